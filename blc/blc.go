@@ -0,0 +1,189 @@
+// Package blc converts between lambda terms and Tromp's binary lambda
+// calculus, BLC.
+//
+// Overview:
+//
+// BLC writes closed terms in de Bruijn notation with two bits per
+// construct, and variables in unary:
+//
+//	\.M  is 00 M
+//	M N  is 01 M N
+//	i    is 1^i 0, the variable bound by the i-th enclosing abstraction
+//
+// so that \x.x is 0010, and \x.\y.x is 0000110. The code is prefix-free: a
+// decoder knows where a term ends without delimiters. The length of the code
+// of a term, its size in bits, measures its complexity, as in Tromp's
+// definition of Kolmogorov complexity.
+//
+// Encode and Decode convert between terms and strings of the characters 0
+// and 1. Pack and Unpack convert these strings to and from bytes, eight bits
+// per byte, most significant bit first, the last byte being padded with
+// zeros; DecodePacked decodes the bytes of such a file.
+//
+// Names are not encoded: decoding names the parameters x, x1, x2 and so on
+// (see term.FromNameless), and only closed terms can be encoded.
+package blc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// ErrFreeVariables is returned, wrapped, when encoding a term that is not
+// closed.
+var ErrFreeVariables = errors.New("free variables cannot be encoded")
+
+// Encode returns the BLC code of the closed term t, as a string of 0 and 1.
+func Encode(t term.Term) monad.Result[string, error] {
+	if free := term.FreeVars(t); len(free) > 0 {
+		names := make([]string, 0, len(free))
+		for name := range free {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return monad.Fail[string, error](fmt.Errorf("%w: %s", ErrFreeVariables, strings.Join(names, ", ")))
+	}
+	var sb strings.Builder
+	encode(&sb, term.ToNameless(t))
+	return monad.Succeed[string, error](sb.String())
+}
+
+func encode(sb *strings.Builder, n term.Nameless) {
+	switch n := n.(type) {
+	case term.Lam:
+		sb.WriteString("00")
+		encode(sb, n.Body())
+	case term.Ap:
+		sb.WriteString("01")
+		encode(sb, n.Fun())
+		encode(sb, n.Arg())
+	case term.Index:
+		sb.WriteString(strings.Repeat("1", n.Index()+1))
+		sb.WriteByte('0')
+	}
+}
+
+// Decode decodes the BLC code of a term, a string of 0 and 1 which may be
+// interspersed with white space. It fails if the code is invalid, or
+// followed by other bits.
+func Decode(bits string) monad.Result[term.Term, error] {
+	d := decoder{bits: strings.Join(strings.Fields(bits), "")}
+	n, err := d.term(0)
+	if err != nil {
+		return monad.Fail[term.Term, error](err)
+	}
+	if d.offset < len(d.bits) {
+		return monad.Fail[term.Term, error](fmt.Errorf("bit %d: unexpected bits after the term", d.offset))
+	}
+	return monad.Succeed[term.Term, error](term.FromNameless(n))
+}
+
+// DecodePacked decodes the BLC code of a term packed in bytes by Pack. The
+// code may only be followed by the zeros padding its last byte.
+func DecodePacked(data []byte) monad.Result[term.Term, error] {
+	d := decoder{bits: Unpack(data)}
+	n, err := d.term(0)
+	if err != nil {
+		return monad.Fail[term.Term, error](err)
+	}
+	if rest := d.bits[d.offset:]; len(rest) >= 8 || strings.Contains(rest, "1") {
+		return monad.Fail[term.Term, error](fmt.Errorf("bit %d: unexpected bits after the term", d.offset))
+	}
+	return monad.Succeed[term.Term, error](term.FromNameless(n))
+}
+
+// Pack packs a string of 0 and 1 into bytes, most significant bit first,
+// padding the last byte with zeros.
+func Pack(bits string) []byte {
+	data := make([]byte, (len(bits)+7)/8)
+	for i := 0; i < len(bits); i++ {
+		if bits[i] == '1' {
+			data[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return data
+}
+
+// Unpack returns the bits of data as a string of 0 and 1, most significant
+// bit first.
+func Unpack(data []byte) string {
+	var sb strings.Builder
+	sb.Grow(8 * len(data))
+	for _, b := range data {
+		fmt.Fprintf(&sb, "%08b", b)
+	}
+	return sb.String()
+}
+
+// decoder reads a term from bits, keeping track of its offset.
+type decoder struct {
+	bits   string
+	offset int
+}
+
+// bit reads the next bit.
+func (d *decoder) bit() (byte, error) {
+	if d.offset >= len(d.bits) {
+		return 0, fmt.Errorf("bit %d: unexpected end of the code", d.offset)
+	}
+	b := d.bits[d.offset]
+	if b != '0' && b != '1' {
+		return 0, fmt.Errorf("bit %d: %q is not a bit", d.offset, b)
+	}
+	d.offset++
+	return b, nil
+}
+
+// term reads a term under the given number of abstractions.
+func (d *decoder) term(depth int) (term.Nameless, error) {
+	start := d.offset
+	first, err := d.bit()
+	if err != nil {
+		return nil, err
+	}
+	if first == '1' {
+		index := 0
+		for {
+			b, err := d.bit()
+			if err != nil {
+				return nil, err
+			}
+			if b == '0' {
+				break
+			}
+			index++
+		}
+		if index >= depth {
+			return nil, fmt.Errorf("bit %d: variable %d is not bound by any of the %d enclosing abstractions",
+				start, index+1, depth)
+		}
+		return term.NewIndex(index), nil
+	}
+
+	second, err := d.bit()
+	if err != nil {
+		return nil, err
+	}
+	if second == '0' {
+		body, err := d.term(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return term.NewLam(body), nil
+	}
+	fun, err := d.term(depth)
+	if err != nil {
+		return nil, err
+	}
+	arg, err := d.term(depth)
+	if err != nil {
+		return nil, err
+	}
+	return term.NewAp(fun, arg), nil
+}
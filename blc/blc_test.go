@@ -0,0 +1,166 @@
+package blc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/term"
+	"github.com/denisdubochevalier/lambdac/term/termtest"
+)
+
+// parse parses a single expression.
+func parse(t *testing.T, source string) term.Term {
+	t.Helper()
+	program := parser.ParseString(source)
+	require.True(t, program.Success(), "%v", program.Error())
+	result := term.FromAST(program.Value().Children()[0])
+	require.True(t, result.Success(), "%v", result.Error())
+	return result.Value()
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		source string
+		bits   string
+	}{
+		{"identity", `\x.x`, "0010"},
+		{"true", `\x.\y.x`, "0000110"},
+		{"false", `\x.\y.y`, "000010"},
+		{"S", `\x.\y.\z.x z (y z)`, "00000001011110100111010"},
+		{"omega", `(\x.x x) (\x.x x)`, "010001101000011010"},
+		{"shadowing", `\x.\x.x`, "000010"},
+		{"two", `\f.\x.f (f x)`, "0000011100111010"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			source := parse(t, testCase.source)
+			encoded := Encode(source)
+			is.True(encoded.Success(), "%v", encoded.Error())
+			is.Equal(testCase.bits, encoded.Value())
+
+			decoded := Decode(testCase.bits)
+			is.True(decoded.Success(), "%v", decoded.Error())
+			is.True(term.AlphaEquivalent(source, decoded.Value()), "%s", decoded.Value())
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	decoded := Decode("00 00 110\n")
+	is.True(decoded.Success(), "%v", decoded.Error())
+	is.Equal(`\x.\x1.x`, decoded.Value().String())
+
+	is.EqualError(Encode(parse(t, `\x.f x y`)).Error(), "free variables cannot be encoded: f, y")
+	is.ErrorIs(Encode(parse(t, `f`)).Error(), ErrFreeVariables)
+
+	is.EqualError(Decode("").Error(), "bit 0: unexpected end of the code")
+	is.EqualError(Decode("0011").Error(), "bit 4: unexpected end of the code")
+	is.EqualError(Decode("00120").Error(), `bit 3: '2' is not a bit`)
+	is.EqualError(Decode("00110").Error(), "bit 2: variable 2 is not bound by any of the 1 enclosing abstractions")
+	is.EqualError(Decode("10").Error(), "bit 0: variable 1 is not bound by any of the 0 enclosing abstractions")
+	is.EqualError(Decode("00100").Error(), "bit 4: unexpected bits after the term")
+}
+
+func TestPack(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	// \x.\y.\z.x z (y z), 23 bits
+	packed := Pack("00000001011110100111010")
+	is.Equal([]byte{0x01, 0x7a, 0x74}, packed)
+	is.Equal("000000010111101001110100", Unpack(packed))
+
+	decoded := DecodePacked(packed)
+	is.True(decoded.Success(), "%v", decoded.Error())
+	is.True(term.AlphaEquivalent(parse(t, `\x.\y.\z.x z (y z)`), decoded.Value()))
+
+	is.EqualError(DecodePacked([]byte{0x20, 0x00}).Error(), "bit 4: unexpected bits after the term")
+	is.EqualError(DecodePacked([]byte{0x21}).Error(), "bit 4: unexpected bits after the term")
+	is.EqualError(DecodePacked(nil).Error(), "bit 0: unexpected end of the code")
+}
+
+func TestRoundTripProperties(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 1000
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("decoding undoes encoding", prop.ForAll(
+		func(t term.Term) bool {
+			bits := Encode(t)
+			if bits.Failure() {
+				return false
+			}
+			decoded := Decode(bits.Value())
+			return decoded.Success() && term.AlphaEquivalent(t, decoded.Value())
+		},
+		termtest.Closed(6),
+	))
+
+	properties.Property("decoding packed codes undoes encoding", prop.ForAll(
+		func(t term.Term) bool {
+			bits := Encode(t)
+			if bits.Failure() {
+				return false
+			}
+			decoded := DecodePacked(Pack(bits.Value()))
+			return decoded.Success() && term.AlphaEquivalent(t, decoded.Value())
+		},
+		termtest.Closed(6),
+	))
+
+	// Codes are prefix-free: any string of bits starting with a code decodes
+	// to the term of that code
+	properties.Property("encoding undoes decoding", prop.ForAll(
+		func(bits []bool) bool {
+			d := decoder{bits: toCode(bits)}
+			n, err := d.term(0)
+			if err != nil {
+				return true
+			}
+			return Encode(term.FromNameless(n)).Value() == d.bits[:d.offset]
+		},
+		gen.SliceOfN(64, gen.Bool()),
+	))
+
+	properties.Property("unpacking undoes packing, up to padding", prop.ForAll(
+		func(bits []bool) bool {
+			code := toCode(bits)
+			unpacked := Unpack(Pack(code))
+			return len(unpacked) == (len(code)+7)/8*8 && unpacked[:len(code)] == code &&
+				!strings.Contains(unpacked[len(code):], "1")
+		},
+		gen.SliceOf(gen.Bool()),
+	))
+
+	properties.TestingRun(t)
+}
+
+// toCode renders bits as a string of 0 and 1.
+func toCode(bits []bool) string {
+	var sb strings.Builder
+	for _, b := range bits {
+		if b {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
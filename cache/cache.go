@@ -0,0 +1,240 @@
+// Package cache stores the artifacts of compilation on disk, so that modules
+// that did not change are not analyzed again.
+//
+// Overview:
+//
+// The cache is content-addressed: an artifact is stored under a Key hashing
+// everything it was computed from, namely the compiler Version and inputs
+// given by the caller, typically the source text of a module and the keys of
+// the modules it imports. Changing any of them changes the key, so that
+// stale artifacts are never read, merely left behind until Clean removes
+// them. There is no other invalidation to get wrong.
+//
+// Entries are written atomically, and callers decode them defensively: a
+// corrupted entry is a cache miss, not an error. Failing to write an entry
+// is not an error either, since the cache is only an optimization.
+//
+// Location:
+//
+// The cache lives in the directory named by the LAMBDAC_CACHE environment
+// variable, by default the lambdac directory of the user cache directory,
+// such as ~/.cache/lambdac on Linux. Setting LAMBDAC_CACHE to "off" disables
+// it.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+
+	"github.com/denisdubochevalier/monad"
+)
+
+// format identifies the layout of the cache and of the artifacts stored in
+// it. Changing either requires changing it.
+const format = "lambdac cache 1"
+
+// Key identifies an artifact.
+type Key [sha256.Size]byte
+
+// NewKey returns the key of the artifact computed from the given inputs by
+// the running compiler. Inputs are hashed along with their length, so that
+// ("ab", "c") and ("a", "bc") do not collide.
+func NewKey(inputs ...string) Key {
+	h := sha256.New()
+	for _, input := range append([]string{format, Version()}, inputs...) {
+		// Writing to a hash never fails
+		_ = binary.Write(h, binary.BigEndian, uint64(len(input)))
+		_, _ = io.WriteString(h, input)
+	}
+	var k Key
+	h.Sum(k[:0])
+	return k
+}
+
+// String returns the hexadecimal form of the key.
+func (k Key) String() string {
+	return hex.EncodeToString(k[:])
+}
+
+// Version returns the version of the running compiler: the version of its
+// module when built from a release, the revision it was built from
+// otherwise, or, for builds of modified sources, a hash of its executable.
+func Version() string {
+	versionOnce.Do(func() { version = readVersion() })
+	return version
+}
+
+var (
+	versionOnce sync.Once
+	version     string
+)
+
+func readVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v := info.Main.Version; v != "" && v != "(devel)" {
+			return v
+		}
+		var revision, modified string
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value
+			}
+		}
+		if revision != "" && modified == "false" {
+			return revision
+		}
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return "unknown"
+	}
+	f, err := os.Open(executable)
+	if err != nil {
+		return "unknown"
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cache is a directory of artifacts. The zero Cache is disabled: it holds
+// nothing and stores nothing.
+type Cache struct {
+	dir string
+}
+
+// Open returns the cache stored in the given directory, creating it if need
+// be.
+func Open(dir string) monad.Result[Cache, error] {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return monad.Fail[Cache, error](err)
+	}
+	return monad.Succeed[Cache, error](Cache{dir: dir})
+}
+
+// Disabled is the value of LAMBDAC_CACHE disabling the cache.
+const Disabled = "off"
+
+// DefaultDir returns the directory of the cache, as configured by the
+// environment, or the empty string if the cache is disabled.
+func DefaultDir() (string, error) {
+	switch dir := os.Getenv("LAMBDAC_CACHE"); dir {
+	case Disabled:
+		return "", nil
+	case "":
+	default:
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lambdac"), nil
+}
+
+// Default returns the cache configured by the environment, disabled if
+// LAMBDAC_CACHE is "off".
+func Default() monad.Result[Cache, error] {
+	dir, err := DefaultDir()
+	if err != nil {
+		return monad.Fail[Cache, error](err)
+	}
+	if dir == "" {
+		return monad.Succeed[Cache, error](Cache{})
+	}
+	return Open(dir)
+}
+
+// Dir returns the directory of the cache, or the empty string if it is
+// disabled.
+func (c Cache) Dir() string {
+	return c.dir
+}
+
+// Enabled tells whether the cache stores artifacts.
+func (c Cache) Enabled() bool {
+	return c.dir != ""
+}
+
+// path returns the name of the file holding the artifact of the given key.
+// Artifacts are spread over 256 subdirectories named after the first byte of
+// their key, to keep directories small.
+func (c Cache) path(k Key) string {
+	name := k.String()
+	return filepath.Join(c.dir, name[:2], name)
+}
+
+// Get returns the artifact stored under the given key, if any.
+func (c Cache) Get(k Key) monad.Maybe[[]byte] {
+	if !c.Enabled() {
+		return monad.None[[]byte]()
+	}
+	data, err := os.ReadFile(c.path(k))
+	if err != nil {
+		return monad.None[[]byte]()
+	}
+	return monad.Some(data)
+}
+
+// Put stores an artifact under the given key. The artifact is written to a
+// temporary file first, then renamed, so that concurrent compilations never
+// read partially written artifacts.
+func (c Cache) Put(k Key, data []byte) error {
+	if !c.Enabled() {
+		return nil
+	}
+	name := c.path(k)
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), "tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
+
+// Clean removes every artifact of the cache. Only the subdirectories holding
+// artifacts are removed, so that pointing LAMBDAC_CACHE at the wrong
+// directory cannot wipe unrelated files.
+func (c Cache) Clean() error {
+	if !c.Enabled() {
+		return errors.New("the cache is disabled")
+	}
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, err := hex.DecodeString(entry.Name()); err != nil || len(entry.Name()) != 2 || !entry.IsDir() {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
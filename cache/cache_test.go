@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKey(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.Equal(NewKey("a", "b"), NewKey("a", "b"))
+	is.NotEqual(NewKey("ab", "c"), NewKey("a", "bc"))
+	is.NotEqual(NewKey("a"), NewKey("a", ""))
+	is.Len(NewKey().String(), 64)
+	is.NotEmpty(Version())
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	dir := filepath.Join(t.TempDir(), "cache")
+	c := Open(dir)
+	is.True(c.Success(), "%v", c.Error())
+	is.True(c.Value().Enabled())
+	is.Equal(dir, c.Value().Dir())
+
+	key := NewKey("source")
+	is.True(c.Value().Get(key).Nothing())
+	is.NoError(c.Value().Put(key, []byte("artifact")))
+	is.Equal([]byte("artifact"), c.Value().Get(key).Value())
+	is.NoError(c.Value().Put(key, []byte("replaced")))
+	is.Equal([]byte("replaced"), c.Value().Get(key).Value())
+
+	// Clean only removes artifacts
+	unrelated := filepath.Join(dir, "notes.txt")
+	is.NoError(os.WriteFile(unrelated, nil, 0o600))
+	is.NoError(c.Value().Clean())
+	is.True(c.Value().Get(key).Nothing())
+	is.FileExists(unrelated)
+}
+
+func TestDisabled(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	var c Cache
+	is.False(c.Enabled())
+	is.NoError(c.Put(NewKey("source"), []byte("artifact")))
+	is.True(c.Get(NewKey("source")).Nothing())
+	is.Error(c.Clean())
+}
+
+func TestDefaultDir(t *testing.T) {
+	is := require.New(t)
+
+	t.Setenv("LAMBDAC_CACHE", "elsewhere")
+	dir, err := DefaultDir()
+	is.NoError(err)
+	is.Equal("elsewhere", dir)
+
+	t.Setenv("LAMBDAC_CACHE", Disabled)
+	c := Default()
+	is.True(c.Success(), "%v", c.Error())
+	is.False(c.Value().Enabled())
+}
@@ -0,0 +1,13 @@
+// Command lambdac is the λ.c compiler. See package driver for its usage.
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/denisdubochevalier/lambdac/driver"
+)
+
+func main() {
+	os.Exit(driver.Main(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
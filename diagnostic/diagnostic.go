@@ -0,0 +1,132 @@
+// Package diagnostic defines the messages that the analysis stages of λ.c
+// report about a program: errors that prevent compilation and warnings that
+// merely point at suspicious code.
+//
+// A Diagnostic is an immutable value carrying a Severity, the lexer.Position
+// it refers to, a message and an optional hint. Diagnostics implement the error
+// interface so that they can travel through monad.Result values like any other
+// error, while remaining inspectable with errors.As by the tools that render
+// them (command-line driver, language server, REPL). A Renderer renders them
+// along with an excerpt of the source text.
+package diagnostic
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+// Severity ranks a Diagnostic by how much it should worry the user.
+type Severity int
+
+const (
+	Error   Severity = iota // Error marks a problem that prevents the program from being compiled.
+	Warning                 // Warning marks suspicious but valid code.
+)
+
+var severities = []string{
+	Error:   "error",
+	Warning: "warning",
+}
+
+// String returns the lower case name of the Severity, as printed in front of
+// rendered diagnostics.
+func (s Severity) String() string {
+	if int(s) < 0 || int(s) >= len(severities) {
+		return "unknown"
+	}
+	return severities[s]
+}
+
+// Diagnostic describes a single finding of the compiler about the source text.
+// It is built with New and refined with the With* methods, each of which
+// returns an updated copy.
+type Diagnostic struct {
+	severity Severity
+	position lexer.Position
+	message  string
+	hint     string
+}
+
+// New creates a Diagnostic of the given severity at the given position. The
+// message is formatted with fmt.Sprintf semantics.
+func New(severity Severity, position lexer.Position, format string, args ...any) Diagnostic {
+	return Diagnostic{
+		severity: severity,
+		position: position,
+		message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// Errorf is a shorthand for New(Error, ...).
+func Errorf(position lexer.Position, format string, args ...any) Diagnostic {
+	return New(Error, position, format, args...)
+}
+
+// Warningf is a shorthand for New(Warning, ...).
+func Warningf(position lexer.Position, format string, args ...any) Diagnostic {
+	return New(Warning, position, format, args...)
+}
+
+// WithHint attaches a short suggestion to the Diagnostic, such as a
+// "did you mean" proposal.
+func (d Diagnostic) WithHint(format string, args ...any) Diagnostic {
+	d.hint = fmt.Sprintf(format, args...)
+	return d
+}
+
+// Severity returns the Severity of the Diagnostic.
+func (d Diagnostic) Severity() Severity {
+	return d.severity
+}
+
+// Position returns the location in the source text the Diagnostic refers to.
+func (d Diagnostic) Position() lexer.Position {
+	return d.position
+}
+
+// Message returns the main message of the Diagnostic, without position nor
+// hint.
+func (d Diagnostic) Message() string {
+	return d.message
+}
+
+// Hint returns the optional suggestion attached to the Diagnostic, or an empty
+// string.
+func (d Diagnostic) Hint() string {
+	return d.hint
+}
+
+// Error renders the Diagnostic as "row:col: severity: message (hint)". It
+// makes Diagnostic usable wherever an error is expected.
+func (d Diagnostic) Error() string {
+	s := fmt.Sprintf("%d:%d: %s: %s", d.position.Row(), d.position.Col(), d.severity, d.message)
+	if d.hint != "" {
+		s += " (" + d.hint + ")"
+	}
+	return s
+}
+
+// HasErrors reports whether at least one of the diagnostics has the Error
+// severity.
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort sorts diagnostics by position, keeping the relative order of the
+// diagnostics at the same position.
+func Sort(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].position, diagnostics[j].position
+		if a.Row() != b.Row() {
+			return a.Row() < b.Row()
+		}
+		return a.Col() < b.Col()
+	})
+}
@@ -0,0 +1,85 @@
+package diagnostic
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+func TestDiagnostic(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+		is := require.New(t)
+
+		d := Errorf(lexer.NewPosition(3, 4), "unbound identifier %q", "fo")
+		is.Equal(Error, d.Severity())
+		is.Equal(lexer.NewPosition(3, 4), d.Position())
+		is.Equal(`3:4: error: unbound identifier "fo"`, d.Error())
+	})
+
+	t.Run("WithHint", func(t *testing.T) {
+		t.Parallel()
+		is := require.New(t)
+
+		d := Warningf(lexer.StartPosition(), "unused").WithHint("did you mean %q?", "foo")
+		is.Equal(`did you mean "foo"?`, d.Hint())
+		is.Equal(`1:0: warning: unused (did you mean "foo"?)`, d.Error())
+	})
+
+	t.Run("errors.As", func(t *testing.T) {
+		t.Parallel()
+		is := require.New(t)
+
+		var d Diagnostic
+		err := fmt.Errorf("wrapped: %w", Errorf(lexer.StartPosition(), "boom"))
+		is.True(errors.As(err, &d))
+		is.Equal("boom", d.Message())
+	})
+
+	t.Run("HasErrors", func(t *testing.T) {
+		t.Parallel()
+		is := require.New(t)
+
+		is.False(HasErrors([]Diagnostic{Warningf(lexer.StartPosition(), "w")}))
+		is.True(HasErrors([]Diagnostic{
+			Warningf(lexer.StartPosition(), "w"),
+			Errorf(lexer.StartPosition(), "e"),
+		}))
+	})
+
+	t.Run("Severity.String", func(t *testing.T) {
+		t.Parallel()
+		is := require.New(t)
+
+		is.Equal("warning", Warning.String())
+		is.Equal("unknown", Severity(42).String())
+	})
+}
+
+func TestRenderer(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	r := NewRenderer("main.lc", "foo := \\x.x\nmain :=\tfooo x\n")
+
+	d := Errorf(lexer.NewPosition(2, 8), "unbound identifier %q", "fooo").WithHint("did you mean %q?", "foo")
+	is.Equal("main.lc:2:9: error: unbound identifier \"fooo\"\n"+
+		"  2 | main :=\tfooo x\n"+
+		"    |        \t^\n"+
+		"    = did you mean \"foo\"?\n", r.Render(d))
+
+	w := Warningf(lexer.NewPosition(1, 0), "definition %q is never used", "foo")
+	is.Equal("main.lc:1:1: warning: definition \"foo\" is never used\n"+
+		"  1 | foo := \\x.x\n"+
+		"    | ^\n", r.RenderError(w))
+
+	is.Equal("main.lc:9:1: error: out of range\n  = hint\n",
+		r.Render(Errorf(lexer.NewPosition(9, 0), "out of range").WithHint("hint")))
+	is.Equal("main.lc: boom\n", r.RenderError(errors.New("boom")))
+}
@@ -0,0 +1,78 @@
+package diagnostic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Renderer renders the diagnostics of a source file the way compilers
+// customarily do: the location, severity and message on a first line, then
+// the offending line of source with a caret under the column, and the hint if
+// any:
+//
+//	main.lc:2:9: error: unbound identifier "fooo"
+//	  2 | main := fooo foo
+//	    |         ^
+//	    = did you mean "foo"?
+//
+// Rows and columns both start at 1 in the location, as compilers and the
+// editors parsing their output expect, although columns start at 0 in
+// lexer.Position and everywhere else in the diagnostics.
+type Renderer struct {
+	filename string
+	lines    []string
+}
+
+// NewRenderer returns a Renderer for the diagnostics of source, read from
+// filename.
+func NewRenderer(filename, source string) Renderer {
+	return Renderer{filename: filename, lines: strings.Split(source, "\n")}
+}
+
+// Render renders a single Diagnostic, with a trailing line break.
+func (r Renderer) Render(d Diagnostic) string {
+	row, col := d.position.Row(), d.position.Col()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:%d:%d: %s: %s\n", r.filename, row, col+1, d.severity, d.message)
+	if row >= 1 && row <= len(r.lines) {
+		line := strings.TrimRight(r.lines[row-1], "\r")
+		gutter := fmt.Sprint(row)
+		fmt.Fprintf(&b, "  %s | %s\n", gutter, line)
+		fmt.Fprintf(&b, "  %s | %s^\n", strings.Repeat(" ", len(gutter)), padding(line, col))
+		if d.hint != "" {
+			fmt.Fprintf(&b, "  %s = %s\n", strings.Repeat(" ", len(gutter)), d.hint)
+		}
+	} else if d.hint != "" {
+		fmt.Fprintf(&b, "  = %s\n", d.hint)
+	}
+	return b.String()
+}
+
+// RenderError renders any error: diagnostics as Render does, other errors
+// prefixed with the file name only.
+func (r Renderer) RenderError(err error) string {
+	var d Diagnostic
+	if errors.As(err, &d) {
+		return r.Render(d)
+	}
+	return fmt.Sprintf("%s: %v\n", r.filename, err)
+}
+
+// padding returns the blanks that align a caret under the col-th rune of line,
+// keeping tabulations so that the alignment survives their expansion.
+func padding(line string, col int) string {
+	var b strings.Builder
+	for i, c := range []rune(line) {
+		if i >= col {
+			break
+		}
+		if c == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
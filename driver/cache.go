@@ -0,0 +1,83 @@
+package driver
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/cache"
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+)
+
+// openCache returns the build cache configured by the environment. A cache
+// that cannot be opened is disabled rather than reported: compiling without
+// it is merely slower.
+func openCache() cache.Cache {
+	c := cache.Default()
+	if c.Failure() {
+		return cache.Cache{}
+	}
+	return c.Value()
+}
+
+// analysis is the outcome of the front end for one module: its diagnostics,
+// and its syntax tree, rewritten if recursive definitions were.
+type analysis struct {
+	diagnostics []diagnostic.Diagnostic
+	program     parser.ASTNode
+}
+
+// wireAnalysis and wireDiagnostic are the serialized forms of an analysis
+// and of its diagnostics.
+type wireAnalysis struct {
+	Diagnostics []wireDiagnostic
+	Program     []byte
+}
+
+type wireDiagnostic struct {
+	Severity      diagnostic.Severity
+	Row, Col      int
+	Message, Hint string
+}
+
+// encode serializes the analysis, to store it in the build cache.
+func (a analysis) encode() []byte {
+	w := wireAnalysis{Program: parser.Encode(a.program)}
+	for _, d := range a.diagnostics {
+		w.Diagnostics = append(w.Diagnostics, wireDiagnostic{
+			Severity: d.Severity(),
+			Row:      d.Position().Row(),
+			Col:      d.Position().Col(),
+			Message:  d.Message(),
+			Hint:     d.Hint(),
+		})
+	}
+	var b bytes.Buffer
+	// Encoding into memory a type known to be encodable cannot fail
+	_ = gob.NewEncoder(&b).Encode(w)
+	return b.Bytes()
+}
+
+// decodeAnalysis restores an analysis serialized by encode.
+func decodeAnalysis(data []byte) monad.Result[analysis, error] {
+	var w wireAnalysis
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&w); err != nil {
+		return monad.Fail[analysis, error](err)
+	}
+	program := parser.Decode(w.Program)
+	if program.Failure() {
+		return monad.Fail[analysis, error](program.Error())
+	}
+	a := analysis{program: program.Value()}
+	for _, d := range w.Diagnostics {
+		decoded := diagnostic.New(d.Severity, lexer.NewPosition(d.Row, d.Col), "%s", d.Message)
+		if d.Hint != "" {
+			decoded = decoded.WithHint("%s", d.Hint)
+		}
+		a.diagnostics = append(a.diagnostics, decoded)
+	}
+	return monad.Succeed[analysis, error](a)
+}
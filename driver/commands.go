@@ -0,0 +1,652 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/blc"
+	"github.com/denisdubochevalier/lambdac/cache"
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/effect"
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/fixpoint"
+	"github.com/denisdubochevalier/lambdac/format"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/lint"
+	"github.com/denisdubochevalier/lambdac/lsp"
+	"github.com/denisdubochevalier/lambdac/module"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/repl"
+	"github.com/denisdubochevalier/lambdac/ski"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+func lexCmd(d driver, c command, args []string) int {
+	fs := d.flagSet(c)
+	files, code, ok := d.parseFlags(fs, args, 1, 1)
+	if !ok {
+		return code
+	}
+	src, err := d.read(files[0])
+	if err != nil {
+		return d.readFailed(err)
+	}
+
+	var b strings.Builder
+	for _, token := range lexer.New().WithContent(src.text).Tokens() {
+		if token.Type() == lexer.ILLEGAL {
+			d.output(b.String())
+			return d.fail(src, diagnostic.Errorf(token.Position(), "illegal token"))
+		}
+		fmt.Fprintf(&b, "%d:%d\t%s\t%q\n", token.Position().Row(), token.Position().Col(), token.Type(), token.Literal())
+	}
+	return d.output(b.String())
+}
+
+func parseCmd(d driver, c command, args []string) int {
+	fs := d.flagSet(c)
+	files, code, ok := d.parseFlags(fs, args, 1, 1)
+	if !ok {
+		return code
+	}
+	src, err := d.read(files[0])
+	if err != nil {
+		return d.readFailed(err)
+	}
+
+	program := parser.ParseString(src.text)
+	if program.Failure() {
+		return d.fail(src, program.Error())
+	}
+	return d.output(program.Value().String() + "\n")
+}
+
+// frontEnd holds the flags of the commands analyzing whole programs, and the
+// build cache they share.
+type frontEnd struct {
+	entry    string
+	fixpoint string
+	cache    cache.Cache
+}
+
+func (f *frontEnd) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.entry, "entry", "",
+		"name of the definition the program starts from (default: the entry of "+module.ManifestName+", or main)")
+	fs.StringVar(&f.fixpoint, "fixpoint", "none",
+		"rewrite recursive definitions with the y or z combinator, or report them with none")
+}
+
+// validate checks the flag values, reporting invalid ones as usage errors.
+func (f *frontEnd) validate(d driver, fs *flag.FlagSet) bool {
+	switch f.fixpoint {
+	case "none", "y", "z":
+		return true
+	}
+	fmt.Fprintf(d.stderr, "lambdac %s: -fixpoint must be none, y or z, not %q\n", fs.Name(), f.fixpoint)
+	return false
+}
+
+// target returns the file or directory a command applies to, the current
+// directory by default.
+func target(args []string) string {
+	if len(args) == 0 {
+		return "."
+	}
+	return args[0]
+}
+
+// load reads the program designated by name: a source file, "-" for the
+// standard input, or a directory, all the source files of which are loaded
+// as one program. Imports are searched along the search path of the project
+// the program belongs to, whose manifest also provides the entry point
+// unless the -entry flag is set.
+func (f *frontEnd) load(d driver, name string) (module.Graph, bool) {
+	var info os.FileInfo
+	dir := "."
+	if name != "-" {
+		var err error
+		if info, err = os.Stat(name); err != nil {
+			d.readFailed(err)
+			return module.Graph{}, false
+		}
+		dir = name
+		if !info.IsDir() {
+			dir = filepath.Dir(name)
+		}
+	}
+	manifest := module.FindManifest(dir)
+	if manifest.Failure() {
+		d.fail(newSource(name, ""), manifest.Error())
+		return module.Graph{}, false
+	}
+	if f.entry == "" {
+		f.entry = manifest.Value().Entry()
+	}
+	f.cache = openCache()
+	loader := module.NewLoader(manifest.Value().SearchPath()...).WithCache(f.cache)
+
+	if info != nil && info.IsDir() {
+		root := manifest.Value().Root()
+		if filepath.Clean(name) != filepath.Clean(manifest.Value().Dir()) {
+			root = module.Dir(name)
+		}
+		g := loader.LoadTree(root)
+		if g.Failure() {
+			d.fail(newSource(name, ""), g.Error())
+			return module.Graph{}, false
+		}
+		return g.Value(), true
+	}
+
+	src, err := d.read(name)
+	if err != nil {
+		d.readFailed(err)
+		return module.Graph{}, false
+	}
+	g := loader.Load(src.name, src.text)
+	if g.Failure() {
+		d.fail(src, g.Error())
+		return module.Graph{}, false
+	}
+	return g.Value(), true
+}
+
+// analyze loads the program designated by name and runs the front end over
+// its modules: scope resolution, linting and recursion detection or
+// rewriting. It writes the diagnostics, and returns the module graph if it
+// has no errors. The analysis of modules that did not change since the
+// previous compilation is read from the build cache.
+func (f *frontEnd) analyze(d driver, name string) (module.Graph, bool) {
+	g, ok := f.load(d, name)
+	if !ok {
+		return module.Graph{}, false
+	}
+
+	failed := false
+	graph := g.Map(func(m module.Module) module.Module {
+		key := cache.NewKey("analysis", m.Key().String(), f.entry, f.fixpoint)
+		a, ok := f.cached(key)
+		if !ok {
+			a = f.analyzeModule(m)
+			// The cache is an optimization: failing to fill it is not an error
+			_ = f.cache.Put(key, a.encode())
+		}
+		if d.report(newSource(m.File(), m.Source()), a.diagnostics) {
+			failed = true
+		}
+		return m.WithProgram(a.program)
+	})
+	return graph, !failed
+}
+
+// cached returns the analysis stored in the build cache under the given key,
+// if any and intact.
+func (f *frontEnd) cached(key cache.Key) (analysis, bool) {
+	data := f.cache.Get(key)
+	if data.Nothing() {
+		return analysis{}, false
+	}
+	a := decodeAnalysis(data.Value())
+	return a.Value(), a.Success()
+}
+
+// analyzeModule runs the front end over a module.
+func (f *frontEnd) analyzeModule(m module.Module) analysis {
+	program, resolution := m.Program(), m.Resolution()
+	diagnostics := resolution.Diagnostics()
+	diagnostics = append(diagnostics, lint.New().WithEntryPoints(f.entry).Run(program, resolution)...)
+	switch f.fixpoint {
+	case "y":
+		program = fixpoint.Rewrite(program, resolution, fixpoint.Y)
+	case "z":
+		program = fixpoint.Rewrite(program, resolution, fixpoint.Z)
+	default:
+		diagnostics = append(diagnostics, fixpoint.Diagnostics(fixpoint.Detect(program, resolution))...)
+	}
+	diagnostic.Sort(diagnostics)
+	return analysis{diagnostics: diagnostics, program: program}
+}
+
+// link analyzes the program designated by name and returns the closed term of
+// its entry point, along with the source of the module defining it. The
+// entry point of a directory is looked for in all its modules.
+func (f *frontEnd) link(d driver, name string) (term.Term, source, bool) {
+	graph, ok := f.analyze(d, name)
+	if !ok {
+		return nil, source{}, false
+	}
+	if graph.Root().Path() != "" {
+		defining := graph.Defining(f.entry)
+		switch len(defining) {
+		case 0:
+			fmt.Fprintf(d.stderr, "lambdac: no module of %s defines the entry point %q\n", name, f.entry)
+			return nil, source{}, false
+		case 1:
+			graph = graph.WithRoot(defining[0].Path()).Value()
+		default:
+			files := make([]string, 0, len(defining))
+			for _, m := range defining {
+				files = append(files, m.File())
+			}
+			fmt.Fprintf(d.stderr, "lambdac: several modules of %s define the entry point %q: %s\n",
+				name, f.entry, strings.Join(files, ", "))
+			return nil, source{}, false
+		}
+	}
+
+	src := newSource(graph.Root().File(), graph.Root().Source())
+	linked := graph.Link()
+	if linked.Failure() {
+		d.fail(src, linked.Error())
+		return nil, source{}, false
+	}
+	entry := linked.Value().Entry(f.entry)
+	if entry.Failure() {
+		d.fail(src, entry.Error())
+		return nil, source{}, false
+	}
+	return entry.Value(), src, true
+}
+
+func checkCmd(d driver, c command, args []string) int {
+	var f frontEnd
+	fs := d.flagSet(c)
+	f.register(fs)
+	args, code, ok := d.parseFlags(fs, args, 0, 1)
+	if !ok {
+		return code
+	}
+	if !f.validate(d, fs) {
+		return ExitUsage
+	}
+
+	if _, ok := f.analyze(d, target(args)); !ok {
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+func runCmd(d driver, c command, args []string) int {
+	var f frontEnd
+	fs := d.flagSet(c)
+	f.register(fs)
+	strategy := fs.String("strategy", "normal", "evaluation strategy: "+strings.Join(eval.Strategies(), ", "))
+	steps := fs.Int("steps", eval.DefaultMaxSteps, "maximum number of reduction steps, 0 for no limit")
+	timeout := fs.Duration("timeout", 0, "maximum duration of the evaluation, 0 for no limit")
+	trace := fs.String("trace", "", "write every reduction step to the standard error, as text or json")
+	printed := fs.String("print", "term", "print the result as a term, a numeral or a string")
+	performs := fs.Bool("io", false, "perform the effects of the std/io action the program evaluates to")
+	args, code, ok := d.parseFlags(fs, args, 0, -1)
+	if !ok {
+		return code
+	}
+	if len(args) > 1 && !*performs {
+		fmt.Fprintln(d.stderr, "lambdac run: only -io programs take arguments")
+		return ExitUsage
+	}
+	if !f.validate(d, fs) {
+		return ExitUsage
+	}
+
+	var tracer eval.Tracer
+	switch *trace {
+	case "":
+	case "text":
+		tracer = eval.NewTextTracer(d.stderr)
+	case "json":
+		tracer = eval.NewJSONTracer(d.stderr)
+	default:
+		fmt.Fprintf(d.stderr, "lambdac run: -trace must be text or json, not %q\n", *trace)
+		return ExitUsage
+	}
+	if *performs {
+		return f.perform(d, fs, args, *steps, *timeout, tracer)
+	}
+	read, ok := readers[*printed]
+	if !ok {
+		fmt.Fprintf(d.stderr, "lambdac run: -print must be term, numeral or string, not %q\n", *printed)
+		return ExitUsage
+	}
+	evaluator := eval.NewEvaluator(*strategy, *steps, tracer)
+	if evaluator.Failure() {
+		fmt.Fprintf(d.stderr, "lambdac run: %v\n", evaluator.Error())
+		return ExitUsage
+	}
+
+	entry, src, ok := f.link(d, target(args))
+	if !ok {
+		return ExitFailure
+	}
+
+	ctx := d.ctx
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	result := evaluator.Value().Evaluate(ctx, entry)
+	if result.Failure() {
+		return d.fail(src, result.Error())
+	}
+	output := read(result.Value())
+	if output.Nothing() {
+		fmt.Fprintf(d.stderr, "lambdac run: the result is not a %s: %s\n", *printed, result.Value())
+		return ExitFailure
+	}
+	return d.output(output.Value() + "\n")
+}
+
+// perform runs the std/io action of an -io program, giving it the arguments
+// following its name, and returns its exit code.
+func (f *frontEnd) perform(
+	d driver, fs *flag.FlagSet, args []string, steps int, timeout time.Duration, tracer eval.Tracer,
+) int {
+	var exclusive []string
+	fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == "strategy" || fl.Name == "print" {
+			exclusive = append(exclusive, "-"+fl.Name)
+		}
+	})
+	if len(exclusive) > 0 {
+		fmt.Fprintf(d.stderr, "lambdac run: %s cannot be used with -io\n", strings.Join(exclusive, " and "))
+		return ExitUsage
+	}
+
+	entry, src, ok := f.link(d, target(args))
+	if !ok {
+		return ExitFailure
+	}
+	var programArgs []string
+	if len(args) > 1 {
+		programArgs = args[1:]
+	}
+
+	ctx := d.ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	interpreter := effect.NewInterpreter(d.stdin, d.stdout, d.stderr).
+		WithArgs(programArgs...).WithMaxSteps(steps).WithTracer(tracer)
+	code := interpreter.Run(ctx, entry)
+	if code.Failure() {
+		return d.fail(src, code.Error())
+	}
+	return code.Value()
+}
+
+// readers decode the results of run for its -print flag.
+var readers = map[string]func(term.Term) monad.Maybe[string]{
+	"term": func(t term.Term) monad.Maybe[string] {
+		return monad.Some(t.String())
+	},
+	"numeral": func(t term.Term) monad.Maybe[string] {
+		n := eval.ReadNumeral(t)
+		if n.Nothing() {
+			return monad.None[string]()
+		}
+		return monad.Some(strconv.Itoa(n.Value()))
+	},
+	"string": eval.ReadString,
+}
+
+func buildCmd(d driver, c command, args []string) int {
+	var f frontEnd
+	fs := d.flagSet(c)
+	f.register(fs)
+	out := fs.String("o", "", "write the linked term to this file instead of the standard output")
+	combinators := fs.String("ski", "", "compile the linked term to the combinators of the basis ski or turner")
+	args, code, ok := d.parseFlags(fs, args, 0, 1)
+	if !ok {
+		return code
+	}
+	if !f.validate(d, fs) {
+		return ExitUsage
+	}
+	bases := map[string]ski.Basis{"ski": ski.SKI, "turner": ski.Turner}
+	basis, compiles := bases[*combinators]
+	if *combinators != "" && !compiles {
+		fmt.Fprintf(d.stderr, "lambdac build: -ski must be ski or turner, not %q\n", *combinators)
+		return ExitUsage
+	}
+
+	entry, _, ok := f.link(d, target(args))
+	if !ok {
+		return ExitFailure
+	}
+	built := entry.String()
+	if compiles {
+		built = ski.Compile(entry, basis).String()
+	}
+	if *out == "" {
+		return d.output(built + "\n")
+	}
+	return d.write(*out, built+"\n")
+}
+
+// blcCommands are the subcommands of blc.
+var blcCommands = []command{
+	{"blc encode", "blc encode [flags] [file|dir]", "encode a program in binary lambda calculus", blcEncodeCmd},
+	{"blc decode", "blc decode [flags] file", "decode a term from binary lambda calculus", blcDecodeCmd},
+}
+
+func blcCmd(d driver, c command, args []string) int {
+	if len(args) > 0 {
+		for _, sub := range blcCommands {
+			if sub.name == "blc "+args[0] {
+				return sub.run(d, sub, args[1:])
+			}
+		}
+	}
+	fs := d.flagSet(c)
+	usage := fs.Usage
+	fs.Usage = func() {
+		usage()
+		fmt.Fprintln(fs.Output(), "\nSubcommands:")
+		for _, sub := range blcCommands {
+			fmt.Fprintf(fs.Output(), "  %-6s %s\n", strings.TrimPrefix(sub.name, "blc "), sub.summary)
+		}
+		fmt.Fprintln(fs.Output(), "\nRun \"lambdac blc <subcommand> -h\" for the flags of a subcommand.")
+	}
+	args, code, ok := d.parseFlags(fs, args, 0, -1)
+	if !ok {
+		return code
+	}
+	if len(args) > 0 {
+		fmt.Fprintf(d.stderr, "lambdac blc: unknown subcommand %q\n", args[0])
+	} else {
+		fmt.Fprintln(d.stderr, "lambdac blc: expected encode or decode")
+	}
+	fs.Usage()
+	return ExitUsage
+}
+
+func blcEncodeCmd(d driver, c command, args []string) int {
+	var f frontEnd
+	fs := d.flagSet(c)
+	f.register(fs)
+	out := fs.String("o", "", "write the code to this file instead of the standard output")
+	packed := fs.Bool("packed", false, "pack the code in bytes, eight bits each, instead of writing 0 and 1")
+	size := fs.Bool("size", false, "print the size of the code in bits instead of the code")
+	args, code, ok := d.parseFlags(fs, args, 0, 1)
+	if !ok {
+		return code
+	}
+	if !f.validate(d, fs) {
+		return ExitUsage
+	}
+
+	entry, src, ok := f.link(d, target(args))
+	if !ok {
+		return ExitFailure
+	}
+	bits := blc.Encode(entry)
+	if bits.Failure() {
+		return d.fail(src, bits.Error())
+	}
+	encoded := bits.Value() + "\n"
+	switch {
+	case *size:
+		encoded = fmt.Sprintf("%d\n", len(bits.Value()))
+	case *packed:
+		encoded = string(blc.Pack(bits.Value()))
+	}
+	if *out == "" {
+		return d.output(encoded)
+	}
+	return d.write(*out, encoded)
+}
+
+func blcDecodeCmd(d driver, c command, args []string) int {
+	fs := d.flagSet(c)
+	packed := fs.Bool("packed", false, "read a code packed in bytes, as written by blc encode -packed")
+	files, code, ok := d.parseFlags(fs, args, 1, 1)
+	if !ok {
+		return code
+	}
+	src, err := d.read(files[0])
+	if err != nil {
+		return d.readFailed(err)
+	}
+
+	var decoded monad.Result[term.Term, error]
+	if *packed {
+		decoded = blc.DecodePacked([]byte(src.text))
+	} else {
+		decoded = blc.Decode(src.text)
+	}
+	if decoded.Failure() {
+		return d.fail(src, decoded.Error())
+	}
+	return d.output(decoded.Value().String() + "\n")
+}
+
+func fmtCmd(d driver, c command, args []string) int {
+	fs := d.flagSet(c)
+	list := fs.Bool("l", false, "list the files whose formatting differs from the canonical form")
+	write := fs.Bool("w", false, "write the canonical form back to the files")
+	diff := fs.Bool("d", false, "print the differences with the canonical form")
+	files, code, ok := d.parseFlags(fs, args, 1, -1)
+	if !ok {
+		return code
+	}
+	if *write && slices.Contains(files, "-") {
+		fmt.Fprintln(d.stderr, "lambdac fmt: -w cannot write back to the standard input")
+		return ExitUsage
+	}
+
+	code = ExitOK
+	for _, file := range files {
+		src, err := d.read(file)
+		if err != nil {
+			code = d.readFailed(err)
+			continue
+		}
+		result := format.Source(src.text)
+		if result.Failure() {
+			code = d.fail(src, result.Error())
+			continue
+		}
+		formatted := result.Value()
+
+		if !*list && !*write && !*diff {
+			if c := d.output(formatted); c != ExitOK {
+				return c
+			}
+			continue
+		}
+		if formatted == src.text {
+			continue
+		}
+		if *list {
+			if c := d.output(src.name + "\n"); c != ExitOK {
+				return c
+			}
+		}
+		if *diff {
+			if c := d.output(unifiedDiff(src.name, src.text, formatted)); c != ExitOK {
+				return c
+			}
+		}
+		if *write {
+			if c := d.write(file, formatted); c != ExitOK {
+				code = c
+			}
+		}
+	}
+	return code
+}
+
+func replCmd(d driver, c command, args []string) int {
+	fs := d.flagSet(c)
+	files, code, ok := d.parseFlags(fs, args, 0, -1)
+	if !ok {
+		return code
+	}
+
+	session := repl.New(d.stdout)
+	for _, file := range files {
+		session.Execute(d.ctx, ":load "+file)
+	}
+	if err := session.Run(d.ctx, d.stdin); err != nil {
+		fmt.Fprintf(d.stderr, "lambdac: %v\n", err)
+		return ExitInternal
+	}
+	return ExitOK
+}
+
+func lspCmd(d driver, c command, args []string) int {
+	fs := d.flagSet(c)
+	if _, code, ok := d.parseFlags(fs, args, 0, 0); !ok {
+		return code
+	}
+
+	err := lsp.New(d.stdin, d.stdout).Run(d.ctx)
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return ExitOK
+	case errors.Is(err, lsp.ErrExitWithoutShutdown):
+		return ExitFailure
+	default:
+		fmt.Fprintf(d.stderr, "lambdac: %v\n", err)
+		return ExitInternal
+	}
+}
+
+func cleanCmd(d driver, c command, args []string) int {
+	fs := d.flagSet(c)
+	cached := fs.Bool("cache", false, "remove the build cache (LAMBDAC_CACHE)")
+	if _, code, ok := d.parseFlags(fs, args, 0, 0); !ok {
+		return code
+	}
+	if !*cached {
+		fmt.Fprintln(d.stderr, "lambdac clean: nothing to clean, use -cache to remove the build cache")
+		return ExitUsage
+	}
+
+	store := cache.Default()
+	if store.Failure() {
+		fmt.Fprintf(d.stderr, "lambdac: %v\n", store.Error())
+		return ExitFailure
+	}
+	if !store.Value().Enabled() {
+		fmt.Fprintf(d.stderr, "lambdac clean: the cache is disabled (LAMBDAC_CACHE=%s)\n", cache.Disabled)
+		return ExitFailure
+	}
+	if err := store.Value().Clean(); err != nil {
+		fmt.Fprintf(d.stderr, "lambdac: %v\n", err)
+		return ExitFailure
+	}
+	return ExitOK
+}
@@ -0,0 +1,123 @@
+package driver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// edit is a line of a diff: kept (' '), removed ('-') or added ('+').
+type edit struct {
+	op   byte
+	line string
+}
+
+// unifiedDiff renders the differences between before and after as a unified
+// diff of the file name. Source files are small, which makes the quadratic
+// longest common subsequence affordable.
+func unifiedDiff(name, before, after string) string {
+	edits := diffLines(splitLines(before), splitLines(after))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s (formatted)\n", name, name)
+	for start := 0; start < len(edits); {
+		if edits[start].op == ' ' {
+			start++
+			continue
+		}
+		// Extend the hunk while changes are close enough to share context.
+		end := start
+		for i := start; i < len(edits) && i <= end+2*diffContext; i++ {
+			if edits[i].op != ' ' {
+				end = i
+			}
+		}
+		from := max(start-diffContext, 0)
+		to := min(end+diffContext+1, len(edits))
+		writeHunk(&b, edits, from, to)
+		start = to
+	}
+	return b.String()
+}
+
+// writeHunk writes edits[from:to] along with its header.
+func writeHunk(b *strings.Builder, edits []edit, from, to int) {
+	oldStart, newStart := 1, 1
+	for _, e := range edits[:from] {
+		if e.op != '+' {
+			oldStart++
+		}
+		if e.op != '-' {
+			newStart++
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, e := range edits[from:to] {
+		if e.op != '+' {
+			oldCount++
+		}
+		if e.op != '-' {
+			newCount++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, e := range edits[from:to] {
+		b.WriteByte(e.op)
+		b.WriteString(e.line)
+		b.WriteByte('\n')
+	}
+}
+
+// diffLines computes a shortest edit turning a into b from their longest
+// common subsequence.
+func diffLines(a, b []string) []edit {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		edits = append(edits, edit{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, edit{'+', b[j]})
+	}
+	return edits
+}
+
+// splitLines splits s into lines, without their terminating line feeds.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,274 @@
+// Package driver implements the lambdac command line.
+//
+// Overview:
+//
+// The command line is a set of subcommands, each chaining the stages of the
+// compiler up to the one it is interested in:
+//
+//	lambdac lex file.lc      print the tokens of a file
+//	lambdac parse file.lc    print the syntax tree of a file
+//	lambdac check file.lc    report the diagnostics of a file
+//	lambdac run file.lc      evaluate a program
+//	lambdac build file.lc    link a program into a single closed term (-ski)
+//	lambdac blc encode file  encode a program in binary lambda calculus
+//	lambdac blc decode file  decode a term from binary lambda calculus
+//	lambdac fmt file.lc      print a file in canonical form (-l, -w, -d)
+//	lambdac repl [file.lc]   start an interactive session
+//	lambdac lsp              start a language server for editors
+//	lambdac clean -cache     remove the build cache
+//
+// Every subcommand accepts its own flags, parsed the same way, and "-" as a
+// file name to read standard input. check, run and build also accept a
+// directory, by default the current one, to compile every .lc file below it
+// as one program, configured by the lambdac.mod manifest of the project (see
+// module.Manifest). The syntax trees, resolutions and analyses of modules
+// that did not change are read back from a build cache (see package cache).
+// Diagnostics are written to the standard error, along with an excerpt of the
+// source text (see diagnostic.Renderer).
+//
+// Exit codes:
+//
+// The exit code tells problems with the program being compiled apart from
+// problems with the compiler itself:
+//
+//   - ExitOK: the command succeeded.
+//   - ExitFailure: the program has errors, or its evaluation failed.
+//   - ExitUsage: the command line is invalid.
+//   - ExitInternal: the compiler failed, which is a bug worth reporting.
+package driver
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"strings"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/module"
+)
+
+// Exit codes returned by Main.
+const (
+	ExitOK       = 0
+	ExitFailure  = 1
+	ExitUsage    = 2
+	ExitInternal = 3
+)
+
+// driver holds the standard streams of an invocation, and the context
+// bounding it.
+type driver struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a subcommand of the driver.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(d driver, c command, args []string) int
+}
+
+// commands lists the subcommands, in the order the help presents them.
+var commands = []command{
+	{"lex", "lex [flags] file", "print the tokens of a file", lexCmd},
+	{"parse", "parse [flags] file", "print the syntax tree of a file", parseCmd},
+	{"check", "check [flags] [file|dir]", "report the errors and warnings of a program", checkCmd},
+	{"run", "run [flags] [file|dir] [arg...]", "evaluate a program and print its result", runCmd},
+	{"build", "build [flags] [file|dir]", "link a program into a single closed term", buildCmd},
+	{"blc", "blc encode|decode [flags] [file|dir]", "convert programs to and from binary lambda calculus", blcCmd},
+	{"fmt", "fmt [flags] file...", "print files in canonical form", fmtCmd},
+	{"repl", "repl [flags] [file...]", "start an interactive session, loading the given files", replCmd},
+	{"lsp", "lsp", "start a language server on the standard input and output", lspCmd},
+	{"clean", "clean [flags]", "remove the files the compiler keeps around", cleanCmd},
+}
+
+// Main runs the command line args, not including the program name, and returns
+// the exit code. Evaluations are interrupted when ctx is done or the process
+// receives an interrupt signal. The REPL catches the signal only while it
+// evaluates, so that it interrupts the evaluation rather than the session.
+func Main(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) (code int) {
+	d := driver{ctx: ctx, stdin: stdin, stdout: stdout, stderr: stderr}
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(stderr, "lambdac: internal error: %v\n%s", r, debug.Stack())
+			code = ExitInternal
+		}
+	}()
+
+	if len(args) == 0 {
+		d.usage(stderr)
+		return ExitUsage
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		return d.help(args[1:])
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			if c.name != "repl" {
+				var stop context.CancelFunc
+				d.ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+				defer stop()
+			}
+			return c.run(d, c, args[1:])
+		}
+	}
+	fmt.Fprintf(stderr, "lambdac: unknown command %q\n", args[0])
+	d.usage(stderr)
+	return ExitUsage
+}
+
+// usage writes the list of subcommands.
+func (d driver) usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: lambdac <command> [flags] [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-6s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w, "\nRun \"lambdac help <command>\" for the flags of a command.")
+}
+
+// help implements "lambdac help [command]".
+func (d driver) help(args []string) int {
+	if len(args) == 0 {
+		d.usage(d.stdout)
+		return ExitOK
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			d.stderr = d.stdout
+			return c.run(d, c, []string{"-h"})
+		}
+	}
+	fmt.Fprintf(d.stderr, "lambdac: unknown command %q\n", args[0])
+	return ExitUsage
+}
+
+// flagSet returns an empty flag set for c, whose usage describes c.
+func (d driver) flagSet(c command) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(d.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: lambdac %s\n\n%s.\n", c.usage, capitalize(c.summary))
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(fs.Output(), "\nFlags:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// parseFlags parses args with fs, and checks that the number of remaining
+// arguments is between min and max, max being ignored if negative. It returns
+// the remaining arguments, or the exit code if parsing failed or help was
+// requested.
+func (d driver) parseFlags(fs *flag.FlagSet, args []string, min, max int) ([]string, int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, ExitOK, false
+		}
+		return nil, ExitUsage, false
+	}
+	if fs.NArg() < min || (max >= 0 && fs.NArg() > max) {
+		fmt.Fprintf(d.stderr, "lambdac %s: wrong number of arguments\n", fs.Name())
+		fs.Usage()
+		return nil, ExitUsage, false
+	}
+	return fs.Args(), ExitOK, true
+}
+
+// source is a source file, read in full.
+type source struct {
+	name     string
+	text     string
+	renderer diagnostic.Renderer
+}
+
+// stdinName is the name of the standard input in messages.
+const stdinName = "<stdin>"
+
+// newSource builds the source of the named file.
+func newSource(name, text string) source {
+	return source{name: name, text: text, renderer: diagnostic.NewRenderer(name, text)}
+}
+
+// read reads the named file, or the standard input if the name is "-".
+func (d driver) read(name string) (source, error) {
+	var content []byte
+	var err error
+	if name == "-" {
+		name = stdinName
+		content, err = io.ReadAll(d.stdin)
+	} else {
+		content, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return source{}, err
+	}
+	return newSource(name, string(content)), nil
+}
+
+// readFailed reports a file that could not be read, which is a user error.
+func (d driver) readFailed(err error) int {
+	fmt.Fprintf(d.stderr, "lambdac: %v\n", err)
+	return ExitFailure
+}
+
+// report writes the diagnostics of src and returns whether any is an error.
+func (d driver) report(src source, diagnostics []diagnostic.Diagnostic) bool {
+	for _, diag := range diagnostics {
+		fmt.Fprint(d.stderr, src.renderer.Render(diag))
+	}
+	return diagnostic.HasErrors(diagnostics)
+}
+
+// fail writes an error related to src, or to the module the error belongs
+// to, and returns ExitFailure.
+func (d driver) fail(src source, err error) int {
+	var located module.Error
+	if errors.As(err, &located) {
+		fmt.Fprint(d.stderr, newSource(located.File(), located.Source()).renderer.RenderError(located.Unwrap()))
+		return ExitFailure
+	}
+	fmt.Fprint(d.stderr, src.renderer.RenderError(err))
+	return ExitFailure
+}
+
+// output writes the result of a command to the standard output. Failing to
+// do so is an internal error.
+func (d driver) output(s string) int {
+	if _, err := io.WriteString(d.stdout, s); err != nil {
+		fmt.Fprintf(d.stderr, "lambdac: %v\n", err)
+		return ExitInternal
+	}
+	return ExitOK
+}
+
+// write writes the result of a command to the named file. Failing to do so is
+// a user error, as the file name comes from the command line.
+func (d driver) write(name, s string) int {
+	if err := os.WriteFile(name, []byte(s), 0o644); err != nil {
+		fmt.Fprintf(d.stderr, "lambdac: %v\n", err)
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+// capitalize returns s with its first letter in upper case.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,433 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain keeps the build cache of the tests away from the one of the user.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "lambdac-cache")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.Setenv("LAMBDAC_CACHE", dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// invoke runs the driver with the given standard input and arguments.
+func invoke(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Main(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// file writes a source file in a temporary directory and returns its path.
+func file(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "main.lc")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+const parity = `t := \x.\_.x
+f := \_.\y.y
+zero := \n.n (\_.f) t
+pred := \n.\g.\x.n (\h.\k.k (h g)) (\_.x) (\u.u)
+even := \n.zero n t (odd (pred n))
+odd := \n.zero n f (even (pred n))
+main := even (\s.\z.s (s (s z)))
+`
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		stdin  string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{
+			"lex", "i := \\x.x\n", []string{"lex", "-"}, ExitOK,
+			"1:0\tIDENT\t\"i\"\n1:2\t:=\t\":=\"\n1:5\t\\\t\"\\\\\"\n1:6\tIDENT\t\"x\"\n" +
+				"1:7\t.\t\".\"\n1:8\tIDENT\t\"x\"\n1:9\tEOL\t\"\"\n2:0\tEOF\t\"\"\n",
+			"",
+		},
+		{
+			"lex illegal", `x := "a`, []string{"lex", "-"}, ExitFailure,
+			"1:0\tIDENT\t\"x\"\n1:2\t:=\t\":=\"\n",
+			"<stdin>:1:6: error: illegal token\n  1 | x := \"a\n    |      ^\n",
+		},
+		{
+			"parse", `i := \x.x`, []string{"parse", "-"}, ExitOK,
+			"(PROGRAM (:= i (\\ x x)) (EOF))\n", "",
+		},
+		{
+			"parse error", `i := \x.`, []string{"parse", "-"}, ExitFailure,
+			"", "<stdin>:1:9: error: unexpected end of input, expected an expression\n" +
+				"  1 | i := \\x.\n    |         ^\n",
+		},
+		{
+			"check", "main := fo\nfoo := \\x.x\n", []string{"check", "-"}, ExitFailure,
+			"", "<stdin>:1:9: error: unbound identifier \"fo\"\n  1 | main := fo\n    |         ^\n" +
+				"<stdin>:2:1: warning: definition \"foo\" is never used\n  2 | foo := \\x.x\n    | ^\n",
+		},
+		{
+			"check suggestion", "foo := \\x.x\nmain := fooo foo\n", []string{"check", "-"}, ExitFailure,
+			"", "<stdin>:2:9: error: unbound identifier \"fooo\"\n  2 | main := fooo foo\n    |         ^\n" +
+				"    = did you mean \"foo\"?\n",
+		},
+		{
+			"check recursion", parity, []string{"check", "-"}, ExitFailure,
+			"", "<stdin>:5:1: error: definitions \"even\" and \"odd\" are mutually recursive: even -> odd -> even\n" +
+				"  5 | even := \\n.zero n t (odd (pred n))\n    | ^\n" +
+				"    = apply a fixpoint combinator, or enable automatic fixpoint insertion\n",
+		},
+		{"check fixpoint", parity, []string{"check", "-fixpoint", "y", "-"}, ExitOK, "", ""},
+		{"run", parity, []string{"run", "-fixpoint", "y", "-"}, ExitOK, "\\_.\\y.y\n", ""},
+		{
+			"run stdlib",
+			"b | \"std/bool\"\nl | \"std/list\"\nmain := l->head b->true (l->tail (l->map b->not (l->cons b->false (l->cons b->true l->nil))))\n",
+			[]string{"run", "-"}, ExitOK, "\\_.\\f.f\n", "",
+		},
+		{
+			"run string",
+			"l | \"std/list\"\nmain := l->append \"hello, \" \"wörld\\n\"\n",
+			[]string{"run", "-print", "string", "-"}, ExitOK, "hello, wörld\n\n", "",
+		},
+		{"run numeral", `(\m.\n.\f.m (n f)) (\f.\x.f (f x)) (\f.\x.f (f (f x)))`, []string{"run", "-print", "numeral", "-"}, ExitOK, "6\n", ""},
+		{
+			"run not a string", `\x.x`, []string{"run", "-print", "string", "-"}, ExitFailure,
+			"", "lambdac run: the result is not a string: \\x.x\n",
+		},
+		{"run entry", "i := \\x.x\nk := \\x.\\_.x\nstart := k i\n", []string{"run", "-entry", "start", "-"}, ExitOK, "\\_.\\x.x\n", ""},
+		{
+			"run trace", `(\x.x) (\y.y)`, []string{"run", "-trace", "text", "-"}, ExitOK,
+			"\\y.y\n", "1. 1:1 leftmost-outermost redex: [(\\x.x) (\\y.y)]\n   => \\y.y\n",
+		},
+		{
+			"run step limit", `(\x.x x) (\x.x x)`, []string{"run", "-steps", "10", "-strategy", "need", "-"}, ExitFailure,
+			"", "<stdin>: step limit exceeded: gave up after 10 steps\n",
+		},
+		{
+			"run no entry", `i := \x.x`, []string{"run", "-"}, ExitFailure,
+			"", "<stdin>:1:1: error: no entry point: the program has no expression and no \"main\" definition\n" +
+				"  1 | i := \\x.x\n    | ^\n",
+		},
+		{"build", "i := \\x.x\nmain := i i\n", []string{"build", "-"}, ExitOK, "(\\x.x) (\\x.x)\n", ""},
+		{"build ski", "main := (\\x.\\_.x) (\\f.\\x.f x)\n", []string{"build", "-ski", "ski", "-"}, ExitOK, "K I\n", ""},
+		{"build turner", "main := \\f.\\x.f (f x)\n", []string{"build", "-ski", "turner", "-"}, ExitOK, "S B I\n", ""},
+		{"blc encode", "k := \\x.\\_.x\nmain := k k\n", []string{"blc", "encode", "-"}, ExitOK, "0100001100000110\n", ""},
+		{"blc size", "main := \\x.x\n", []string{"blc", "encode", "-size", "-"}, ExitOK, "4\n", ""},
+		{"blc decode", "0000 110\n", []string{"blc", "decode", "-"}, ExitOK, "\\x.\\x1.x\n", ""},
+		{"blc decode error", "0010 1", []string{"blc", "decode", "-"}, ExitFailure, "", "<stdin>: bit 4: unexpected bits after the term\n"},
+		{
+			"fmt", "io|\"std/io\"\ni:=\\x .x -- identity\n\n\n(\\x. x)(f(x))\n", []string{"fmt", "-"}, ExitOK,
+			"io | \"std/io\"\ni := \\x.x -- identity\n\n(\\x.x) (f x)\n", "",
+		},
+		{
+			"fmt diff", "i:=\\x.x\nk := \\x.\\y.x\n", []string{"fmt", "-d", "-"}, ExitOK,
+			"--- <stdin>\n+++ <stdin> (formatted)\n@@ -1,2 +1,2 @@\n-i:=\\x.x\n+i := \\x.x\n k := \\x.\\y.x\n", "",
+		},
+		{"fmt list", "i := \\x.x\n", []string{"fmt", "-l", "-"}, ExitOK, "", ""},
+		{
+			"fmt write stdin", "i := \\x.x\n", []string{"fmt", "-w", "-"}, ExitUsage,
+			"", "lambdac fmt: -w cannot write back to the standard input\n",
+		},
+		{"repl", "i := \\x.x\ni y\n", []string{"repl"}, ExitOK, "λ.c, type :help for help\nλ> λ> y\nλ> \n", ""},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			code, stdout, stderr := invoke(testCase.stdin, testCase.args...)
+			is.Equal(testCase.stdout, stdout)
+			is.Equal(testCase.stderr, stderr)
+			is.Equal(testCase.code, code)
+		})
+	}
+}
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	code, _, stderr := invoke("")
+	is.Equal(ExitUsage, code)
+	is.Contains(stderr, "usage: lambdac <command> [flags] [arguments]")
+
+	code, _, stderr = invoke("", "frobnicate")
+	is.Equal(ExitUsage, code)
+	is.Contains(stderr, `lambdac: unknown command "frobnicate"`)
+
+	code, stdout, _ := invoke("", "help")
+	is.Equal(ExitOK, code)
+	is.Contains(stdout, "  run    evaluate a program and print its result\n")
+
+	code, stdout, _ = invoke("", "help", "run")
+	is.Equal(ExitOK, code)
+	is.Contains(stdout, "usage: lambdac run [flags] [file|dir] [arg...]\n\nEvaluate a program and print its result.\n\nFlags:\n")
+	is.Contains(stdout, "-strategy string")
+
+	code, _, stderr = invoke("", "run", "-strategy", "lazy", "-")
+	is.Equal(ExitUsage, code)
+	is.Contains(stderr, `unknown evaluation strategy "lazy"`)
+
+	code, _, stderr = invoke("", "run", "-trace", "xml", "-")
+	is.Equal(ExitUsage, code)
+	is.Equal("lambdac run: -trace must be text or json, not \"xml\"\n", stderr)
+
+	code, _, stderr = invoke("", "run", "-print", "bits", "-")
+	is.Equal(ExitUsage, code)
+	is.Equal("lambdac run: -print must be term, numeral or string, not \"bits\"\n", stderr)
+
+	code, _, stderr = invoke("", "build", "-ski", "sk", "-")
+	is.Equal(ExitUsage, code)
+	is.Equal("lambdac build: -ski must be ski or turner, not \"sk\"\n", stderr)
+
+	code, _, stderr = invoke("", "blc", "compress", "-")
+	is.Equal(ExitUsage, code)
+	is.Contains(stderr, "lambdac blc: unknown subcommand \"compress\"\n")
+	code, _, stderr = invoke("", "blc")
+	is.Equal(ExitUsage, code)
+	is.Contains(stderr, "lambdac blc: expected encode or decode\n")
+	code, stdout, _ = invoke("", "help", "blc")
+	is.Equal(ExitOK, code)
+	is.Contains(stdout, "  decode decode a term from binary lambda calculus\n")
+
+	code, _, stderr = invoke("", "check", "-bogus", "-")
+	is.Equal(ExitUsage, code)
+	is.Contains(stderr, "flag provided but not defined: -bogus")
+
+	code, _, stderr = invoke("", "parse")
+	is.Equal(ExitUsage, code)
+	is.Contains(stderr, "lambdac parse: wrong number of arguments\n")
+}
+
+func TestModules(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	path := file(t, "b | \"lib/bool\"\nmain := b->not b->true\n")
+	lib := filepath.Join(filepath.Dir(path), "lib")
+	is.NoError(os.Mkdir(lib, 0o700))
+	is.NoError(os.WriteFile(filepath.Join(lib, "bool.lc"),
+		[]byte("true := \\t.\\f.t\nfalse := \\t.\\f.f\nnot := \\b.b false true\n"), 0o600))
+
+	code, stdout, stderr := invoke("", "run", path)
+	is.Equal(ExitOK, code, stderr)
+	is.Equal("\\t.\\f.f\n", stdout)
+
+	is.NoError(os.WriteFile(filepath.Join(lib, "bool.lc"), []byte("not := \\b.b fals\n"), 0o600))
+	code, _, stderr = invoke("", "check", path)
+	is.Equal(ExitFailure, code)
+	is.Equal(filepath.Join(lib, "bool.lc")+":1:13: error: unbound identifier \"fals\"\n"+
+		"  1 | not := \\b.b fals\n    |             ^\n"+
+		path+":2:19: error: namespace \"b\" has no definition \"true\"\n"+
+		"  2 | main := b->not b->true\n    |                   ^\n", stderr)
+
+	code, _, stderr = invoke("b | \"lib/bool\"\n", "check", "-")
+	is.Equal(ExitFailure, code)
+	is.Contains(stderr, "<stdin>:1:5: error: cannot find module \"lib/bool\"")
+}
+
+func TestProjects(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		is.NoError(os.MkdirAll(filepath.Dir(path), 0o700))
+		is.NoError(os.WriteFile(path, []byte(content), 0o600))
+	}
+	write("lambdac.mod", "module example.com/calc\nentry start\n")
+	write("lib/bool.lc", "true := \\t.\\_.t\nfalse := \\_.\\f.f\nnot := \\b.b false true\n")
+	write("app.lc", "b | \"example.com/calc/lib/bool\"\nstart := b->not b->true\n")
+
+	code, stdout, stderr := invoke("", "run", dir)
+	is.Equal(ExitOK, code, stderr)
+	is.Equal("\\_.\\f.f\n", stdout)
+
+	code, stdout, stderr = invoke("", "build", filepath.Join(dir, "app.lc"))
+	is.Equal(ExitOK, code, stderr)
+	is.Equal("(\\b.b (\\_.\\f.f) (\\t.\\_.t)) (\\t.\\_.t)\n", stdout)
+
+	code, stdout, _ = invoke("", "run", "-entry", "b->true", filepath.Join(dir, "app.lc"))
+	is.Equal(ExitFailure, code)
+	is.Empty(stdout)
+
+	write("other.lc", "start := \\x.x\nmain := y\n")
+	code, _, stderr = invoke("", "check", dir)
+	is.Equal(ExitFailure, code)
+	is.Contains(stderr, filepath.Join(dir, "other.lc")+":2:9: error: unbound identifier \"y\"\n"+
+		"  2 | main := y\n    |         ^\n")
+
+	write("other.lc", "start := \\x.x\n")
+	code, _, stderr = invoke("", "run", dir)
+	is.Equal(ExitFailure, code)
+	is.Equal(fmt.Sprintf("lambdac: several modules of %s define the entry point \"start\": %s, %s\n",
+		dir, filepath.Join(dir, "app.lc"), filepath.Join(dir, "other.lc")), stderr)
+
+	write("lambdac.mod", "module example.com/calc\nentry start\nversion 2\n")
+	code, _, stderr = invoke("", "check", dir)
+	is.Equal(ExitFailure, code)
+	is.Contains(stderr, filepath.Join(dir, "lambdac.mod")+":3:1: error: unknown directive \"version\"")
+}
+
+func TestEffects(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	echo := file(t, "io | \"std/io\"\nmain := io->bind io->args (io->each io->println)\n")
+	code, stdout, stderr := invoke("", "run", "-io", echo, "hello", "world")
+	is.Equal(ExitOK, code, stderr)
+	is.Equal("hello\nworld\n", stdout)
+
+	greet := file(t, "io | \"std/io\"\nn | \"std/nat\"\n"+
+		"main := io->bind io->readline (\\name.io->then (io->eprintln name) (io->exit (n->succ n->one)))\n")
+	code, stdout, stderr = invoke("ada\nbob\n", "run", "-io", greet)
+	is.Equal(2, code)
+	is.Empty(stdout)
+	is.Equal("ada\n", stderr)
+
+	code, _, stderr = invoke("", "run", echo, "hello")
+	is.Equal(ExitUsage, code)
+	is.Equal("lambdac run: only -io programs take arguments\n", stderr)
+	code, _, stderr = invoke("", "run", "-io", "-strategy", "value", "-print", "string", echo)
+	is.Equal(ExitUsage, code)
+	is.Equal("lambdac run: -print and -strategy cannot be used with -io\n", stderr)
+
+	code, _, stderr = invoke("", "run", "-io", file(t, "main := \\_.\\_.\\x.x\n"))
+	is.Equal(ExitFailure, code)
+	is.Contains(stderr, "the program is not an IO action: \\x.x")
+}
+
+func TestCache(t *testing.T) {
+	is := require.New(t)
+	cacheDir := t.TempDir()
+	t.Setenv("LAMBDAC_CACHE", cacheDir)
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		is.NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	write("bool.lc", "true := \\t.\\_.t\nfalse := \\_.\\f.f\n")
+	write("main.lc", "b | \"bool\"\nmain := b->true b->flase\n")
+	main := filepath.Join(dir, "main.lc")
+
+	// Cached analyses report the same diagnostics
+	code, _, first := invoke("", "check", main)
+	is.Equal(ExitFailure, code)
+	is.Contains(first, `namespace "b" has no definition "flase"`)
+	entries, err := os.ReadDir(cacheDir)
+	is.NoError(err)
+	is.NotEmpty(entries)
+	code, _, second := invoke("", "check", main)
+	is.Equal(ExitFailure, code)
+	is.Equal(first, second)
+
+	// Changing an imported module invalidates the analysis of its importers
+	write("bool.lc", "true := \\t.\\_.t\nflase := \\_.\\f.f\n")
+	code, stdout, stderr := invoke("", "run", main)
+	is.Equal(ExitOK, code, stderr)
+	is.Equal("\\_.\\_.\\f.f\n", stdout)
+
+	code, _, _ = invoke("", "clean", "-cache")
+	is.Equal(ExitOK, code)
+	entries, err = os.ReadDir(cacheDir)
+	is.NoError(err)
+	is.Empty(entries)
+
+	code, _, stderr = invoke("", "clean")
+	is.Equal(ExitUsage, code)
+	is.Equal("lambdac clean: nothing to clean, use -cache to remove the build cache\n", stderr)
+
+	t.Setenv("LAMBDAC_CACHE", "off")
+	code, _, stderr = invoke("", "clean", "-cache")
+	is.Equal(ExitFailure, code)
+	is.Equal("lambdac clean: the cache is disabled (LAMBDAC_CACHE=off)\n", stderr)
+	code, _, stderr = invoke("", "check", main)
+	is.Equal(ExitOK, code, stderr)
+}
+
+func TestLanguageServer(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	var session strings.Builder
+	for _, message := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(&session, "Content-Length: %d\r\n\r\n%s", len(message), message)
+	}
+	code, stdout, _ := invoke(session.String(), "lsp")
+	is.Equal(ExitOK, code)
+	is.Contains(stdout, `"documentFormattingProvider":true`)
+
+	code, _, _ = invoke("Content-Length: 33\r\n\r\n"+`{"jsonrpc":"2.0","method":"exit"}`, "lsp")
+	is.Equal(ExitFailure, code)
+}
+
+func TestFiles(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	path := file(t, "i := \\x.x\nmain := i i\n")
+	code, stdout, _ := invoke("", "run", path)
+	is.Equal(ExitOK, code)
+	is.Equal("\\x.x\n", stdout)
+
+	out := filepath.Join(t.TempDir(), "main.out")
+	code, _, _ = invoke("", "build", "-o", out, path)
+	is.Equal(ExitOK, code)
+	content, err := os.ReadFile(out)
+	is.NoError(err)
+	is.Equal("(\\x.x) (\\x.x)\n", string(content))
+
+	code, _, _ = invoke("", "blc", "encode", "-packed", "-o", out, path)
+	is.Equal(ExitOK, code)
+	content, err = os.ReadFile(out)
+	is.NoError(err)
+	is.Equal([]byte{0x48, 0x80}, content)
+	code, stdout, _ = invoke("", "blc", "decode", "-packed", out)
+	is.Equal(ExitOK, code)
+	is.Equal("(\\x.x) (\\x.x)\n", stdout)
+
+	code, _, stderr := invoke("", "check", filepath.Join(t.TempDir(), "missing.lc"))
+	is.Equal(ExitFailure, code)
+	is.Contains(stderr, "no such file or directory")
+
+	tidy, untidy := file(t, "i := \\x.x\n"), file(t, "i:=\\x.x\n")
+	code, stdout, _ = invoke("", "fmt", "-l", "-w", tidy, untidy)
+	is.Equal(ExitOK, code)
+	is.Equal(untidy+"\n", stdout)
+	content, err = os.ReadFile(untidy)
+	is.NoError(err)
+	is.Equal("i := \\x.x\n", string(content))
+
+	code, stdout, _ = invoke("y\n", "repl", path)
+	is.Equal(ExitOK, code)
+	is.Contains(stdout, "λ> y\n")
+}
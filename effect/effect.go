@@ -0,0 +1,335 @@
+// Package effect performs the input and output of λ.c programs.
+//
+// Overview:
+//
+// Evaluation is pure: a program describes its effects as an action of
+// std/io, which an Interpreter performs. Actions are Church-encoded free
+// monads, taking two arguments: what to do with the result of the action,
+// and what to do with a request for an effect, given the name of the effect,
+// the list of its arguments and the continuation expecting its response.
+//
+// The Interpreter applies the action to two free variables standing for
+// these, and evaluates the application by need to weak head normal form,
+// which is either the first variable applied to the result, in which case
+// the program is over, or the second applied to a request. It then
+// normalizes the name and the arguments of the request, performs the effect
+// and carries on with the continuation applied to the response. Evaluating
+// the continuation lazily lets programs loop forever, such as a program
+// echoing its input line by line.
+//
+// Effects:
+//
+// The requests of std/io are:
+//
+//   - "stdout" s and "stderr" s write the string s;
+//   - "readline" yields the next line of the standard input, without its
+//     line feed, or the empty string at the end of the input;
+//   - "eof" yields whether the standard input is exhausted;
+//   - "read" yields the rest of the standard input;
+//   - "readfile" path yields the content of a file;
+//   - "writefile" path s writes the string s to a file;
+//   - "args" yields the command-line arguments of the program;
+//   - "exit" code ends the program with the numeral code as exit code.
+//
+// The requests of std/net are:
+//
+//   - "listen" address yields the handle of a TCP listener on the address;
+//   - "address" listener yields the address the listener listens on;
+//   - "accept" listener waits for a connection, and yields its handle;
+//   - "dial" address connects to the address, and yields the handle of the
+//     connection;
+//   - "recvline", "recveof" and "recv" connection read from the connection,
+//     as "readline", "eof" and "read" do from the standard input;
+//   - "send" connection s writes the string s to the connection;
+//   - "close" handle closes a listener or a connection.
+//
+// Handles are numerals, starting at 1. The listeners and connections still
+// open when the program ends are closed.
+//
+// Effects that yield nothing yield the identity. Effects that fail, such as
+// reading a file that does not exist, abort the program with an error.
+package effect
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// The free variables the Interpreter applies actions to. They contain a dot,
+// which neither identifiers nor linked global names do.
+const (
+	done    = "io.done"
+	request = "io.request"
+)
+
+// Interpreter performs the effects of std/io actions. It follows the immutable
+// builder pattern: configuration methods return updated copies.
+type Interpreter struct {
+	stdin    *bufio.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+	args     []string
+	maxSteps int
+	tracer   eval.Tracer
+}
+
+// NewInterpreter returns an Interpreter reading the standard input of
+// programs from stdin and writing their outputs to stdout and stderr. The
+// evaluation between two effects is limited to eval.DefaultMaxSteps steps.
+func NewInterpreter(stdin io.Reader, stdout, stderr io.Writer) Interpreter {
+	return Interpreter{
+		stdin:    bufio.NewReader(stdin),
+		stdout:   stdout,
+		stderr:   stderr,
+		maxSteps: eval.DefaultMaxSteps,
+	}
+}
+
+// WithArgs returns a copy of the interpreter giving programs the command-line
+// arguments args.
+func (i Interpreter) WithArgs(args ...string) Interpreter {
+	i.args = append([]string{}, args...)
+	return i
+}
+
+// WithMaxSteps returns a copy of the interpreter limiting the evaluation
+// between two effects to n reduction steps, or unlimited if n is zero or
+// less. Programs running forever are only stopped by the context.
+func (i Interpreter) WithMaxSteps(n int) Interpreter {
+	i.maxSteps = n
+	return i
+}
+
+// WithTracer returns a copy of the interpreter reporting every reduction step
+// to tracer.
+func (i Interpreter) WithTracer(tracer eval.Tracer) Interpreter {
+	i.tracer = tracer
+	return i
+}
+
+// Run performs the effects of action, and returns the exit code of the
+// program.
+func (i Interpreter) Run(ctx context.Context, action term.Term) monad.Result[int, error] {
+	whnf := eval.NewCallByNeed().WithMaxSteps(i.maxSteps).WithTracer(i.tracer)
+	t := term.NewApp(term.NewApp(action, term.NewVar(done)), term.NewVar(request))
+
+	// Closing the network when the context is done unblocks the effects
+	// waiting on it
+	network := newNetwork()
+	defer network.close()
+	stop := context.AfterFunc(ctx, network.close)
+	defer stop()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return monad.Fail[int, error](err)
+		}
+		result := whnf.Evaluate(ctx, t)
+		if result.Failure() {
+			return monad.Fail[int, error](result.Error())
+		}
+
+		head, args := spine(result.Value())
+		switch {
+		case head == done && len(args) == 1:
+			return monad.Succeed[int, error](0)
+		case head == request && len(args) == 3:
+			c, err := i.decode(ctx, args[0], args[1])
+			if err != nil {
+				return monad.Fail[int, error](err)
+			}
+			if c.name == "exit" {
+				return c.exit()
+			}
+			response, err := i.perform(ctx, network, c)
+			if ctx.Err() != nil {
+				return monad.Fail[int, error](ctx.Err())
+			}
+			if err != nil {
+				return monad.Fail[int, error](err)
+			}
+			t = term.NewApp(args[2], response)
+		default:
+			return monad.Fail[int, error](fmt.Errorf("the program is not an IO action: %s", result.Value()))
+		}
+	}
+}
+
+// spine returns the name of the variable heading t, if any, and the
+// arguments it is applied to.
+func spine(t term.Term) (string, []term.Term) {
+	var args []term.Term
+	for {
+		switch u := t.(type) {
+		case term.App:
+			args = append([]term.Term{u.Arg()}, args...)
+			t = u.Fun()
+		case term.Var:
+			return u.Name(), args
+		default:
+			return "", args
+		}
+	}
+}
+
+// decode normalizes the name and the arguments of a request.
+func (i Interpreter) decode(ctx context.Context, name, arguments term.Term) (call, error) {
+	normal := eval.NewNormalOrder().WithMaxSteps(i.maxSteps).WithTracer(i.tracer)
+	effect := normal.Evaluate(ctx, name)
+	if effect.Failure() {
+		return call{}, effect.Error()
+	}
+	decoded := eval.ReadString(effect.Value())
+	if decoded.Nothing() {
+		return call{}, fmt.Errorf("the name of an effect is not a string: %s", effect.Value())
+	}
+	normalized := normal.Evaluate(ctx, arguments)
+	if normalized.Failure() {
+		return call{}, normalized.Error()
+	}
+	list := eval.ReadList(normalized.Value())
+	if list.Nothing() {
+		return call{}, fmt.Errorf("%s: the arguments are not a list: %s", decoded.Value(), normalized.Value())
+	}
+	return call{name: decoded.Value(), args: list.Value()}, nil
+}
+
+// perform performs the effect requested, but exit, and returns its response.
+func (i Interpreter) perform(ctx context.Context, network *network, c call) (term.Term, error) {
+	if isNetwork(c.name) {
+		return network.perform(ctx, c)
+	}
+	switch c.name {
+	case "stdout":
+		return c.write(i.stdout, 0)
+	case "stderr":
+		return c.write(i.stderr, 0)
+	case "readline":
+		line, err := i.stdin.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("readline: %w", err)
+		}
+		return term.Literal(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")), nil
+	case "eof":
+		_, err := i.stdin.Peek(1)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("eof: %w", err)
+		}
+		return boolean(errors.Is(err, io.EOF)), nil
+	case "read":
+		content, err := io.ReadAll(i.stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read: %w", err)
+		}
+		return term.Literal(string(content)), nil
+	case "readfile":
+		path, err := c.string(0)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("readfile: %w", err)
+		}
+		return term.Literal(string(content)), nil
+	case "writefile":
+		path, err := c.string(0)
+		if err != nil {
+			return nil, err
+		}
+		content, err := c.string(1)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return nil, fmt.Errorf("writefile: %w", err)
+		}
+		return unit(), nil
+	case "args":
+		var list term.Term = term.NewVar("n")
+		for j := len(i.args) - 1; j >= 0; j-- {
+			list = term.NewApp(term.NewApp(term.NewVar("c"), term.Literal(i.args[j])), list)
+		}
+		return term.NewAbs("c", term.NewAbs("n", list)), nil
+	}
+	return nil, fmt.Errorf("unknown effect %q", c.name)
+}
+
+// call is a request for an effect, whose arguments are in normal form.
+type call struct {
+	name string
+	args []term.Term
+}
+
+// string decodes the argument of the given index as a string.
+func (c call) string(index int) (string, error) {
+	if index >= len(c.args) {
+		return "", fmt.Errorf("%s: missing argument %d", c.name, index+1)
+	}
+	s := eval.ReadString(c.args[index])
+	if s.Nothing() {
+		return "", fmt.Errorf("%s: argument %d is not a string: %s", c.name, index+1, c.args[index])
+	}
+	return s.Value(), nil
+}
+
+// exit returns the exit code argument of the call.
+func (c call) exit() monad.Result[int, error] {
+	if len(c.args) != 1 {
+		return monad.Fail[int, error](fmt.Errorf("exit: expected 1 argument, got %d", len(c.args)))
+	}
+	code := eval.ReadNumeral(c.args[0])
+	if code.Nothing() {
+		return monad.Fail[int, error](fmt.Errorf("exit: the exit code is not a numeral: %s", c.args[0]))
+	}
+	return monad.Succeed[int, error](code.Value())
+}
+
+// handle decodes the first argument of the call as the handle of a listener
+// or a connection.
+func (c call) handle() (int, error) {
+	if len(c.args) == 0 {
+		return 0, fmt.Errorf("%s: missing argument 1", c.name)
+	}
+	handle := eval.ReadNumeral(c.args[0])
+	if handle.Nothing() {
+		return 0, fmt.Errorf("%s: argument 1 is not a handle: %s", c.name, c.args[0])
+	}
+	return handle.Value(), nil
+}
+
+// write writes the string argument of the given index to w.
+func (c call) write(w io.Writer, index int) (term.Term, error) {
+	s, err := c.string(index)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", c.name, err)
+	}
+	return unit(), nil
+}
+
+// unit is the response of the effects yielding nothing, the identity.
+func unit() term.Term {
+	return term.NewAbs("x", term.NewVar("x"))
+}
+
+// boolean returns the Church boolean of b.
+func boolean(b bool) term.Term {
+	chosen := "f"
+	if b {
+		chosen = "t"
+	}
+	return term.NewAbs("t", term.NewAbs("f", term.NewVar(chosen)))
+}
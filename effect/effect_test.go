@@ -0,0 +1,230 @@
+package effect
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/module"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// action loads and links source, which imports std/io as io, and returns its
+// main definition.
+func action(t *testing.T, source string) term.Term {
+	t.Helper()
+	g := module.NewLoader(module.Stdlib()).Load("main.lc", "io | \"std/io\"\n"+source)
+	require.True(t, g.Success(), "%v", g.Error())
+	require.Empty(t, g.Value().Root().Resolution().Diagnostics())
+	program := g.Value().Link()
+	require.True(t, program.Success(), "%v", program.Error())
+	entry := program.Value().Entry("main")
+	require.True(t, entry.Success(), "%v", entry.Error())
+	return entry.Value()
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		source string
+		stdin  string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"pure", `main := io->pure (\x.x)`, "", nil, 0, "", ""},
+		{"print", `main := io->then (io->print "a") (io->println "b")`, "", nil, 0, "ab\n", ""},
+		{"stderr", `main := io->then (io->eprint "a") (io->eprintln "b")`, "", nil, 0, "", "ab\n"},
+		{
+			"readline", `main := io->bind io->readline (\a.io->bind io->readline (\b.io->print b))`,
+			"one\r\ntwo", nil, 0, "two", "",
+		},
+		{"readline end", `main := io->bind io->readline io->print`, "", nil, 0, "", ""},
+		{
+			"eof", `main := io->bind io->eof (\e.io->when e (io->print "empty"))`,
+			"", nil, 0, "empty", "",
+		},
+		{
+			"not eof", `main := io->bind io->eof (\e.io->when e (io->print "empty"))`,
+			"\n", nil, 0, "", "",
+		},
+		{
+			"read", `l | "std/list"` + "\n" + `main := io->bind io->read (\s.io->print (l->reverse s))`,
+			"abc", nil, 0, "cba", "",
+		},
+		{
+			"map", `l | "std/list"` + "\n" + `main := io->bind (io->map l->reverse io->readline) io->print`,
+			"ab\ncd\n", nil, 0, "ba", "",
+		},
+		{
+			"args", `main := io->bind io->args (io->each io->println)`,
+			"", []string{"a", "β"}, 0, "a\nβ\n", "",
+		},
+		{
+			"exit", `main := io->then (io->exit (\f.\x.f (f (f x)))) (io->print "unreachable")`,
+			"", nil, 3, "", "",
+		},
+		{
+			"loop",
+			`l | "std/list"` + "\n" +
+				`main := (\f.(\x.f (x x)) (\x.f (x x))) (\loop.io->bind io->eof (\e.e (io->pure l->nil) ` +
+				`(io->bind io->readline (\s.io->then (io->println (l->reverse s)) loop))))`,
+			"ab\ncd\n", nil, 0, "ba\ndc\n", "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			var stdout, stderr bytes.Buffer
+			interpreter := NewInterpreter(strings.NewReader(testCase.stdin), &stdout, &stderr).
+				WithArgs(testCase.args...)
+			code := interpreter.Run(context.Background(), action(t, testCase.source))
+			is.True(code.Success(), "%v", code.Error())
+			is.Equal(testCase.code, code.Value())
+			is.Equal(testCase.stdout, stdout.String())
+			is.Equal(testCase.stderr, stderr.String())
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "out.txt")
+	var stdout bytes.Buffer
+	interpreter := NewInterpreter(strings.NewReader(path), &stdout, &bytes.Buffer{})
+	code := interpreter.Run(context.Background(), action(t,
+		`main := io->bind io->read (\p.io->then (io->writefile p "λ.c") (io->bind (io->readfile p) io->print))`))
+	is.True(code.Success(), "%v", code.Error())
+	is.Equal("λ.c", stdout.String())
+	content, err := os.ReadFile(path)
+	is.NoError(err)
+	is.Equal("λ.c", string(content))
+
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	interpreter = NewInterpreter(strings.NewReader(missing), &stdout, &bytes.Buffer{})
+	code = interpreter.Run(context.Background(), action(t, `main := io->bind io->read io->readfile`))
+	is.ErrorIs(code.Error(), os.ErrNotExist)
+	is.ErrorContains(code.Error(), "readfile: ")
+}
+
+// echo is a λ.c echo server, answering a single client line by line, which
+// prints the address it listens on.
+const echo = `c | "std/combinator"
+l | "std/list"
+n | "std/net"
+main := io->bind (n->listen "127.0.0.1:0") (\s.io->then (io->bind (n->address s) io->println) (io->bind (n->accept s) echo))
+echo := c->Y (\echo.\c.io->bind (n->eof c) (\e.e (n->close c) (io->bind (n->readline c) ` +
+	`(\line.io->then (n->write c (l->append line "\n")) (echo c)))))`
+
+func TestNetwork(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	// A λ.c client of a Go echo server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	is.NoError(err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	var stdout bytes.Buffer
+	interpreter := NewInterpreter(strings.NewReader(""), &stdout, &bytes.Buffer{}).
+		WithArgs(listener.Addr().String())
+	code := interpreter.Run(context.Background(), action(t, `l | "std/list"
+n | "std/net"
+main := io->bind io->args (\as.io->bind (n->dial (l->head "" as)) (\c.io->then (n->write c "ping\npong\n")
+  (io->bind (n->readline c) (\a.io->bind (n->readline c) (\b.io->then (n->close c) (io->print (l->append b a)))))))`))
+	is.True(code.Success(), "%v", code.Error())
+	is.Equal("pongping", stdout.String())
+
+	// A Go client of a λ.c echo server
+	out, in := io.Pipe()
+	result := make(chan error, 1)
+	go func() {
+		interpreter := NewInterpreter(strings.NewReader(""), in, &bytes.Buffer{})
+		result <- interpreter.Run(context.Background(), action(t, echo)).Error()
+		in.Close()
+	}()
+	address, err := bufio.NewReader(out).ReadString('\n')
+	is.NoError(err)
+	conn, err := net.Dial("tcp", strings.TrimSuffix(address, "\n"))
+	is.NoError(err)
+	defer conn.Close()
+	_, err = io.WriteString(conn, "hello\nwörld\n")
+	is.NoError(err)
+	is.NoError(conn.(*net.TCPConn).CloseWrite())
+	echoed, err := io.ReadAll(conn)
+	is.NoError(err)
+	is.Equal("hello\nwörld\n", string(echoed))
+	is.NoError(<-result)
+}
+
+func TestNetworkErrors(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	run := func(ctx context.Context, source string) error {
+		interpreter := NewInterpreter(strings.NewReader(""), io.Discard, io.Discard)
+		return interpreter.Run(ctx, action(t, "n | \"std/net\"\n"+source)).Error()
+	}
+
+	is.EqualError(run(context.Background(), `main := n->close (\f.\x.f x)`), "close: no listener or connection 1")
+	is.EqualError(run(context.Background(), `main := n->readline (\f.\x.x)`), "recvline: no connection 0")
+	is.EqualError(run(context.Background(), `main := n->accept (\x.x x)`), `accept: argument 1 is not a handle: \x.x x`)
+	is.ErrorContains(run(context.Background(), `main := n->listen "nowhere"`), "listen: ")
+	is.ErrorContains(run(context.Background(), `main := n->dial "127.0.0.1:0"`), "dial: ")
+
+	// Waiting for a connection stops with the context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	is.ErrorIs(run(ctx, `main := io->bind (n->listen "127.0.0.1:0") n->accept`), context.DeadlineExceeded)
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	run := func(source string) error {
+		interpreter := NewInterpreter(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}).WithMaxSteps(10000)
+		return interpreter.Run(context.Background(), action(t, source)).Error()
+	}
+
+	is.EqualError(run(`main := \r.\e.e "beep" (\c.\n.n) r`), `unknown effect "beep"`)
+	is.EqualError(run(`main := io->print (\x.x)`), `stdout: argument 1 is not a string: \x.x`)
+	is.EqualError(run(`main := io->exit (\x.x x)`), `exit: the exit code is not a numeral: \x.x x`)
+	is.EqualError(run(`main := \r.\e.e (\x.x) (\c.\n.n) r`), `the name of an effect is not a string: \x.x`)
+	is.EqualError(run(`main := \r.\e.e "stdout" (\x.x) r`), `stdout: the arguments are not a list: \x.x`)
+	is.EqualError(run(`main := \r.\e.e "readfile" (\c.\n.n) r`), `readfile: missing argument 1`)
+	is.ErrorIs(run(`main := io->then (io->print "a") ((\x.x x) (\x.x x))`), eval.ErrStepLimit)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	code := NewInterpreter(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}).
+		Run(ctx, action(t, `main := io->pure (\x.x)`))
+	is.ErrorIs(code.Error(), context.Canceled)
+}
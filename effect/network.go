@@ -0,0 +1,216 @@
+package effect
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// network holds the listeners and connections of a program, by handle. The
+// handles are the numerals the program refers to them with, starting at 1.
+type network struct {
+	mu          sync.Mutex
+	listeners   map[int]net.Listener
+	connections map[int]*connection
+	next        int
+	closed      bool
+}
+
+// connection is a TCP connection, read through a buffer for readline.
+type connection struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newNetwork() *network {
+	return &network{listeners: map[int]net.Listener{}, connections: map[int]*connection{}, next: 1}
+}
+
+// isNetwork tells whether the effect of the given name is a network one.
+func isNetwork(name string) bool {
+	switch name {
+	case "listen", "address", "accept", "dial", "recvline", "recveof", "recv", "send", "close":
+		return true
+	}
+	return false
+}
+
+// perform performs a network effect, and returns its response.
+func (n *network) perform(ctx context.Context, c call) (term.Term, error) {
+	switch c.name {
+	case "listen", "dial":
+		address, err := c.string(0)
+		if err != nil {
+			return nil, err
+		}
+		if c.name == "listen" {
+			var config net.ListenConfig
+			l, err := config.Listen(ctx, "tcp", address)
+			if err != nil {
+				return nil, fmt.Errorf("listen: %w", err)
+			}
+			return n.register(l, nil)
+		}
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("dial: %w", err)
+		}
+		return n.register(nil, conn)
+	case "address", "accept":
+		l, err := n.listener(c)
+		if err != nil {
+			return nil, err
+		}
+		if c.name == "address" {
+			return term.Literal(l.Addr().String()), nil
+		}
+		conn, err := l.Accept()
+		if err != nil {
+			return nil, fmt.Errorf("accept: %w", err)
+		}
+		return n.register(nil, conn)
+	case "recvline":
+		conn, err := n.connection(c)
+		if err != nil {
+			return nil, err
+		}
+		line, err := conn.reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("recvline: %w", err)
+		}
+		return term.Literal(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")), nil
+	case "recveof":
+		conn, err := n.connection(c)
+		if err != nil {
+			return nil, err
+		}
+		_, err = conn.reader.Peek(1)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("recveof: %w", err)
+		}
+		return boolean(errors.Is(err, io.EOF)), nil
+	case "recv":
+		conn, err := n.connection(c)
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(conn.reader)
+		if err != nil {
+			return nil, fmt.Errorf("recv: %w", err)
+		}
+		return term.Literal(string(content)), nil
+	case "send":
+		conn, err := n.connection(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.write(conn.conn, 1)
+	case "close":
+		return n.release(c)
+	}
+	return nil, fmt.Errorf("unknown effect %q", c.name)
+}
+
+// release closes the listener or connection of the handle argument of the
+// call.
+func (n *network) release(c call) (term.Term, error) {
+	handle, err := c.handle()
+	if err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var closer io.Closer
+	if l, ok := n.listeners[handle]; ok {
+		closer = l
+		delete(n.listeners, handle)
+	} else if conn, ok := n.connections[handle]; ok {
+		closer = conn.conn
+		delete(n.connections, handle)
+	} else {
+		return nil, fmt.Errorf("close: no listener or connection %d", handle)
+	}
+	if err := closer.Close(); err != nil {
+		return nil, fmt.Errorf("close: %w", err)
+	}
+	return unit(), nil
+}
+
+// register gives a handle to a new listener or connection.
+func (n *network) register(l net.Listener, conn net.Conn) (term.Term, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		// The program was stopped while listening or dialing
+		if l != nil {
+			_ = l.Close()
+		} else {
+			_ = conn.Close()
+		}
+		return nil, net.ErrClosed
+	}
+	handle := n.next
+	n.next++
+	if l != nil {
+		n.listeners[handle] = l
+	} else {
+		n.connections[handle] = &connection{conn: conn, reader: bufio.NewReader(conn)}
+	}
+	return eval.Numeral(handle), nil
+}
+
+// listener returns the listener of the handle argument of the call.
+func (n *network) listener(c call) (net.Listener, error) {
+	handle, err := c.handle()
+	if err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	l, ok := n.listeners[handle]
+	if !ok {
+		return nil, fmt.Errorf("%s: no listener %d", c.name, handle)
+	}
+	return l, nil
+}
+
+// connection returns the connection of the handle argument of the call.
+func (n *network) connection(c call) (*connection, error) {
+	handle, err := c.handle()
+	if err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	conn, ok := n.connections[handle]
+	if !ok {
+		return nil, fmt.Errorf("%s: no connection %d", c.name, handle)
+	}
+	return conn, nil
+}
+
+// close closes every listener and connection still open, unblocking the
+// effects waiting on them. It is called when the program ends, and when its
+// context is done.
+func (n *network) close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.closed = true
+	for handle, l := range n.listeners {
+		_ = l.Close()
+		delete(n.listeners, handle)
+	}
+	for handle, conn := range n.connections {
+		_ = conn.conn.Close()
+		delete(n.connections, handle)
+	}
+}
@@ -0,0 +1,197 @@
+// Package eval implements the evaluation of λ.c programs.
+//
+// Overview:
+//
+// A parsed program is first loaded into a Program (see Load), which gathers
+// its definitions into an Environment and its bare expression statements. The
+// term to evaluate is obtained with Program.Entry, which inlines the
+// definitions it depends on. Evaluation proper is then a matter of beta
+// reduction:
+//
+//	program := eval.Load(ast)
+//	entry := program.Value().Entry("main")
+//	result := eval.NewNormalOrder().Evaluate(ctx, entry.Value())
+//
+// NormalOrder, the leftmost-outermost strategy, is the reference semantics of
+// λ.c. The other strategies implement the Evaluator interface as well, and
+// differ in where they look for redexes and in where they stop:
+//
+//   - NormalOrder reduces the leftmost-outermost redex first, to normal form.
+//   - ApplicativeOrder reduces the leftmost-innermost redex first, to normal
+//     form: arguments are normalized before being substituted.
+//   - CallByValue reduces arguments to values before substituting them, and
+//     never reduces under abstractions.
+//   - CallByName substitutes arguments unevaluated, and stops at weak head
+//     normal form: it is weak head reduction, which never reduces arguments
+//     nor under abstractions.
+//   - CallByNeed is CallByName with sharing: an argument is evaluated at most
+//     once, however many times it is used.
+//   - HeadReduction reduces the head redex only, to head normal form.
+//
+// Krivine is not a strategy but a faster backend for NormalOrder: an abstract
+// machine running terms in environments rather than substituting them, by
+// name or, with NewLazyKrivine, by need.
+//
+// A term may have a normal form under one strategy and none under another,
+// and strategies that stop early return terms that are only beta-equivalent
+// to the normal form. Evaluations are bounded by a number of reduction steps
+// and by the context they run in, and fail with ErrStepLimit or the context
+// error when either is exceeded.
+//
+// Every strategy can report each of its reduction steps to a Tracer: the
+// contracted redex, its position in the source text, the reason the strategy
+// picked it and the resulting term. TextTracer renders these events for
+// humans, JSONTracer for tools.
+//
+// Numeral and ReadNumeral convert between Go integers and Church numerals, to
+// feed programs with numbers and read their results back. Integer, Rational,
+// Real and Complex, and their Read counterparts, do the same for the numbers
+// of the standard library, writing reals and complex numbers in decimal, and
+// ReadList and ReadString for lists and strings.
+//
+// Evaluators given Primitives apply them to their arguments like functions of
+// a single step, called delta steps: applications embedding λ.c provide
+// programs with Go functions this way (see package host).
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// DefaultMaxSteps is the default bound on the number of reduction steps an
+// evaluation may take.
+const DefaultMaxSteps = 1_000_000
+
+// ErrStepLimit is returned, wrapped, when an evaluation exceeds its step
+// limit. Test for it with errors.Is.
+var ErrStepLimit = errors.New("step limit exceeded")
+
+// checkInterval is the number of steps between two checks of the context,
+// which is too expensive to check at every step.
+const checkInterval = 1024
+
+// meter counts the reduction steps of an evaluation, enforces its limits and,
+// when tracing, reports the steps to a Tracer. To report the whole term after
+// each step, the evaluation functions record the path from the root of the
+// term to the subterm they are reducing with enter and leave.
+type meter struct {
+	ctx      context.Context
+	maxSteps int
+	steps    int
+	tracer   Tracer
+	strategy string
+	decision string
+	frames   []frame
+	// primitives are applied by delta steps, see primitive
+	primitives Primitives
+}
+
+// newMeter returns a meter enforcing the given context and step limit,
+// applying the given primitives, and reporting the steps of the named
+// strategy to tracer, if not nil.
+func newMeter(
+	ctx context.Context, maxSteps int, tracer Tracer, primitives Primitives, strategy, decision string,
+) *meter {
+	return &meter{
+		ctx: ctx, maxSteps: maxSteps, tracer: tracer, primitives: primitives, strategy: strategy, decision: decision,
+	}
+}
+
+// tracing reports whether the steps are traced.
+func (m *meter) tracing() bool {
+	return m.tracer != nil
+}
+
+// enter records that the evaluation descends into the hole of f.
+func (m *meter) enter(f frame) {
+	if m.tracing() {
+		m.frames = append(m.frames, f)
+	}
+}
+
+// leave records that the evaluation returns from the last frame entered.
+func (m *meter) leave() {
+	if m.tracing() {
+		m.frames = m.frames[:len(m.frames)-1]
+	}
+}
+
+// step records the contraction of redex into contractum, and fails when a
+// limit is exceeded or the tracer fails.
+func (m *meter) step(redex, contractum term.Term) error {
+	m.steps++
+	if m.maxSteps > 0 && m.steps > m.maxSteps {
+		return fmt.Errorf("%w: gave up after %d steps", ErrStepLimit, m.maxSteps)
+	}
+	if m.steps%checkInterval == 0 {
+		if err := m.ctx.Err(); err != nil {
+			return fmt.Errorf("evaluation interrupted after %d steps: %w", m.steps, err)
+		}
+	}
+	if m.tracing() {
+		return m.tracer.Trace(Event{
+			step:       m.steps,
+			strategy:   m.strategy,
+			decision:   m.decision,
+			redex:      redex,
+			contractum: contractum,
+			frames:     append([]frame{}, m.frames...),
+		})
+	}
+	return nil
+}
+
+// Evaluator reduces terms according to an evaluation strategy. Strategies
+// that stop short of the normal form, such as CallByName, return terms that
+// may still contain redexes.
+type Evaluator interface {
+	Evaluate(ctx context.Context, t term.Term) monad.Result[term.Term, error]
+}
+
+// Strategies returns the names of the available evaluation strategies, as
+// accepted by NewEvaluator.
+func Strategies() []string {
+	return []string{"normal", "applicative", "value", "name", "need", "head", "krivine", "krivine-need"}
+}
+
+// NewEvaluator returns the evaluator implementing the named strategy, limited
+// to maxSteps reduction steps and reporting them to tracer if not nil. The
+// "krivine" and "krivine-need" backends cannot be traced.
+func NewEvaluator(strategy string, maxSteps int, tracer Tracer) monad.Result[Evaluator, error] {
+	var e Evaluator
+	switch strategy {
+	case "normal":
+		e = NewNormalOrder().WithMaxSteps(maxSteps).WithTracer(tracer)
+	case "applicative":
+		e = NewApplicativeOrder().WithMaxSteps(maxSteps).WithTracer(tracer)
+	case "value":
+		e = NewCallByValue().WithMaxSteps(maxSteps).WithTracer(tracer)
+	case "name":
+		e = NewCallByName().WithMaxSteps(maxSteps).WithTracer(tracer)
+	case "need":
+		e = NewCallByNeed().WithMaxSteps(maxSteps).WithTracer(tracer)
+	case "head":
+		e = NewHeadReduction().WithMaxSteps(maxSteps).WithTracer(tracer)
+	case "krivine", "krivine-need":
+		if tracer != nil {
+			return monad.Fail[Evaluator, error](fmt.Errorf("the %s backend cannot be traced", strategy))
+		}
+		if strategy == "krivine" {
+			e = NewKrivine().WithMaxSteps(maxSteps)
+		} else {
+			e = NewLazyKrivine().WithMaxSteps(maxSteps)
+		}
+	default:
+		return monad.Fail[Evaluator, error](fmt.Errorf(
+			"unknown evaluation strategy %q, expected one of %s", strategy, strings.Join(Strategies(), ", "),
+		))
+	}
+	return monad.Succeed[Evaluator, error](e)
+}
@@ -0,0 +1,143 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/fixpoint"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// entry parses source and returns its closed entry point.
+func entry(t testing.TB, source string) term.Term {
+	t.Helper()
+	ast := parser.ParseString(source)
+	require.True(t, ast.Success(), "%v", ast.Error())
+	program := Load(ast.Value())
+	require.True(t, program.Success(), "%v", program.Error())
+	result := program.Value().Entry("main")
+	require.True(t, result.Success(), "%v", result.Error())
+	return result.Value()
+}
+
+const church = `zero := \f.\x.x
+succ := \n.\f.\x.f (n f x)
+plus := \m.\n.\f.\x.m f (n f x)
+mult := \m.\n.\f.m (n f)
+two := succ (succ zero)
+three := succ two
+`
+
+func TestNormalOrder(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"variable", `x`, `x`},
+		{"identity", `(\x.x) y`, `y`},
+		{"SKK", `(\x.\y.\z.x z (y z)) (\x.\y.x) (\x.\y.x)`, `\z.z`},
+		{"under binder", `\y.(\x.x) y`, `\y.y`},
+		{"neutral arguments", `f ((\x.x) a) ((\x.x) b)`, `f a b`},
+		{"capture avoided", `(\x.\y.x) y`, `\y1.y`},
+		{"discarded divergence", `(\x.\y.y) ((\x.x x) (\x.x x))`, `\y.y`},
+		{"addition", church + `plus two three`, `\f.\x.f (f (f (f (f x))))`},
+		{"multiplication", church + `mult two three`, `\f.\x.f (f (f (f (f (f x)))))`},
+		{"main", church + `main := succ zero`, `\f.\x.f x`},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			result := NewNormalOrder().Evaluate(context.Background(), entry(t, testCase.input))
+			is.True(result.Success(), "%v", result.Error())
+			is.Equal(testCase.expected, result.Value().String())
+		})
+	}
+}
+
+func TestNormalOrderStepLimit(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	omega := entry(t, `(\x.x x) (\x.x x)`)
+	result := NewNormalOrder().WithMaxSteps(100).Evaluate(context.Background(), omega)
+	is.True(result.Failure())
+	is.True(errors.Is(result.Error(), ErrStepLimit))
+
+	result = NewNormalOrder().WithMaxSteps(1).Evaluate(context.Background(), entry(t, `(\x.x) y`))
+	is.True(result.Success())
+}
+
+func TestNormalOrderDeadline(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	y := entry(t, `(\f.(\x.f (x x)) (\x.f (x x))) g`)
+	result := NewNormalOrder().WithMaxSteps(0).Evaluate(ctx, y)
+	is.True(result.Failure())
+	is.True(errors.Is(result.Error(), context.DeadlineExceeded))
+}
+
+func TestFixpoint(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	source := `t := \x.\_.x
+f := \_.\y.y
+zero := \n.n (\_.f) t
+pred := \n.\f.\x.n (\g.\h.h (g f)) (\_.x) (\u.u)
+even := \n.zero n t (odd (pred n))
+odd := \n.zero n f (even (pred n))
+Y := \f.(\x.f (x x)) (\x.f (x x))
+main := even (\f.\x.f (f (f x)))`
+
+	ast := parser.ParseString(source).Value()
+	program := Load(ast).Value()
+	is.True(program.Entry("main").Failure())
+
+	resolution := resolver.Resolve(ast)
+	rewritten := fixpoint.Rewrite(ast, resolution, fixpoint.Y)
+	main := Load(rewritten).Value().Entry("main")
+	is.True(main.Success(), "%v", main.Error())
+
+	result := NewNormalOrder().Evaluate(context.Background(), main.Value())
+	is.True(result.Success(), "%v", result.Error())
+	is.Equal(`\_.\y.y`, result.Value().String())
+}
+
+func TestProgram(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	ast := parser.ParseString("io | \"std/io\"\ni := \\x.x\nk := \\x.\\_.x\ni k\nk i").Value()
+	program := Load(ast).Value()
+	is.Equal([]string{"i", "k"}, program.Environment().Names())
+	is.Len(program.Expressions(), 2)
+	is.Equal(`(\x.\_.x) (\x.x)`, program.Entry("main").Value().String())
+
+	is.True(program.Environment().Lookup("i").Just())
+	is.True(program.Environment().Lookup("main").Nothing())
+
+	env := program.Environment().Define("i", term.NewVar("j"))
+	is.Equal("j", env.Lookup("i").Value().String())
+	is.Equal(`\x.x`, program.Environment().Lookup("i").Value().String())
+
+	empty := Load(parser.ParseString("i := \\x.x").Value()).Value()
+	is.Equal(`1:0: error: no entry point: the program has no expression and no "main" definition`,
+		empty.Entry("main").Error().Error())
+}
@@ -0,0 +1,352 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Krivine evaluates terms to normal form on a Krivine abstract machine, an
+// alternative backend to the substitution of NormalOrder that returns the
+// same normal forms, up to the names of the parameters, much faster.
+//
+// The machine runs terms compiled to de Bruijn indices in environments
+// binding the indices to closures, so that a beta step pushes a binding
+// instead of copying the argument into the body of the abstraction:
+//
+//   - an application pushes its argument, closed by the environment, on the
+//     stack and carries on with its function;
+//   - an abstraction pops an argument and binds it, which is the beta step;
+//   - a variable enters the closure it is bound to.
+//
+// The machine stops at weak head normal form, when an abstraction finds the
+// stack empty or the head is a free variable. Strong normal forms are then
+// read back by running the machine under abstractions, their parameters
+// bound to fresh variables, and on the arguments of the free variables.
+//
+// The machine evaluates by name: an argument used twice is evaluated twice.
+// NewLazyKrivine returns the call-by-need variant, which updates the closures
+// with their weak head normal form once entered, leaving an update marker on
+// the stack until it is reached.
+//
+// Krivine machines cannot trace their steps, nor apply primitives: free
+// variables stay free, and evaluating a term whose free variables include
+// the primitives given to WithPrimitives fails rather than leaving them
+// unapplied. Since it never builds the intermediate terms, steps count beta
+// steps alone.
+type Krivine struct {
+	maxSteps   int
+	sharing    bool
+	primitives Primitives
+}
+
+// NewKrivine returns a call-by-name Krivine machine limited to
+// DefaultMaxSteps beta steps.
+func NewKrivine() Krivine {
+	return Krivine{maxSteps: DefaultMaxSteps}
+}
+
+// NewLazyKrivine returns a call-by-need Krivine machine, which evaluates every
+// argument at most once, limited to DefaultMaxSteps beta steps.
+func NewLazyKrivine() Krivine {
+	return Krivine{maxSteps: DefaultMaxSteps, sharing: true}
+}
+
+// WithMaxSteps returns a copy of the machine limited to n beta steps, or
+// unlimited if n is zero or less.
+func (k Krivine) WithMaxSteps(n int) Krivine {
+	k.maxSteps = n
+	return k
+}
+
+// WithPrimitives returns a copy of the machine refusing to evaluate terms
+// that refer to the given primitives, which it cannot apply.
+func (k Krivine) WithPrimitives(primitives Primitives) Krivine {
+	k.primitives = primitives
+	return k
+}
+
+// Evaluate reduces t to normal form.
+func (k Krivine) Evaluate(ctx context.Context, t term.Term) monad.Result[term.Term, error] {
+	strategy := "krivine"
+	if k.sharing {
+		strategy = "krivine-need"
+	}
+	free := term.FreeVars(t)
+	var applied []string
+	for name := range free {
+		if _, ok := k.primitives[name]; ok {
+			applied = append(applied, name)
+		}
+	}
+	if len(applied) > 0 {
+		sort.Strings(applied)
+		return monad.Fail[term.Term, error](fmt.Errorf(
+			"the %s backend cannot apply primitives: %s", strategy, strings.Join(applied, ", "),
+		))
+	}
+	machine := &krivineMachine{
+		meter:   newMeter(ctx, k.maxSteps, nil, nil, strategy, "head redex, argument closed by its environment"),
+		sharing: k.sharing,
+		free:    free,
+	}
+	value, err := machine.run(compileKrivine(t, nil), nil)
+	if err != nil {
+		return monad.Fail[term.Term, error](err)
+	}
+	result, err := machine.readback(value, nil)
+	if err != nil {
+		return monad.Fail[term.Term, error](err)
+	}
+	return monad.Succeed[term.Term, error](result)
+}
+
+// kCode is the code the machine runs: a term whose bound variables are de
+// Bruijn indices, 0 standing for the parameter of the innermost enclosing
+// abstraction. Abstractions keep the name of their parameter, to name the
+// parameters of the normal form after it.
+type kCode interface {
+	kCode()
+}
+
+type (
+	kIndex int
+	kFree  string
+	kLam   struct {
+		param string
+		body  kCode
+	}
+	kApp struct {
+		fun, arg kCode
+	}
+)
+
+func (kIndex) kCode() {}
+func (kFree) kCode()  {}
+func (*kLam) kCode()  {}
+func (*kApp) kCode()  {}
+
+// compileKrivine compiles t, under the abstractions binding params, innermost
+// first.
+func compileKrivine(t term.Term, params []string) kCode {
+	switch t := t.(type) {
+	case term.Var:
+		for i, param := range params {
+			if param == t.Name() {
+				return kIndex(i)
+			}
+		}
+		return kFree(t.Name())
+	case term.Abs:
+		return &kLam{param: t.Param(), body: compileKrivine(t.Body(), append([]string{t.Param()}, params...))}
+	case term.App:
+		return &kApp{fun: compileKrivine(t.Fun(), params), arg: compileKrivine(t.Arg(), params)}
+	}
+	return kFree(t.String())
+}
+
+// kEnv is an environment, as a linked list of closures: index 0 is bound to
+// the first one.
+type kEnv struct {
+	closure *kClosure
+	next    *kEnv
+}
+
+// lookup returns the closure index i is bound to in e.
+func (e *kEnv) lookup(i kIndex) *kClosure {
+	for ; i > 0; i-- {
+		e = e.next
+	}
+	return e.closure
+}
+
+// kClosure is code along with the environment binding its indices. Once
+// evaluated by a call-by-need machine, or when standing for a variable, it
+// holds its weak head normal form.
+type kClosure struct {
+	code  kCode
+	env   *kEnv
+	value *kValue
+}
+
+// kValue is a weak head normal form: an abstraction closed by its
+// environment, or a neutral term, the application of a variable to
+// arguments. The variable is free, or the parameter of the abstraction at
+// the given level of the readback, counting from the outermost.
+type kValue struct {
+	lam   *kLam
+	env   *kEnv
+	free  string
+	level int
+	args  []*kClosure
+}
+
+// kFrame is an entry of the stack: an argument, or the closure to update
+// with the weak head normal form of its code once reached.
+type kFrame struct {
+	arg    *kClosure
+	update *kClosure
+}
+
+// krivineMachine holds the state of an evaluation.
+type krivineMachine struct {
+	*meter
+	sharing bool
+	// free holds the free variables of the term, which the parameters of the
+	// normal form must not capture
+	free map[string]bool
+	// entered counts the closures entered, which are not beta steps
+	entered int
+}
+
+// run runs code in env to weak head normal form.
+func (k *krivineMachine) run(code kCode, env *kEnv) (kValue, error) {
+	var stack []kFrame
+	for {
+		switch c := code.(type) {
+		case *kApp:
+			// A variable argument is already bound to a closure: pushing it
+			// again rather than wrapping it keeps the closures a single
+			// indirection away from their code
+			if index, ok := c.arg.(kIndex); ok {
+				stack = append(stack, kFrame{arg: env.lookup(index)})
+			} else {
+				stack = append(stack, kFrame{arg: &kClosure{code: c.arg, env: env}})
+			}
+			code = c.fun
+		case *kLam:
+			n := len(stack)
+			if n == 0 {
+				return kValue{lam: c, env: env}, nil
+			}
+			if update := stack[n-1].update; update != nil {
+				update.value = &kValue{lam: c, env: env}
+				stack = stack[:n-1]
+				continue
+			}
+			if err := k.step(nil, nil); err != nil {
+				return kValue{}, err
+			}
+			env = &kEnv{closure: stack[n-1].arg, next: env}
+			stack = stack[:n-1]
+			code = c.body
+		case kIndex:
+			bound := env.lookup(c)
+			if bound.value == nil {
+				if err := k.enter(); err != nil {
+					return kValue{}, err
+				}
+				if k.sharing {
+					stack = append(stack, kFrame{update: bound})
+				}
+				code, env = bound.code, bound.env
+				continue
+			}
+			if bound.value.lam == nil {
+				return k.neutral(*bound.value, stack), nil
+			}
+			code, env = bound.value.lam, bound.value.env
+		case kFree:
+			return k.neutral(kValue{free: string(c), level: -1}, stack), nil
+		}
+	}
+}
+
+// enter records that the machine enters a closure, and fails once in a while
+// if the evaluation has been canceled, so that no loop of the machine runs
+// without checking the context.
+func (k *krivineMachine) enter() error {
+	k.entered++
+	if k.entered%checkInterval == 0 {
+		if err := k.ctx.Err(); err != nil {
+			return fmt.Errorf("evaluation interrupted after %d steps: %w", k.steps, err)
+		}
+	}
+	return nil
+}
+
+// neutral applies the neutral value v to the arguments on the stack, updating
+// the closures marked on the way with the partial applications.
+func (k *krivineMachine) neutral(v kValue, stack []kFrame) kValue {
+	args := v.args
+	for i := len(stack) - 1; i >= 0; i-- {
+		if update := stack[i].update; update != nil {
+			update.value = &kValue{free: v.free, level: v.level, args: args[:len(args):len(args)]}
+			continue
+		}
+		args = append(args[:len(args):len(args)], stack[i].arg)
+	}
+	v.args = args
+	return v
+}
+
+// force returns the weak head normal form of c, updating c with it when
+// sharing.
+func (k *krivineMachine) force(c *kClosure) (kValue, error) {
+	if c.value != nil {
+		return *c.value, nil
+	}
+	value, err := k.run(c.code, c.env)
+	if err != nil {
+		return value, err
+	}
+	if k.sharing {
+		c.value = &value
+	}
+	return value, nil
+}
+
+// readback returns the normal form of v, under abstractions whose parameters
+// are named params, outermost first.
+func (k *krivineMachine) readback(v kValue, params []string) (term.Term, error) {
+	if v.lam != nil {
+		param := term.Fresh(v.lam.param, k.taken(params))
+		variable := &kClosure{value: &kValue{level: len(params)}}
+		body, err := k.run(v.lam.body, &kEnv{closure: variable, next: v.env})
+		if err != nil {
+			return nil, err
+		}
+		t, err := k.readback(body, append(params[:len(params):len(params)], param))
+		if err != nil {
+			return nil, err
+		}
+		return term.NewAbs(param, t), nil
+	}
+
+	var t term.Term
+	if v.level < 0 {
+		t = term.NewVar(v.free)
+	} else {
+		t = term.NewVar(params[v.level])
+	}
+	for _, arg := range v.args {
+		value, err := k.force(arg)
+		if err != nil {
+			return nil, err
+		}
+		a, err := k.readback(value, params)
+		if err != nil {
+			return nil, err
+		}
+		t = term.NewApp(t, a)
+	}
+	return t, nil
+}
+
+// taken returns the names a new parameter must avoid: the free variables of
+// the term, and the parameters in scope.
+func (k *krivineMachine) taken(params []string) map[string]bool {
+	taken := make(map[string]bool, len(k.free)+len(params))
+	for name := range k.free {
+		taken[name] = true
+	}
+	for _, param := range params {
+		taken[param] = true
+	}
+	return taken
+}
@@ -0,0 +1,185 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/term"
+	"github.com/denisdubochevalier/lambdac/term/termtest"
+)
+
+// factorial computes the factorial of a Church numeral by general recursion.
+const factorial = church + `true := \x.\y.x
+false := \x.\y.y
+iszero := \n.n (\_.false) true
+pred := \n.\f.\x.n (\g.\h.h (g f)) (\_.x) (\u.u)
+Y := \f.(\x.f (x x)) (\x.f (x x))
+fact := Y (\fact.\n.iszero n (succ zero) (mult n (fact (pred n))))
+`
+
+func TestKrivine(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"variable", `x`, `x`},
+		{"identity", `(\x.x) y`, `y`},
+		{"SKK", `(\x.\y.\z.x z (y z)) (\x.\y.x) (\x.\y.x)`, `\z.z`},
+		{"under binder", `\y.(\x.x) y`, `\y.y`},
+		{"neutral arguments", `f ((\x.x) a) ((\x.x) b)`, `f a b`},
+		{"capture avoided", `(\x.\y.x) y`, `\y1.y`},
+		{"shadowing", `(\x.\x.x) a`, `\x.x`},
+		{"inner capture avoided", `(\x.\y.\z.x y) y w`, `\z.y w`},
+		{"discarded divergence", `(\x.\y.y) ((\x.x x) (\x.x x))`, `\y.y`},
+		{"duplicated argument", `(\x.\y.x x) ((\z.z) w)`, `\y.w w`},
+		{"multiplication", church + `mult two three`, `\f.\x.f (f (f (f (f (f x)))))`},
+		{"factorial", factorial + `fact three`, `\f.\x.f (f (f (f (f (f x)))))`},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			input := entry(t, testCase.input)
+			for _, machine := range []Krivine{NewKrivine(), NewLazyKrivine()} {
+				result := machine.Evaluate(context.Background(), input)
+				is.True(result.Success(), "%v", result.Error())
+				is.Equal(testCase.expected, result.Value().String())
+			}
+		})
+	}
+}
+
+func TestKrivineLimits(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	omega := entry(t, `(\x.x x) (\x.x x)`)
+	for _, machine := range []Krivine{NewKrivine(), NewLazyKrivine()} {
+		result := machine.WithMaxSteps(100).Evaluate(context.Background(), omega)
+		is.True(errors.Is(result.Error(), ErrStepLimit))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		result = machine.WithMaxSteps(0).Evaluate(ctx, omega)
+		is.True(errors.Is(result.Error(), context.Canceled))
+	}
+
+	// Giving up within the default limit, each self-application adding no
+	// indirection to the closures
+	for _, machine := range []Krivine{NewKrivine(), NewLazyKrivine()} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		result := machine.Evaluate(ctx, omega)
+		cancel()
+		is.True(errors.Is(result.Error(), ErrStepLimit), "%v", result.Error())
+	}
+
+	// Diverging under an abstraction, while reading back
+	result := NewKrivine().WithMaxSteps(100).Evaluate(context.Background(), entry(t, `\y.(\x.x x) (\x.x x)`))
+	is.True(errors.Is(result.Error(), ErrStepLimit))
+}
+
+// constant is a primitive of a single argument returning the variable c.
+type constant struct{}
+
+func (constant) Arity() int      { return 1 }
+func (constant) Strict(int) bool { return false }
+func (constant) Apply(context.Context, []term.Term) (term.Term, error) {
+	return term.NewVar("c"), nil
+}
+
+func TestKrivinePrimitives(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	primitives := Primitives{"host/k->const": constant{}, "host/k->other": constant{}}
+	input := term.NewApp(term.NewVar("host/k->const"), term.NewVar("x"))
+	is.Equal("c", NewNormalOrder().WithPrimitives(primitives).Evaluate(context.Background(), input).Value().String())
+
+	for _, machine := range []Krivine{NewKrivine(), NewLazyKrivine()} {
+		result := machine.WithPrimitives(primitives).Evaluate(context.Background(), input)
+		is.True(result.Failure())
+		is.Contains(result.Error().Error(), "backend cannot apply primitives: host/k->const")
+
+		// Free variables that are not primitives stay free
+		result = machine.WithPrimitives(primitives).Evaluate(context.Background(), term.NewVar("x"))
+		is.True(result.Success(), "%v", result.Error())
+	}
+}
+
+func TestLazyKrivineSharing(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	// The argument takes two steps to evaluate, and is used three times
+	input := entry(t, `(\x.x (x (x w))) ((\y.\z.y) (\v.v) u)`)
+	byNeed := NewLazyKrivine().WithMaxSteps(6).Evaluate(context.Background(), input)
+	is.True(byNeed.Success(), "%v", byNeed.Error())
+	is.Equal(`w`, byNeed.Value().String())
+
+	byName := NewKrivine().WithMaxSteps(6).Evaluate(context.Background(), input)
+	is.True(errors.Is(byName.Error(), ErrStepLimit))
+}
+
+func TestKrivineProperties(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 1000
+	properties := gopter.NewProperties(parameters)
+
+	normal := NewNormalOrder().WithMaxSteps(1000)
+	agrees := func(machine Krivine) func(term.Term) bool {
+		return func(t term.Term) bool {
+			expected := normal.Evaluate(context.Background(), t)
+			if expected.Failure() {
+				return true
+			}
+			result := machine.Evaluate(context.Background(), t)
+			return result.Success() && term.AlphaEquivalent(expected.Value(), result.Value())
+		}
+	}
+
+	properties.Property("the Krivine machine agrees with normal order", prop.ForAll(
+		agrees(NewKrivine().WithMaxSteps(100_000)), termtest.Term(6),
+	))
+	properties.Property("the lazy Krivine machine agrees with normal order", prop.ForAll(
+		agrees(NewLazyKrivine().WithMaxSteps(100_000)), termtest.Term(6),
+	))
+
+	properties.TestingRun(t)
+}
+
+// BenchmarkFactorial compares the reference evaluator with the Krivine
+// machines on the factorial of 4.
+func BenchmarkFactorial(b *testing.B) {
+	evaluators := []struct {
+		name      string
+		evaluator Evaluator
+	}{
+		{"normal", NewNormalOrder().WithMaxSteps(0)},
+		{"krivine", NewKrivine().WithMaxSteps(0)},
+		{"krivine-need", NewLazyKrivine().WithMaxSteps(0)},
+	}
+	input := entry(b, factorial+`main := fact (succ three)`)
+
+	for _, e := range evaluators {
+		e := e
+		b.Run(e.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if result := e.evaluator.Evaluate(context.Background(), input); result.Failure() {
+					b.Fatal(result.Error())
+				}
+			}
+		})
+	}
+}
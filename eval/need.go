@@ -0,0 +1,198 @@
+package eval
+
+import (
+	"context"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// CallByNeed evaluates terms the way lazy languages do: like CallByName,
+// arguments are only evaluated when they reach the head position, and
+// evaluation stops at weak head normal form, but every argument is evaluated at
+// most once, however many times it is used.
+//
+// Sharing cannot be expressed by substitution, which copies the argument at
+// every occurrence. Instead, terms are evaluated in environments binding
+// variables to thunks, which are updated with their value once forced. The
+// result is read back into a term by substituting the thunks it refers to,
+// evaluated or not.
+type CallByNeed struct {
+	maxSteps   int
+	tracer     Tracer
+	primitives Primitives
+}
+
+// NewCallByNeed returns a CallByNeed evaluator limited to DefaultMaxSteps
+// reduction steps.
+func NewCallByNeed() CallByNeed {
+	return CallByNeed{maxSteps: DefaultMaxSteps}
+}
+
+// WithMaxSteps returns a copy of the evaluator limited to n reduction steps, or
+// unlimited if n is zero or less.
+func (e CallByNeed) WithMaxSteps(n int) CallByNeed {
+	e.maxSteps = n
+	return e
+}
+
+// WithTracer returns a copy of the evaluator reporting every reduction step to
+// tracer.
+func (e CallByNeed) WithTracer(tracer Tracer) CallByNeed {
+	e.tracer = tracer
+	return e
+}
+
+// WithPrimitives returns a copy of the evaluator applying the given
+// primitives.
+func (e CallByNeed) WithPrimitives(primitives Primitives) CallByNeed {
+	e.primitives = primitives
+	return e
+}
+
+// Evaluate reduces t to weak head normal form.
+func (e CallByNeed) Evaluate(ctx context.Context, t term.Term) monad.Result[term.Term, error] {
+	m := newMeter(ctx, e.maxSteps, e.tracer, e.primitives,
+		"need", "head redex, argument shared unevaluated")
+	c, err := byNeed(m, closure{term: t})
+	if err != nil {
+		return monad.Fail[term.Term, error](err)
+	}
+	return monad.Succeed[term.Term, error](readback(c.term, c.env, nil))
+}
+
+// closure is a term along with the environment binding its free variables.
+type closure struct {
+	term term.Term
+	env  *env
+}
+
+// env is an environment, as a linked list of bindings. Inner bindings come
+// first and shadow the outer ones.
+type env struct {
+	name  string
+	thunk *thunk
+	next  *env
+}
+
+// lookup returns the thunk bound to name, or nil if name is free.
+func (e *env) lookup(name string) *thunk {
+	for ; e != nil; e = e.next {
+		if e.name == name {
+			return e.thunk
+		}
+	}
+	return nil
+}
+
+// thunk is a suspended argument, updated with its weak head normal form once
+// forced.
+type thunk struct {
+	suspended closure
+	value     *closure
+	term      term.Term
+}
+
+// force evaluates the thunk, unless it already was.
+func (t *thunk) force(m *meter) (closure, error) {
+	if t.value != nil {
+		return *t.value, nil
+	}
+	value, err := byNeed(m, t.suspended)
+	if err != nil {
+		return value, err
+	}
+	t.value = &value
+	t.term = nil
+	return value, nil
+}
+
+// readback returns the term the thunk stands for, memoized until the thunk is
+// forced.
+func (t *thunk) readback() term.Term {
+	if t.term == nil {
+		c := t.suspended
+		if t.value != nil {
+			c = *t.value
+		}
+		t.term = readback(c.term, c.env, nil)
+	}
+	return t.term
+}
+
+// byNeed reduces c to weak head normal form.
+func byNeed(m *meter, c closure) (closure, error) {
+	switch t := c.term.(type) {
+	case term.Var:
+		if th := c.env.lookup(t.Name()); th != nil {
+			return th.force(m)
+		}
+		return needDelta(m, t)
+	case term.App:
+		fun, err := byNeed(m, closure{term: t.Fun(), env: c.env})
+		if err != nil {
+			return c, err
+		}
+		arg := &thunk{suspended: closure{term: t.Arg(), env: c.env}}
+		if abs, ok := fun.term.(term.Abs); ok {
+			body := closure{term: abs.Body(), env: &env{name: abs.Param(), thunk: arg, next: fun.env}}
+			var redex, contractum term.Term
+			if m.tracing() {
+				redex = term.NewApp(readback(abs, fun.env, nil), arg.readback()).WithPosition(t.Position())
+				contractum = readback(body.term, body.env, nil)
+			}
+			if err := m.step(redex, contractum); err != nil {
+				return c, err
+			}
+			return byNeed(m, body)
+		}
+		neutral := term.NewApp(readback(fun.term, fun.env, nil), arg.readback()).WithPosition(t.Position())
+		return needDelta(m, neutral)
+	}
+	return c, nil
+}
+
+// needDelta applies the primitive t is an application of, if any, and
+// evaluates the result. Arguments are read back, which loses their sharing.
+func needDelta(m *meter, t term.Term) (closure, error) {
+	r, applied, err := m.primitive(t)
+	if err != nil || !applied {
+		return closure{term: r}, err
+	}
+	return byNeed(m, closure{term: r})
+}
+
+// readback substitutes, simultaneously and avoiding captures, the thunks of e
+// for the variables they are bound to in t. Binders of t met along the way are
+// recorded in local, which maps them to their possibly renamed variable and
+// takes precedence over e.
+func readback(t term.Term, e *env, local map[string]term.Var) term.Term {
+	switch t := t.(type) {
+	case term.Var:
+		if replacement, ok := local[t.Name()]; ok {
+			return replacement.WithPosition(t.Position())
+		}
+		if th := e.lookup(t.Name()); th != nil {
+			return th.readback()
+		}
+		return t
+	case term.App:
+		return term.NewApp(readback(t.Fun(), e, local), readback(t.Arg(), e, local)).WithPosition(t.Position())
+	case term.Abs:
+		avoid := map[string]bool{}
+		for name := range term.FreeVars(t) {
+			for free := range term.FreeVars(readback(term.NewVar(name), e, local)) {
+				avoid[free] = true
+			}
+		}
+		param := term.Fresh(t.Param(), avoid)
+		inner := make(map[string]term.Var, len(local)+1)
+		for name, replacement := range local {
+			inner[name] = replacement
+		}
+		inner[t.Param()] = term.NewVar(param)
+		return term.NewAbs(param, readback(t.Body(), e, inner)).WithPosition(t.Position())
+	}
+	return t
+}
@@ -0,0 +1,143 @@
+package eval
+
+import (
+	"context"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// NormalOrder evaluates terms by repeatedly contracting the leftmost-outermost
+// redex, until the term reaches its beta normal form. By the standardization
+// theorem, this strategy finds the normal form of every term that has one,
+// which makes it the reference semantics of λ.c: every other strategy and
+// backend is tested against it.
+//
+// Terms without a normal form, such as applications of the Y combinator that
+// never stop unfolding, would make the evaluation run forever. NormalOrder
+// therefore gives up after a configurable number of steps, and whenever the
+// context passed to Evaluate is done.
+type NormalOrder struct {
+	maxSteps   int
+	tracer     Tracer
+	primitives Primitives
+}
+
+// NewNormalOrder returns a NormalOrder evaluator limited to DefaultMaxSteps
+// reduction steps.
+func NewNormalOrder() NormalOrder {
+	return NormalOrder{maxSteps: DefaultMaxSteps}
+}
+
+// WithMaxSteps returns a copy of the evaluator limited to n reduction steps.
+// A limit of zero or less disables the limit, in which case only the context
+// can stop a diverging evaluation.
+func (e NormalOrder) WithMaxSteps(n int) NormalOrder {
+	e.maxSteps = n
+	return e
+}
+
+// WithTracer returns a copy of the evaluator reporting every reduction step to
+// tracer.
+func (e NormalOrder) WithTracer(tracer Tracer) NormalOrder {
+	e.tracer = tracer
+	return e
+}
+
+// WithPrimitives returns a copy of the evaluator applying the given
+// primitives.
+func (e NormalOrder) WithPrimitives(primitives Primitives) NormalOrder {
+	e.primitives = primitives
+	return e
+}
+
+// Evaluate reduces t to its beta normal form.
+func (e NormalOrder) Evaluate(ctx context.Context, t term.Term) monad.Result[term.Term, error] {
+	m := newMeter(ctx, e.maxSteps, e.tracer, e.primitives, "normal", "leftmost-outermost redex")
+	return monad.FromTuple(normalize(m, t))
+}
+
+// normalize reduces t to normal form in normal order: the head of the term is
+// reduced first, then the body of the resulting abstraction, or the arguments
+// of the resulting variable application from left to right.
+func normalize(m *meter, t term.Term) (term.Term, error) {
+	t, err := whnf(m, t)
+	if err != nil {
+		return t, err
+	}
+
+	steps := m.steps
+	switch t := t.(type) {
+	case term.Abs:
+		m.enter(inBody(t))
+		body, err := normalize(m, t.Body())
+		m.leave()
+		if err != nil {
+			return t, err
+		}
+		return rebuildAbs(t, body, m.steps != steps), nil
+	case term.App:
+		m.enter(inFun(t, t.Arg()))
+		fun, err := normalize(m, t.Fun())
+		m.leave()
+		if err != nil {
+			return t, err
+		}
+		m.enter(inArg(t, fun))
+		arg, err := normalize(m, t.Arg())
+		m.leave()
+		if err != nil {
+			return t, err
+		}
+		return rebuildApp(t, fun, arg, m.steps != steps), nil
+	}
+	return t, nil
+}
+
+// whnf reduces t to weak head normal form by contracting head redexes only,
+// and applying primitives: the result is an abstraction, or a variable
+// applied to arguments.
+func whnf(m *meter, t term.Term) (term.Term, error) {
+	app, ok := t.(term.App)
+	if !ok {
+		return m.delta(t, whnf)
+	}
+
+	steps := m.steps
+	m.enter(inFun(app, app.Arg()))
+	fun, err := whnf(m, app.Fun())
+	m.leave()
+	if err != nil {
+		return t, err
+	}
+	rebuilt := rebuildApp(app, fun, app.Arg(), m.steps != steps)
+	if abs, ok := fun.(term.Abs); ok {
+		contractum := term.Subst(abs.Body(), abs.Param(), app.Arg())
+		if err := m.step(rebuilt, contractum); err != nil {
+			return t, err
+		}
+		return whnf(m, contractum)
+	}
+	return m.delta(rebuilt, whnf)
+}
+
+// rebuildAbs returns a with the given body, keeping a as is when the body has
+// not changed, so that unchanged subterms stay shared. Whether it changed is
+// told by the caller, which knows whether reducing it took any step: comparing
+// the terms would walk them whole.
+func rebuildAbs(a term.Abs, body term.Term, changed bool) term.Term {
+	if !changed {
+		return a
+	}
+	return term.NewAbs(a.Param(), body).WithPosition(a.Position())
+}
+
+// rebuildApp returns a with the given function and argument, keeping a as is
+// when neither changed.
+func rebuildApp(a term.App, fun, arg term.Term, changed bool) term.Term {
+	if !changed {
+		return a
+	}
+	return term.NewApp(fun, arg).WithPosition(a.Position())
+}
@@ -0,0 +1,164 @@
+package eval
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// The functions below encode Go numbers as the terms of the standard library
+// modules std/int, std/rat, std/real and std/complex, and decode the normal
+// forms of such terms. Decoding accepts any representation of a number, such
+// as the unnormalized integer (5, 2) for 3.
+
+// pair returns the Church pair of a and b, \s.s a b, which must be closed.
+func pair(a, b term.Term) term.Term {
+	return term.NewAbs("s", term.Apply(term.NewVar("s"), a, b))
+}
+
+// readPair decodes a Church pair in normal form.
+func readPair(t term.Term) (term.Term, term.Term, bool) {
+	abs, ok := t.(term.Abs)
+	if !ok {
+		return nil, nil, false
+	}
+	outer, ok := abs.Body().(term.App)
+	if !ok {
+		return nil, nil, false
+	}
+	inner, ok := outer.Fun().(term.App)
+	if !ok {
+		return nil, nil, false
+	}
+	if selector, ok := inner.Fun().(term.Var); !ok || selector.Name() != abs.Param() {
+		return nil, nil, false
+	}
+	a, b := inner.Arg(), outer.Arg()
+	if term.IsFree(abs.Param(), a) || term.IsFree(abs.Param(), b) {
+		return nil, nil, false
+	}
+	return a, b, true
+}
+
+// Integer returns the std/int integer n.
+func Integer(n int) term.Term {
+	if n < 0 {
+		return pair(Numeral(0), Numeral(-n))
+	}
+	return pair(Numeral(n), Numeral(0))
+}
+
+// ReadInteger decodes a std/int integer in normal form.
+func ReadInteger(t term.Term) monad.Maybe[int] {
+	m, n, ok := readPair(t)
+	if !ok {
+		return monad.None[int]()
+	}
+	positive, negative := ReadNumeral(m), ReadNumeral(n)
+	if positive.Nothing() || negative.Nothing() {
+		return monad.None[int]()
+	}
+	return monad.Some(positive.Value() - negative.Value())
+}
+
+// Rational returns the std/rat rational r. Its numerator and denominator must
+// fit in an int.
+func Rational(r *big.Rat) term.Term {
+	return pair(Integer(int(r.Num().Int64())), Numeral(int(r.Denom().Int64())))
+}
+
+// ReadRational decodes a std/rat rational in normal form. Rationals whose
+// denominator is zero give None.
+func ReadRational(t term.Term) monad.Maybe[*big.Rat] {
+	i, d, ok := readPair(t)
+	if !ok {
+		return monad.None[*big.Rat]()
+	}
+	num, den := ReadInteger(i), ReadNumeral(d)
+	if num.Nothing() || den.Nothing() || den.Value() == 0 {
+		return monad.None[*big.Rat]()
+	}
+	return monad.Some(big.NewRat(int64(num.Value()), int64(den.Value())))
+}
+
+// Real returns the std/real real written in decimal, such as "-3.14", keeping
+// as many decimals as written.
+func Real(decimal string) monad.Result[term.Term, error] {
+	digits, negative := strings.CutPrefix(decimal, "-")
+	whole, fraction, _ := strings.Cut(digits, ".")
+	mantissa := 0
+	for _, c := range whole + fraction {
+		if c < '0' || c > '9' {
+			return monad.Fail[term.Term, error](fmt.Errorf("invalid decimal number %q", decimal))
+		}
+		mantissa = mantissa*10 + int(c-'0')
+	}
+	if whole == "" && fraction == "" {
+		return monad.Fail[term.Term, error](fmt.Errorf("invalid decimal number %q", decimal))
+	}
+	if negative {
+		mantissa = -mantissa
+	}
+	return monad.Succeed[term.Term, error](pair(Integer(mantissa), Numeral(len(fraction))))
+}
+
+// ReadReal decodes a std/real real in normal form, and writes it in decimal
+// with as many decimals as its exponent, such as "3.140".
+func ReadReal(t term.Term) monad.Maybe[string] {
+	m, e, ok := readPair(t)
+	if !ok {
+		return monad.None[string]()
+	}
+	mantissa, exponent := ReadInteger(m), ReadNumeral(e)
+	if mantissa.Nothing() || exponent.Nothing() {
+		return monad.None[string]()
+	}
+
+	sign, digits := "", fmt.Sprint(mantissa.Value())
+	if mantissa.Value() < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	decimals := exponent.Value()
+	if decimals == 0 {
+		return monad.Some(sign + digits)
+	}
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	return monad.Some(sign + digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:])
+}
+
+// Complex returns the std/complex number of the given real and imaginary
+// parts, written in decimal.
+func Complex(re, im string) monad.Result[term.Term, error] {
+	x := Real(re)
+	if x.Failure() {
+		return x
+	}
+	y := Real(im)
+	if y.Failure() {
+		return y
+	}
+	return monad.Succeed[term.Term, error](pair(x.Value(), y.Value()))
+}
+
+// ReadComplex decodes a std/complex number in normal form, and writes it as
+// its real and imaginary parts in decimal, such as "1.5-2i".
+func ReadComplex(t term.Term) monad.Maybe[string] {
+	x, y, ok := readPair(t)
+	if !ok {
+		return monad.None[string]()
+	}
+	re, im := ReadReal(x), ReadReal(y)
+	if re.Nothing() || im.Nothing() {
+		return monad.None[string]()
+	}
+	if strings.HasPrefix(im.Value(), "-") {
+		return monad.Some(re.Value() + im.Value() + "i")
+	}
+	return monad.Some(re.Value() + "+" + im.Value() + "i")
+}
@@ -0,0 +1,111 @@
+package eval
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// expression parses a single expression.
+func expression(t *testing.T, source string) term.Term {
+	t.Helper()
+	program := Load(parser.ParseString(source).Value())
+	require.True(t, program.Success(), "%v", program.Error())
+	return program.Value().Expressions()[0]
+}
+
+func TestReadNumbers(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	three, two := `(\f.\x.f (f (f x)))`, `(\f.\x.f (f x))`
+	is.Equal(1, ReadInteger(expression(t, `\p.p `+three+` `+two)).Value())
+	is.Equal(-1, ReadInteger(expression(t, `\p.p `+two+` `+three)).Value())
+	is.Equal(big.NewRat(1, 2), ReadRational(expression(t, `\p.p (\q.q `+three+` `+two+`) `+two)).Value())
+	is.Equal("0.01", ReadReal(expression(t, `\p.p (\q.q `+three+` `+two+`) `+two)).Value())
+
+	for _, source := range []string{
+		`\p.p`,
+		`\p.p ` + three,
+		`\p.q ` + three + ` ` + two,
+		`\p.p ` + three + ` p`,
+		`\p.p ` + three + ` x`,
+	} {
+		is.True(ReadInteger(expression(t, source)).Nothing(), source)
+	}
+	is.True(ReadRational(expression(t, `\p.p (\q.q `+three+` `+two+`) (\f.\x.x)`)).Nothing())
+	is.True(ReadComplex(expression(t, `\p.p `+three+` `+two)).Nothing())
+}
+
+func TestReal(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		decimal  string
+		expected string
+	}{
+		{"0", "0"},
+		{"42", "42"},
+		{"3.14", "3.14"},
+		{"-0.05", "-0.05"},
+		{".5", "0.5"},
+		{"-1.", "-1"},
+		{"2.500", "2.500"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.decimal, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			encoded := Real(testCase.decimal)
+			is.True(encoded.Success(), "%v", encoded.Error())
+			is.Equal(testCase.expected, ReadReal(encoded.Value()).Value())
+		})
+	}
+
+	for _, invalid := range []string{"", "-", ".", "1.2.3", "1e3", "--1"} {
+		require.Error(t, Real(invalid).Error(), invalid)
+	}
+}
+
+func TestComplex(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.Equal("1.5+2i", ReadComplex(Complex("1.5", "2").Value()).Value())
+	is.Equal("0-0.25i", ReadComplex(Complex("0", "-0.25").Value()).Value())
+	is.Error(Complex("x", "1").Error())
+	is.Error(Complex("1", "y").Error())
+}
+
+func TestNumberProperties(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 200
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("ReadInteger decodes Integer", prop.ForAll(
+		func(n int) bool {
+			return ReadInteger(Integer(n)).Value() == n
+		},
+		gen.IntRange(-500, 500),
+	))
+
+	properties.Property("ReadRational decodes Rational", prop.ForAll(
+		func(num, den int) bool {
+			r := big.NewRat(int64(num), int64(den))
+			return ReadRational(Rational(r)).Value().Cmp(r) == 0
+		},
+		gen.IntRange(-100, 100), gen.IntRange(1, 100),
+	))
+
+	properties.TestingRun(t)
+}
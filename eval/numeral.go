@@ -0,0 +1,60 @@
+package eval
+
+import (
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Numeral returns the Church numeral of n, which applies a function n times:
+// \f.\x.f (f (... (f x))). It panics if n is negative.
+func Numeral(n int) term.Term {
+	if n < 0 {
+		panic("eval: negative Church numeral")
+	}
+	var body term.Term = term.NewVar("x")
+	for i := 0; i < n; i++ {
+		body = term.NewApp(term.NewVar("f"), body)
+	}
+	return term.NewAbs("f", term.NewAbs("x", body))
+}
+
+// ReadNumeral decodes a term in normal form as a Church numeral, whatever the
+// names of its parameters: \s.\z.s (s z) is 2. The eta-reduced form of one,
+// \f.f, is recognized as well, since it is the normal form of some
+// computations yielding one, such as raising to the power zero. Terms that
+// are not numerals give None.
+func ReadNumeral(t term.Term) monad.Maybe[int] {
+	outer, ok := term.ToNameless(t).(term.Lam)
+	if !ok {
+		return monad.None[int]()
+	}
+	if index, ok := outer.Body().(term.Index); ok && index.Index() == 0 {
+		return monad.Some(1)
+	}
+	inner, ok := outer.Body().(term.Lam)
+	if !ok {
+		return monad.None[int]()
+	}
+
+	// The body applies f, of index 1, to x, of index 0, n times
+	n := 0
+	body := inner.Body()
+	for {
+		switch b := body.(type) {
+		case term.Index:
+			if b.Index() != 0 {
+				return monad.None[int]()
+			}
+			return monad.Some(n)
+		case term.Ap:
+			if f, ok := b.Fun().(term.Index); !ok || f.Index() != 1 {
+				return monad.None[int]()
+			}
+			n++
+			body = b.Arg()
+		default:
+			return monad.None[int]()
+		}
+	}
+}
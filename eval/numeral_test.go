@@ -0,0 +1,85 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/parser"
+)
+
+func TestReadNumeral(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		source   string
+		expected int
+		ok       bool
+	}{
+		{`\f.\x.x`, 0, true},
+		{`\s.\z.s (s (s z))`, 3, true},
+		{`\f.f`, 1, true},
+		{`\f.\x.f`, 0, false},
+		{`\x.\f.f (f x)`, 0, false},
+		{`\f.\x.f (f f)`, 0, false},
+		{`\f.\f.f (f f)`, 0, false},
+		{`\f.\x.f x x`, 0, false},
+		{`\f.\x.g x`, 0, false},
+		{`x`, 0, false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.source, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			program := Load(parser.ParseString(testCase.source).Value())
+			is.True(program.Success(), "%v", program.Error())
+			n := ReadNumeral(program.Value().Expressions()[0])
+			is.Equal(testCase.ok, n.Just())
+			if testCase.ok {
+				is.Equal(testCase.expected, n.Value())
+			}
+		})
+	}
+}
+
+func TestNumeral(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.Equal(`\f.\x.x`, Numeral(0).String())
+	is.Equal(`\f.\x.f (f x)`, Numeral(2).String())
+	is.Panics(func() { Numeral(-1) })
+}
+
+func TestNumeralProperties(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 200
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("ReadNumeral decodes Numeral", prop.ForAll(
+		func(n int) bool {
+			decoded := ReadNumeral(Numeral(n))
+			return decoded.Just() && decoded.Value() == n
+		},
+		gen.IntRange(0, 1000),
+	))
+
+	properties.Property("ReadNumeral decodes the sum of numerals", prop.ForAll(
+		func(m, n int) bool {
+			plus := entry(t, church+"main := plus ("+Numeral(m).String()+") ("+Numeral(n).String()+")")
+			normal := NewNormalOrder().Evaluate(context.Background(), plus)
+			decoded := ReadNumeral(normal.Value())
+			return normal.Success() && decoded.Just() && decoded.Value() == m+n
+		},
+		gen.IntRange(0, 50), gen.IntRange(0, 50),
+	))
+
+	properties.TestingRun(t)
+}
@@ -0,0 +1,111 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Primitive is a function of the application embedding λ.c, which programs
+// reach through a free variable of the linked term (see package host).
+// Evaluators apply a primitive, in a single step, once it is applied to as
+// many arguments as it takes, its strict arguments being normalized first.
+type Primitive interface {
+	// Arity returns the number of arguments the primitive takes.
+	Arity() int
+	// Strict tells whether the argument of the given index, starting at 0,
+	// is to be normalized before the primitive is applied. Other arguments
+	// are passed as they are, possibly with redexes.
+	Strict(i int) bool
+	// Apply computes the term the application of the primitive to args
+	// reduces to. The term must be closed.
+	Apply(ctx context.Context, args []term.Term) (term.Term, error)
+}
+
+// Primitives maps the names of the free variables standing for primitives,
+// such as host/os->getenv, to the primitives.
+type Primitives map[string]Primitive
+
+// delta applies the primitive t is an application of, if any, and carries on
+// evaluating the result with next. Other terms are returned as they are.
+func (m *meter) delta(t term.Term, next func(*meter, term.Term) (term.Term, error)) (term.Term, error) {
+	r, applied, err := m.primitive(t)
+	if err != nil || !applied {
+		return r, err
+	}
+	return next(m, r)
+}
+
+// primitive contracts t when it is a primitive applied to as many arguments
+// as it takes, and reports whether it did. A primitive is stuck, and t
+// returned with the strict arguments normalized so far, when one of them
+// has free variables: applied under an abstraction to its parameter, for
+// instance, a primitive has nothing to compute with yet.
+func (m *meter) primitive(t term.Term) (term.Term, bool, error) {
+	if len(m.primitives) == 0 {
+		return t, false, nil
+	}
+	var apps []term.App
+	fun := t
+	for {
+		app, ok := fun.(term.App)
+		if !ok {
+			break
+		}
+		apps = append([]term.App{app}, apps...)
+		fun = app.Fun()
+	}
+	v, ok := fun.(term.Var)
+	if !ok {
+		return t, false, nil
+	}
+	p, ok := m.primitives[v.Name()]
+	if !ok || p.Arity() != len(apps) {
+		return t, false, nil
+	}
+
+	args := make([]term.Term, len(apps))
+	for i, app := range apps {
+		args[i] = app.Arg()
+	}
+	for i := range args {
+		if !p.Strict(i) {
+			continue
+		}
+		for j := len(args) - 1; j > i; j-- {
+			m.enter(inFun(apps[j], args[j]))
+		}
+		m.enter(inArg(apps[i], spine(v, apps[:i], args)))
+		arg, err := normalize(m, args[i])
+		for j := i; j < len(args); j++ {
+			m.leave()
+		}
+		if err != nil {
+			return t, false, err
+		}
+		args[i] = arg
+		if len(term.FreeVars(arg)) > 0 {
+			return spine(v, apps, args), false, nil
+		}
+	}
+
+	redex := spine(v, apps, args)
+	contractum, err := p.Apply(m.ctx, args)
+	if err != nil {
+		return t, false, fmt.Errorf("%s: %w", v.Name(), err)
+	}
+	if err := m.step(redex, contractum); err != nil {
+		return t, false, err
+	}
+	return contractum, true, nil
+}
+
+// spine applies fun to the first len(apps) arguments, positioning the
+// applications as apps.
+func spine(fun term.Term, apps []term.App, args []term.Term) term.Term {
+	for i, app := range apps {
+		fun = term.NewApp(fun, args[i]).WithPosition(app.Position())
+	}
+	return fun
+}
@@ -0,0 +1,169 @@
+package eval
+
+import (
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Environment maps the names of top-level definitions to their terms. It is
+// immutable: Define returns an updated copy, which makes it cheap to keep
+// snapshots, as the REPL does between lines.
+type Environment struct {
+	definitions map[string]term.Term
+	order       []string
+}
+
+// NewEnvironment returns an empty Environment.
+func NewEnvironment() Environment {
+	return Environment{definitions: map[string]term.Term{}}
+}
+
+// Define returns a copy of the Environment in which name is bound to t,
+// replacing any previous definition of the same name.
+func (e Environment) Define(name string, t term.Term) Environment {
+	definitions := make(map[string]term.Term, len(e.definitions)+1)
+	for n, d := range e.definitions {
+		definitions[n] = d
+	}
+	order := append([]string{}, e.order...)
+	if _, ok := definitions[name]; !ok {
+		order = append(order, name)
+	}
+	definitions[name] = t
+	return Environment{definitions: definitions, order: order}
+}
+
+// Lookup returns the term bound to name, if any.
+func (e Environment) Lookup(name string) monad.Maybe[term.Term] {
+	if t, ok := e.definitions[name]; ok {
+		return monad.Some(t)
+	}
+	return monad.None[term.Term]()
+}
+
+// Names returns the defined names, in order of first definition.
+func (e Environment) Names() []string {
+	return append([]string{}, e.order...)
+}
+
+// Close inlines the definitions of the Environment into t, transitively, so
+// that the resulting term only has free variables that are not defined. The
+// definitions are substituted rather than bound by abstractions, hence do not
+// cost any reduction step. Recursive definitions cannot be inlined and are
+// reported as errors; they must be rewritten with a fixpoint combinator
+// beforehand.
+func (e Environment) Close(t term.Term) monad.Result[term.Term, error] {
+	c := closer{env: e, closed: map[string]term.Term{}, pending: map[string]bool{}}
+	return c.close(t)
+}
+
+// closer implements Environment.Close, memoizing the closed definitions and
+// tracking the ones being closed to detect recursion.
+type closer struct {
+	env     Environment
+	closed  map[string]term.Term
+	pending map[string]bool
+}
+
+func (c closer) close(t term.Term) monad.Result[term.Term, error] {
+	for name := range term.FreeVars(t) {
+		definition := c.env.Lookup(name)
+		if definition.Nothing() {
+			continue
+		}
+		closed := c.closeDefinition(name, definition.Value())
+		if closed.Failure() {
+			return closed
+		}
+		t = term.Subst(t, name, closed.Value())
+	}
+	return monad.Succeed[term.Term, error](t)
+}
+
+func (c closer) closeDefinition(name string, definition term.Term) monad.Result[term.Term, error] {
+	if closed, ok := c.closed[name]; ok {
+		return monad.Succeed[term.Term, error](closed)
+	}
+	if c.pending[name] {
+		return monad.Fail[term.Term, error](diagnostic.Errorf(
+			definition.Position(), "definition %q is recursive", name,
+		).WithHint("apply a fixpoint combinator, or enable automatic fixpoint insertion"))
+	}
+
+	c.pending[name] = true
+	closed := c.close(definition)
+	delete(c.pending, name)
+	if closed.Success() {
+		c.closed[name] = closed.Value()
+	}
+	return closed
+}
+
+// Program is a parsed program converted to terms: the Environment of its
+// top-level definitions, and its bare expression statements.
+type Program struct {
+	environment Environment
+	expressions []term.Term
+}
+
+// NewProgram builds a Program from the environment of its definitions and
+// its bare expression statements, for callers that convert programs
+// themselves, such as the module loader linking several modules together.
+func NewProgram(environment Environment, expressions ...term.Term) Program {
+	return Program{environment: environment, expressions: expressions}
+}
+
+// Load converts a parsed program into a Program. Imports are ignored: the
+// definitions of other modules are linked by the module loader.
+func Load(program parser.ASTNode) monad.Result[Program, error] {
+	p := Program{environment: NewEnvironment()}
+	for _, statement := range program.Children() {
+		switch statement.NodeType() {
+		case lexer.MODULE, lexer.EOF:
+		case lexer.ASSIGN:
+			children := statement.Children()
+			t := term.FromAST(children[1])
+			if t.Failure() {
+				return monad.Fail[Program, error](t.Error())
+			}
+			p.environment = p.environment.Define(children[0].Token().Literal().String(), t.Value())
+		default:
+			t := term.FromAST(statement)
+			if t.Failure() {
+				return monad.Fail[Program, error](t.Error())
+			}
+			p.expressions = append(p.expressions, t.Value())
+		}
+	}
+	return monad.Succeed[Program, error](p)
+}
+
+// Environment returns the definitions of the Program.
+func (p Program) Environment() Environment {
+	return p.environment
+}
+
+// Expressions returns the bare expression statements of the Program, in
+// source order.
+func (p Program) Expressions() []term.Term {
+	return append([]term.Term{}, p.expressions...)
+}
+
+// Entry returns the closed term to evaluate in order to run the Program: its
+// last bare expression statement if any, the definition named entry
+// otherwise.
+func (p Program) Entry(entry string) monad.Result[term.Term, error] {
+	if len(p.expressions) > 0 {
+		return p.environment.Close(p.expressions[len(p.expressions)-1])
+	}
+	if p.environment.Lookup(entry).Nothing() {
+		return monad.Fail[term.Term, error](diagnostic.Errorf(
+			lexer.StartPosition(), "no entry point: the program has no expression and no %q definition", entry,
+		))
+	}
+	return p.environment.Close(term.NewVar(entry))
+}
@@ -0,0 +1,269 @@
+package eval
+
+import (
+	"context"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// ApplicativeOrder evaluates terms by contracting the leftmost-innermost redex
+// first: both sides of an application are normalized before the application
+// itself is contracted. It avoids duplicating unevaluated arguments, but
+// diverges on terms that discard a diverging argument.
+type ApplicativeOrder struct {
+	maxSteps   int
+	tracer     Tracer
+	primitives Primitives
+}
+
+// NewApplicativeOrder returns an ApplicativeOrder evaluator limited to
+// DefaultMaxSteps reduction steps.
+func NewApplicativeOrder() ApplicativeOrder {
+	return ApplicativeOrder{maxSteps: DefaultMaxSteps}
+}
+
+// WithMaxSteps returns a copy of the evaluator limited to n reduction steps, or
+// unlimited if n is zero or less.
+func (e ApplicativeOrder) WithMaxSteps(n int) ApplicativeOrder {
+	e.maxSteps = n
+	return e
+}
+
+// WithTracer returns a copy of the evaluator reporting every reduction step to
+// tracer.
+func (e ApplicativeOrder) WithTracer(tracer Tracer) ApplicativeOrder {
+	e.tracer = tracer
+	return e
+}
+
+// WithPrimitives returns a copy of the evaluator applying the given
+// primitives.
+func (e ApplicativeOrder) WithPrimitives(primitives Primitives) ApplicativeOrder {
+	e.primitives = primitives
+	return e
+}
+
+// Evaluate reduces t to its beta normal form.
+func (e ApplicativeOrder) Evaluate(ctx context.Context, t term.Term) monad.Result[term.Term, error] {
+	m := newMeter(ctx, e.maxSteps, e.tracer, e.primitives,
+		"applicative", "leftmost-innermost redex, operands normalized")
+	return monad.FromTuple(applicative(m, t))
+}
+
+func applicative(m *meter, t term.Term) (term.Term, error) {
+	steps := m.steps
+	switch t := t.(type) {
+	case term.Abs:
+		m.enter(inBody(t))
+		body, err := applicative(m, t.Body())
+		m.leave()
+		if err != nil {
+			return t, err
+		}
+		return rebuildAbs(t, body, m.steps != steps), nil
+	case term.App:
+		m.enter(inFun(t, t.Arg()))
+		fun, err := applicative(m, t.Fun())
+		m.leave()
+		if err != nil {
+			return t, err
+		}
+		m.enter(inArg(t, fun))
+		arg, err := applicative(m, t.Arg())
+		m.leave()
+		if err != nil {
+			return t, err
+		}
+		rebuilt := rebuildApp(t, fun, arg, m.steps != steps)
+		if abs, ok := fun.(term.Abs); ok {
+			contractum := term.Subst(abs.Body(), abs.Param(), arg)
+			if err := m.step(rebuilt, contractum); err != nil {
+				return t, err
+			}
+			return applicative(m, contractum)
+		}
+		return m.delta(rebuilt, applicative)
+	case term.Var:
+		return m.delta(t, applicative)
+	}
+	return t, nil
+}
+
+// CallByValue evaluates terms the way strict functional languages do:
+// arguments are reduced to values, that is abstractions or variables applied
+// to values, before being substituted, and abstractions are values that are
+// never reduced further.
+type CallByValue struct {
+	maxSteps   int
+	tracer     Tracer
+	primitives Primitives
+}
+
+// NewCallByValue returns a CallByValue evaluator limited to DefaultMaxSteps
+// reduction steps.
+func NewCallByValue() CallByValue {
+	return CallByValue{maxSteps: DefaultMaxSteps}
+}
+
+// WithMaxSteps returns a copy of the evaluator limited to n reduction steps, or
+// unlimited if n is zero or less.
+func (e CallByValue) WithMaxSteps(n int) CallByValue {
+	e.maxSteps = n
+	return e
+}
+
+// WithTracer returns a copy of the evaluator reporting every reduction step to
+// tracer.
+func (e CallByValue) WithTracer(tracer Tracer) CallByValue {
+	e.tracer = tracer
+	return e
+}
+
+// WithPrimitives returns a copy of the evaluator applying the given
+// primitives.
+func (e CallByValue) WithPrimitives(primitives Primitives) CallByValue {
+	e.primitives = primitives
+	return e
+}
+
+// Evaluate reduces t to a value.
+func (e CallByValue) Evaluate(ctx context.Context, t term.Term) monad.Result[term.Term, error] {
+	m := newMeter(ctx, e.maxSteps, e.tracer, e.primitives, "value", "argument reduced to a value")
+	return monad.FromTuple(byValue(m, t))
+}
+
+func byValue(m *meter, t term.Term) (term.Term, error) {
+	app, ok := t.(term.App)
+	if !ok {
+		return m.delta(t, byValue)
+	}
+
+	steps := m.steps
+	m.enter(inFun(app, app.Arg()))
+	fun, err := byValue(m, app.Fun())
+	m.leave()
+	if err != nil {
+		return t, err
+	}
+	m.enter(inArg(app, fun))
+	arg, err := byValue(m, app.Arg())
+	m.leave()
+	if err != nil {
+		return t, err
+	}
+	rebuilt := rebuildApp(app, fun, arg, m.steps != steps)
+	if abs, ok := fun.(term.Abs); ok {
+		contractum := term.Subst(abs.Body(), abs.Param(), arg)
+		if err := m.step(rebuilt, contractum); err != nil {
+			return t, err
+		}
+		return byValue(m, contractum)
+	}
+	return m.delta(rebuilt, byValue)
+}
+
+// CallByName evaluates terms the way non-strict languages without sharing do:
+// arguments are substituted unevaluated, and evaluation stops as soon as the
+// term is an abstraction or a variable applied to arguments, that is in weak
+// head normal form.
+type CallByName struct {
+	maxSteps   int
+	tracer     Tracer
+	primitives Primitives
+}
+
+// NewCallByName returns a CallByName evaluator limited to DefaultMaxSteps
+// reduction steps.
+func NewCallByName() CallByName {
+	return CallByName{maxSteps: DefaultMaxSteps}
+}
+
+// WithMaxSteps returns a copy of the evaluator limited to n reduction steps, or
+// unlimited if n is zero or less.
+func (e CallByName) WithMaxSteps(n int) CallByName {
+	e.maxSteps = n
+	return e
+}
+
+// WithTracer returns a copy of the evaluator reporting every reduction step to
+// tracer.
+func (e CallByName) WithTracer(tracer Tracer) CallByName {
+	e.tracer = tracer
+	return e
+}
+
+// WithPrimitives returns a copy of the evaluator applying the given
+// primitives.
+func (e CallByName) WithPrimitives(primitives Primitives) CallByName {
+	e.primitives = primitives
+	return e
+}
+
+// Evaluate reduces t to weak head normal form.
+func (e CallByName) Evaluate(ctx context.Context, t term.Term) monad.Result[term.Term, error] {
+	m := newMeter(ctx, e.maxSteps, e.tracer, e.primitives,
+		"name", "head redex, argument substituted unevaluated")
+	return monad.FromTuple(whnf(m, t))
+}
+
+// HeadReduction contracts the head redex only, reducing under the leading
+// abstractions but leaving the arguments of the head variable untouched. Its
+// result, the head normal form, exists exactly for the solvable terms.
+type HeadReduction struct {
+	maxSteps   int
+	tracer     Tracer
+	primitives Primitives
+}
+
+// NewHeadReduction returns a HeadReduction evaluator limited to
+// DefaultMaxSteps reduction steps.
+func NewHeadReduction() HeadReduction {
+	return HeadReduction{maxSteps: DefaultMaxSteps}
+}
+
+// WithMaxSteps returns a copy of the evaluator limited to n reduction steps, or
+// unlimited if n is zero or less.
+func (e HeadReduction) WithMaxSteps(n int) HeadReduction {
+	e.maxSteps = n
+	return e
+}
+
+// WithTracer returns a copy of the evaluator reporting every reduction step to
+// tracer.
+func (e HeadReduction) WithTracer(tracer Tracer) HeadReduction {
+	e.tracer = tracer
+	return e
+}
+
+// WithPrimitives returns a copy of the evaluator applying the given
+// primitives.
+func (e HeadReduction) WithPrimitives(primitives Primitives) HeadReduction {
+	e.primitives = primitives
+	return e
+}
+
+// Evaluate reduces t to head normal form.
+func (e HeadReduction) Evaluate(ctx context.Context, t term.Term) monad.Result[term.Term, error] {
+	m := newMeter(ctx, e.maxSteps, e.tracer, e.primitives, "head", "head redex")
+	return monad.FromTuple(head(m, t))
+}
+
+func head(m *meter, t term.Term) (term.Term, error) {
+	t, err := whnf(m, t)
+	if err != nil {
+		return t, err
+	}
+	if abs, ok := t.(term.Abs); ok {
+		steps := m.steps
+		m.enter(inBody(abs))
+		body, err := head(m, abs.Body())
+		m.leave()
+		if err != nil {
+			return t, err
+		}
+		return rebuildAbs(abs, body, m.steps != steps), nil
+	}
+	return t, nil
+}
@@ -0,0 +1,104 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategies(t *testing.T) {
+	t.Parallel()
+
+	const diverges = "diverges"
+	strategies := []string{"normal", "applicative", "value", "name", "need", "head"}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			"discarded divergence",
+			`(\x.\y.y) ((\x.x x) (\x.x x))`,
+			[]string{`\y.y`, diverges, diverges, `\y.y`, `\y.y`, `\y.y`},
+		},
+		{
+			"redex under binder",
+			`\x.(\y.y) x`,
+			[]string{`\x.x`, `\x.x`, `\x.(\y.y) x`, `\x.(\y.y) x`, `\x.(\y.y) x`, `\x.x`},
+		},
+		{
+			"redex in argument",
+			`x ((\y.y) z)`,
+			[]string{`x z`, `x z`, `x z`, `x ((\y.y) z)`, `x ((\y.y) z)`, `x ((\y.y) z)`},
+		},
+		{
+			"unevaluated argument",
+			`(\x.\y.x) ((\z.z) w)`,
+			[]string{`\y.w`, `\y.w`, `\y.w`, `\y.(\z.z) w`, `\y.(\z.z) w`, `\y.w`},
+		},
+		{
+			"duplicated argument",
+			`(\x.\y.x x) ((\z.z) w)`,
+			[]string{`\y.w w`, `\y.w w`, `\y.w w`, `\y.(\z.z) w ((\z.z) w)`, `\y.(\z.z) w ((\z.z) w)`, `\y.w ((\z.z) w)`},
+		},
+		{
+			"capture avoided",
+			`(\x.\y.\z.x y) y w`,
+			[]string{`\z.y w`, `\z.y w`, `\z.y w`, `\z.y w`, `\z.y w`, `\z.y w`},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		for i, strategy := range strategies {
+			strategy, expected := strategy, testCase.expected[i]
+			t.Run(testCase.name+"/"+strategy, func(t *testing.T) {
+				t.Parallel()
+				is := require.New(t)
+
+				evaluator := NewEvaluator(strategy, 1000, nil)
+				is.True(evaluator.Success())
+				result := evaluator.Value().Evaluate(context.Background(), entry(t, testCase.input))
+				if expected == diverges {
+					is.True(result.Failure())
+					is.True(errors.Is(result.Error(), ErrStepLimit))
+					return
+				}
+				is.True(result.Success(), "%v", result.Error())
+				is.Equal(expected, result.Value().String())
+			})
+		}
+	}
+}
+
+func TestCallByNeedSharing(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	// The argument takes 3 steps to evaluate and is used 4 times: 16 steps
+	// without sharing, 7 with it.
+	input := entry(t, `(\x.x (x (x x))) ((\a.\b.a) ((\c.c) (\d.d)) e)`)
+
+	byName := NewCallByName().WithMaxSteps(10).Evaluate(context.Background(), input)
+	is.True(byName.Failure())
+	is.True(errors.Is(byName.Error(), ErrStepLimit))
+
+	byNeed := NewCallByNeed().WithMaxSteps(10).Evaluate(context.Background(), input)
+	is.True(byNeed.Success(), "%v", byNeed.Error())
+	is.Equal(`\d.d`, byNeed.Value().String())
+}
+
+func TestNewEvaluator(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.IsType(CallByName{}, NewEvaluator("name", 0, nil).Value())
+	is.Equal(`unknown evaluation strategy "lazy", expected one of `+
+		`normal, applicative, value, name, need, head, krivine, krivine-need`, NewEvaluator("lazy", 0, nil).Error().Error())
+	is.IsType(Krivine{}, NewEvaluator("krivine-need", 0, nil).Value())
+	is.EqualError(NewEvaluator("krivine", 0, NewTextTracer(io.Discard)).Error(), "the krivine backend cannot be traced")
+}
@@ -0,0 +1,71 @@
+package eval
+
+import (
+	"unicode/utf8"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// ReadList decodes a Church list in normal form, \c.\n.c a (c b n), into its
+// elements. Terms that are not lists give None. Beware that the empty list is
+// also the numeral zero and the boolean false.
+func ReadList(t term.Term) monad.Maybe[[]term.Term] {
+	outer, ok := t.(term.Abs)
+	if !ok {
+		return monad.None[[]term.Term]()
+	}
+	inner, ok := outer.Body().(term.Abs)
+	if !ok || inner.Param() == outer.Param() {
+		return monad.None[[]term.Term]()
+	}
+	cons, empty := outer.Param(), inner.Param()
+
+	elements := []term.Term{}
+	body := inner.Body()
+	for {
+		switch b := body.(type) {
+		case term.Var:
+			if b.Name() != empty {
+				return monad.None[[]term.Term]()
+			}
+			return monad.Some(elements)
+		case term.App:
+			head, ok := b.Fun().(term.App)
+			if !ok {
+				return monad.None[[]term.Term]()
+			}
+			if c, ok := head.Fun().(term.Var); !ok || c.Name() != cons {
+				return monad.None[[]term.Term]()
+			}
+			if term.IsFree(cons, head.Arg()) || term.IsFree(empty, head.Arg()) {
+				return monad.None[[]term.Term]()
+			}
+			elements = append(elements, head.Arg())
+			body = b.Arg()
+		default:
+			return monad.None[[]term.Term]()
+		}
+	}
+}
+
+// ReadString decodes the normal form of a string, a Church list of the
+// numerals of Unicode code points such as the string literals of λ.c stand
+// for. Terms that are not such lists give None.
+func ReadString(t term.Term) monad.Maybe[string] {
+	elements := ReadList(t)
+	if elements.Nothing() {
+		return monad.None[string]()
+	}
+
+	runes := make([]rune, 0, len(elements.Value()))
+	for _, element := range elements.Value() {
+		n := ReadNumeral(element)
+		if n.Nothing() || n.Value() > utf8.MaxRune || !utf8.ValidRune(rune(n.Value())) {
+			return monad.None[string]()
+		}
+		runes = append(runes, rune(n.Value()))
+	}
+	return monad.Some(string(runes))
+}
@@ -0,0 +1,57 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+func TestReadString(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	// Reverses a list by folding it
+	source := `reverse := \l.l (\h.\t.\c.\n.t c (c h n)) (\c.\n.n)
+main := reverse "héllo\n"
+`
+	result := NewNormalOrder().Evaluate(context.Background(), entry(t, source))
+	is.True(result.Success(), "%v", result.Error())
+	is.Equal("\nolléh", ReadString(result.Value()).Value())
+
+	is.Equal("", ReadString(expression(t, `\c.\n.n`)).Value())
+	is.Len(ReadList(expression(t, `\c.\n.c a (c (b d) n)`)).Value(), 2)
+
+	for _, source := range []string{
+		`\c.c`,
+		`\c.\c.c a c`,
+		`\c.\n.c a`,
+		`\c.\n.c a (c b m)`,
+		`\c.\n.d a n`,
+		`\c.\n.c c n`,
+		`\c.\n.c (\x.n) n`,
+	} {
+		is.True(ReadList(expression(t, source)).Nothing(), source)
+	}
+	is.True(ReadString(expression(t, `\c.\n.c a n`)).Nothing())
+}
+
+func TestReadStringProperties(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 200
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("ReadString decodes string literals", prop.ForAll(
+		func(s string) bool {
+			return ReadString(term.Literal(s)).Value() == s
+		},
+		gen.AlphaString(),
+	))
+
+	properties.TestingRun(t)
+}
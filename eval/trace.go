@@ -0,0 +1,215 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Tracer receives an Event for every reduction step of a traced evaluation.
+// An error returned by Trace aborts the evaluation.
+type Tracer interface {
+	Trace(Event) error
+}
+
+// TracerFunc adapts a function to the Tracer interface.
+type TracerFunc func(Event) error
+
+// Trace calls f(e).
+func (f TracerFunc) Trace(e Event) error {
+	return f(e)
+}
+
+// Event describes a reduction step: which redex was contracted, where it
+// stems from in the source, why the strategy picked it, and the resulting
+// term.
+type Event struct {
+	step       int
+	strategy   string
+	decision   string
+	redex      term.Term
+	contractum term.Term
+	frames     []frame
+}
+
+// Step returns the number of the step, starting at 1.
+func (e Event) Step() int {
+	return e.step
+}
+
+// Strategy returns the name of the evaluation strategy, as accepted by
+// NewEvaluator.
+func (e Event) Strategy() string {
+	return e.strategy
+}
+
+// Decision describes why the strategy contracted this redex.
+func (e Event) Decision() string {
+	return e.decision
+}
+
+// Redex returns the contracted redex.
+func (e Event) Redex() term.Term {
+	return e.redex
+}
+
+// Position returns the location in the source text of the application the
+// redex stems from. Substitution preserves positions, so this is the
+// application as written, even when the redex was created by earlier steps.
+func (e Event) Position() lexer.Position {
+	return e.redex.Position()
+}
+
+// Term returns the whole term after the step. CallByNeed evaluates a graph in
+// which arguments are shared rather than a term, and only reports the
+// contractum.
+func (e Event) Term() term.Term {
+	return plug(e.frames, e.contractum)
+}
+
+// Highlight renders the whole term before the step, surrounding the redex with
+// open and close.
+func (e Event) Highlight(open, close string) string {
+	redex := e.redex.String()
+	if len(e.frames) > 0 && e.frames[len(e.frames)-1].kind == argFrame {
+		redex = "(" + redex + ")"
+	}
+	return strings.Replace(plug(e.frames, term.NewVar(hole)).String(), hole, open+redex+close, 1)
+}
+
+// String renders the event on a single line, the redex being highlighted
+// with brackets.
+func (e Event) String() string {
+	return e.line("[", "]")
+}
+
+// line renders the event on a single line, the redex being highlighted with
+// open and close.
+func (e Event) line(open, close string) string {
+	return fmt.Sprintf(
+		"%d. %d:%d %s: %s",
+		e.step, e.Position().Row(), e.Position().Col(), e.decision, e.Highlight(open, close),
+	)
+}
+
+// MarshalJSON encodes the event as a JSON object.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type position struct {
+		Row int `json:"row"`
+		Col int `json:"col"`
+	}
+	return json.Marshal(struct {
+		Step     int      `json:"step"`
+		Strategy string   `json:"strategy"`
+		Decision string   `json:"decision"`
+		Position position `json:"position"`
+		Redex    string   `json:"redex"`
+		Term     string   `json:"term"`
+	}{
+		Step:     e.step,
+		Strategy: e.strategy,
+		Decision: e.decision,
+		Position: position{Row: e.Position().Row(), Col: e.Position().Col()},
+		Redex:    e.redex.String(),
+		Term:     e.Term().String(),
+	})
+}
+
+// TextTracer writes events in a human readable form: each step on a line,
+// followed by the resulting term on the next.
+type TextTracer struct {
+	w           io.Writer
+	open, close string
+}
+
+// NewTextTracer returns a TextTracer writing to w, highlighting redexes with
+// brackets.
+func NewTextTracer(w io.Writer) TextTracer {
+	return TextTracer{w: w, open: "[", close: "]"}
+}
+
+// WithHighlight returns a copy of the tracer highlighting redexes with open
+// and close instead, for instance terminal escape sequences.
+func (t TextTracer) WithHighlight(open, close string) TextTracer {
+	t.open, t.close = open, close
+	return t
+}
+
+// Trace writes e.
+func (t TextTracer) Trace(e Event) error {
+	_, err := fmt.Fprintf(t.w, "%s\n   => %s\n", e.line(t.open, t.close), e.Term())
+	return err
+}
+
+// JSONTracer writes events as JSON objects, one per line.
+type JSONTracer struct {
+	encoder *json.Encoder
+}
+
+// NewJSONTracer returns a JSONTracer writing to w.
+func NewJSONTracer(w io.Writer) JSONTracer {
+	return JSONTracer{encoder: json.NewEncoder(w)}
+}
+
+// Trace writes e.
+func (t JSONTracer) Trace(e Event) error {
+	return t.encoder.Encode(e)
+}
+
+// hole is the name of the variable standing for the redex when highlighting.
+// It cannot clash with an identifier.
+const hole = "\x00"
+
+// frameKind tells which part of its term a frame has a hole in.
+type frameKind int
+
+const (
+	funFrame frameKind = iota
+	argFrame
+	bodyFrame
+)
+
+// frame is a term with a hole, one level deep: the function or argument of an
+// application, or the body of an abstraction. A stack of frames locates a
+// subterm within the whole term.
+type frame struct {
+	kind     frameKind
+	sibling  term.Term
+	param    string
+	position lexer.Position
+}
+
+// inFun returns the frame of the function of a, whose argument is arg.
+func inFun(a term.App, arg term.Term) frame {
+	return frame{kind: funFrame, sibling: arg, position: a.Position()}
+}
+
+// inArg returns the frame of the argument of a, whose function is fun.
+func inArg(a term.App, fun term.Term) frame {
+	return frame{kind: argFrame, sibling: fun, position: a.Position()}
+}
+
+// inBody returns the frame of the body of a.
+func inBody(a term.Abs) frame {
+	return frame{kind: bodyFrame, param: a.Param(), position: a.Position()}
+}
+
+// plug fills the innermost hole of frames with t, and returns the whole term.
+func plug(frames []frame, t term.Term) term.Term {
+	for i := len(frames) - 1; i >= 0; i-- {
+		f := frames[i]
+		switch f.kind {
+		case funFrame:
+			t = term.NewApp(t, f.sibling).WithPosition(f.position)
+		case argFrame:
+			t = term.NewApp(f.sibling, t).WithPosition(f.position)
+		case bodyFrame:
+			t = term.NewAbs(f.param, t).WithPosition(f.position)
+		}
+	}
+	return t
+}
@@ -0,0 +1,84 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+func TestTextTracer(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	var out bytes.Buffer
+	result := NewNormalOrder().
+		WithTracer(NewTextTracer(&out)).
+		Evaluate(context.Background(), entry(t, `(\x.\y.x) ((\z.z) a) b`))
+	is.True(result.Success())
+	is.Equal(`1. 1:1 leftmost-outermost redex: [(\x.\y.x) ((\z.z) a)] b
+   => (\y.(\z.z) a) b
+2. 1:1 leftmost-outermost redex: [(\y.(\z.z) a) b]
+   => (\z.z) a
+3. 1:12 leftmost-outermost redex: [(\z.z) a]
+   => a
+`, out.String())
+}
+
+func TestHighlight(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	events := []Event{}
+	tracer := TracerFunc(func(e Event) error {
+		events = append(events, e)
+		return nil
+	})
+	result := NewApplicativeOrder().
+		WithTracer(tracer).
+		Evaluate(context.Background(), entry(t, `f ((\x.x) a) (\y.(\z.z) y)`))
+	is.True(result.Success())
+	is.Len(events, 2)
+
+	is.Equal(`f <((\x.x) a)> (\y.(\z.z) y)`, events[0].Highlight("<", ">"))
+	is.Equal(`f a (\y.(\z.z) y)`, events[0].Term().String())
+	is.Equal(lexer.NewPosition(1, 4), events[0].Position())
+	is.Equal(`f a (\y.[(\z.z) y])`, events[1].Highlight("[", "]"))
+	is.Equal(`(\z.z) y`, events[1].Redex().String())
+	is.Equal(2, events[1].Step())
+	is.Equal("applicative", events[1].Strategy())
+	is.Equal("leftmost-innermost redex, operands normalized", events[1].Decision())
+}
+
+func TestJSONTracer(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	var out bytes.Buffer
+	result := NewCallByNeed().
+		WithTracer(NewJSONTracer(&out)).
+		Evaluate(context.Background(), entry(t, `(\x.x x) ((\z.z) (\w.w))`))
+	is.True(result.Success())
+	is.Equal(`{"step":1,"strategy":"need","decision":"head redex, argument shared unevaluated",`+
+		`"position":{"row":1,"col":1},"redex":"(\\x.x x) ((\\z.z) (\\w.w))","term":"(\\z.z) (\\w.w) ((\\z.z) (\\w.w))"}
+{"step":2,"strategy":"need","decision":"head redex, argument shared unevaluated",`+
+		`"position":{"row":1,"col":11},"redex":"(\\z.z) (\\w.w)","term":"\\w.w"}
+{"step":3,"strategy":"need","decision":"head redex, argument shared unevaluated",`+
+		`"position":{"row":1,"col":4},"redex":"(\\w.w) (\\w.w)","term":"\\w.w"}
+`, out.String())
+}
+
+func TestTracerFailure(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	broken := errors.New("broken pipe")
+	result := NewCallByValue().
+		WithTracer(TracerFunc(func(Event) error { return broken })).
+		Evaluate(context.Background(), entry(t, `(\x.x) y`))
+	is.True(errors.Is(result.Error(), broken))
+}
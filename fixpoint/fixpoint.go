@@ -0,0 +1,129 @@
+// Package fixpoint deals with recursive top-level definitions.
+//
+// Overview:
+//
+// In the untyped lambda calculus, a definition cannot refer to itself: a name
+// is nothing but an abbreviation for a term, and `fact := \n. ... fact ...`
+// would abbreviate an infinite term. Yet this is how people naturally write
+// recursive functions. The same goes for mutually recursive definitions, such
+// as `even` and `odd` defined in terms of each other.
+//
+// This package builds the dependency graph of the top-level definitions of a
+// resolved program, and finds its cycles as strongly connected components.
+// From there, two behaviours are offered:
+//
+//   - Detect and Diagnostics report every recursive group of definitions as an
+//     error, explaining the cycle.
+//   - Rewrite, which callers opt into, turns recursive definitions into
+//     non-recursive ones thanks to a fixpoint combinator.
+//
+// Rewriting:
+//
+// A directly recursive definition `f := body` becomes `f := FIX (\f.body)`,
+// where FIX is the chosen combinator. Mutually recursive definitions f1 ... fn
+// are tupled: a private helper definition computes the fixpoint of a function
+// returning the Church tuple of all the bodies, and each fi selects its own
+// component from it:
+//
+//	_f1_fn := FIX (\t.(\f1. ... \fn.\s.s body1 ... bodyn) (t sel1) ... (t seln))
+//	fi     := _f1_fn seli
+//
+// where seli is `\_. ... \fi. ... \_.fi`. The Y combinator suits the lazy
+// evaluation strategies. Under call-by-value, the Z combinator must be used,
+// in which case the recursive references are also eta-expanded so that they
+// are not evaluated before being called.
+package fixpoint
+
+import (
+	"strings"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+)
+
+// Combinator selects the fixpoint combinator inserted by Rewrite.
+type Combinator int
+
+const (
+	Y Combinator = iota // Y is Curry's combinator \f.(\x.f (x x)) (\x.f (x x)), for lazy strategies.
+	Z                   // Z is the strict combinator \f.(\x.f (\v.x x v)) (\x.f (\v.x x v)), for call-by-value.
+)
+
+// Group is a set of top-level definitions that depend on each other, directly
+// or not. A group of a single definition is a directly recursive definition.
+type Group struct {
+	members []resolver.Binding
+	cycle   []resolver.Binding
+}
+
+// Members returns the definitions of the group, in source order.
+func (g Group) Members() []resolver.Binding {
+	return append([]resolver.Binding{}, g.members...)
+}
+
+// Cycle returns a chain of references going from the first member back to
+// itself, e.g. even, odd, even.
+func (g Group) Cycle() []resolver.Binding {
+	return append([]resolver.Binding{}, g.cycle...)
+}
+
+// Detect finds the groups of recursive definitions of a resolved program, in
+// the order of their first member in the source text.
+func Detect(program parser.ASTNode, resolution resolver.Resolution) []Group {
+	g := newGraph(program, resolution)
+	groups := []Group{}
+	for _, component := range g.components() {
+		if g.recursive(component) {
+			groups = append(groups, Group{members: component, cycle: g.cycle(component)})
+		}
+	}
+
+	order := map[resolver.Binding]int{}
+	for i, b := range g.nodes {
+		order[b] = i
+	}
+	for i := 1; i < len(groups); i++ {
+		for j := i; j > 0 && order[groups[j].members[0]] < order[groups[j-1].members[0]]; j-- {
+			groups[j], groups[j-1] = groups[j-1], groups[j]
+		}
+	}
+	return groups
+}
+
+// Diagnostics turns recursive groups into errors, positioned on the first
+// member of each group.
+func Diagnostics(groups []Group) []diagnostic.Diagnostic {
+	diagnostics := []diagnostic.Diagnostic{}
+	for _, group := range groups {
+		first := group.members[0]
+		if len(group.members) == 1 {
+			diagnostics = append(diagnostics, diagnostic.Errorf(
+				first.Position(), "definition %q refers to itself", first.Name(),
+			).WithHint("apply a fixpoint combinator, or enable automatic fixpoint insertion"))
+			continue
+		}
+
+		chain := []string{}
+		for _, b := range group.cycle {
+			chain = append(chain, b.Name())
+		}
+		diagnostics = append(diagnostics, diagnostic.Errorf(
+			first.Position(), "definitions %s are mutually recursive: %s",
+			quoteNames(group.members), strings.Join(chain, " -> "),
+		).WithHint("apply a fixpoint combinator, or enable automatic fixpoint insertion"))
+	}
+	return diagnostics
+}
+
+// quoteNames renders the names of bindings as `"a", "b" and "c"`.
+func quoteNames(bindings []resolver.Binding) string {
+	quoted := []string{}
+	for _, b := range bindings {
+		quoted = append(quoted, `"`+b.Name()+`"`)
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " and " + quoted[len(quoted)-1]
+}
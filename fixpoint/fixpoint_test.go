@@ -0,0 +1,171 @@
+package fixpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+)
+
+func parse(t *testing.T, source string) (parser.ASTNode, resolver.Resolution) {
+	t.Helper()
+	program := parser.ParseString(source)
+	require.True(t, program.Success(), "%v", program.Error())
+	return program.Value(), resolver.Resolve(program.Value())
+}
+
+func names(bindings []resolver.Binding) []string {
+	result := []string{}
+	for _, b := range bindings {
+		result = append(result, b.Name())
+	}
+	return result
+}
+
+const evenOdd = `t := \x.\_.x
+f := \_.\y.y
+zero := \n.n (\_.f) t
+pred := \n.\f.\x.n (\g.\h.h (g f)) (\_.x) (\u.u)
+even := \n.zero n t (odd (pred n))
+odd := \n.zero n f (even (pred n))
+main := even pred`
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		members  [][]string
+		cycles   [][]string
+		messages []string
+	}{
+		{
+			"no recursion",
+			"i := \\x.x\nk := \\x.\\y.x\nmain := k i",
+			[][]string{},
+			[][]string{},
+			[]string{},
+		},
+		{
+			"direct recursion",
+			"i := \\x.x\nloop := \\x.loop x\nmain := loop i",
+			[][]string{{"loop"}},
+			[][]string{{"loop", "loop"}},
+			[]string{`2:0: error: definition "loop" refers to itself ` +
+				`(apply a fixpoint combinator, or enable automatic fixpoint insertion)`},
+		},
+		{
+			"mutual recursion",
+			evenOdd,
+			[][]string{{"even", "odd"}},
+			[][]string{{"even", "odd", "even"}},
+			[]string{`5:0: error: definitions "even" and "odd" are mutually recursive: even -> odd -> even ` +
+				`(apply a fixpoint combinator, or enable automatic fixpoint insertion)`},
+		},
+		{
+			"several groups",
+			"c := \\x.a x\na := \\x.b x\nb := \\x.c x\nd := \\x.d\nmain := a d",
+			[][]string{{"c", "a", "b"}, {"d"}},
+			[][]string{{"c", "a", "b", "c"}, {"d", "d"}},
+			[]string{
+				`1:0: error: definitions "c", "a" and "b" are mutually recursive: c -> a -> b -> c ` +
+					`(apply a fixpoint combinator, or enable automatic fixpoint insertion)`,
+				`4:0: error: definition "d" refers to itself ` +
+					`(apply a fixpoint combinator, or enable automatic fixpoint insertion)`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			groups := Detect(parse(t, testCase.input))
+			members, cycles, messages := [][]string{}, [][]string{}, []string{}
+			for _, group := range groups {
+				members = append(members, names(group.Members()))
+				cycles = append(cycles, names(group.Cycle()))
+			}
+			for _, d := range Diagnostics(groups) {
+				messages = append(messages, d.Error())
+			}
+			is.Equal(testCase.members, members)
+			is.Equal(testCase.cycles, cycles)
+			is.Equal(testCase.messages, messages)
+		})
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		combinator Combinator
+		expected   string
+	}{
+		{
+			"non recursive program is untouched",
+			"i := \\x.x\nmain := i i",
+			Y,
+			"i := \\x.x\nmain := i i",
+		},
+		{
+			"direct recursion with Y",
+			"loop := \\x.loop x\nmain := loop loop",
+			Y,
+			"loop := (\\f.(\\x.f (x x)) (\\x.f (x x))) (\\loop.\\x.loop x)\nmain := loop loop",
+		},
+		{
+			"direct recursion with Z",
+			"loop := \\x.loop x\nmain := loop loop",
+			Z,
+			"loop := (\\f.(\\x.f (\\v.x x v)) (\\x.f (\\v.x x v))) (\\loop.\\x.loop x)\nmain := loop loop",
+		},
+		{
+			"mutual recursion with Y",
+			"a := \\x.b x\nb := \\x.a x\nmain := a b",
+			Y,
+			"_a_b := (\\f.(\\x.f (x x)) (\\x.f (x x))) " +
+				"(\\t.(\\a.\\b.\\s.s (\\x.b x) (\\x.a x)) (t (\\a.\\_.a)) (t (\\_.\\b.b)))\n" +
+				"a := _a_b (\\a.\\_.a)\n" +
+				"b := _a_b (\\_.\\b.b)\n" +
+				"main := a b",
+		},
+		{
+			"mutual recursion with Z and clashing names",
+			"a := \\t.b t\nb := \\s.a s v\nv := \\x.x\nmain := a b",
+			Z,
+			"_a_b := (\\f.(\\x.f (\\v.x x v)) (\\x.f (\\v.x x v))) " +
+				"(\\t1.(\\a.\\b.\\s1.s1 (\\t.b t) (\\s.a s v)) (\\v1.t1 (\\a.\\_.a) v1) (\\v1.t1 (\\_.\\b.b) v1))\n" +
+				"a := _a_b (\\a.\\_.a)\n" +
+				"b := _a_b (\\_.\\b.b)\n" +
+				"v := \\x.x\n" +
+				"main := a b",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			program, resolution := parse(t, testCase.input)
+			rewritten := Rewrite(program, resolution, testCase.combinator)
+			expected, _ := parse(t, testCase.expected)
+			is.Equal(expected.String(), rewritten.String())
+
+			// The rewritten program is free of recursion and of scoping errors
+			resolved := resolver.Resolve(expected)
+			is.Empty(resolved.Diagnostics())
+			is.Empty(Detect(expected, resolved))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package fixpoint
+
+import (
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+)
+
+// graph is the dependency graph of the top-level definitions of a program:
+// an edge goes from a definition to every definition its body refers to.
+type graph struct {
+	nodes  []resolver.Binding
+	bodies map[resolver.Binding]parser.ASTNode
+	edges  map[resolver.Binding][]resolver.Binding
+}
+
+// newGraph builds the dependency graph of a resolved program. Duplicate
+// definitions, already reported by the resolver, are left out.
+func newGraph(program parser.ASTNode, resolution resolver.Resolution) graph {
+	g := graph{
+		bodies: map[resolver.Binding]parser.ASTNode{},
+		edges:  map[resolver.Binding][]resolver.Binding{},
+	}
+	for _, statement := range program.Children() {
+		if statement.NodeType() != lexer.ASSIGN {
+			continue
+		}
+		name := statement.Children()[0].Token()
+		b := resolution.Lookup(name.Position())
+		if b.Nothing() || b.Value().Position() != name.Position() {
+			continue
+		}
+		g.nodes = append(g.nodes, b.Value())
+		g.bodies[b.Value()] = statement.Children()[1]
+		g.edges[b.Value()] = resolution.DefinitionsUsedBy(statement.Children()[1])
+	}
+	return g
+}
+
+// components computes the strongly connected components of the graph with
+// Tarjan's algorithm. Components are returned in reverse topological order
+// (dependencies first), each listing its members in source order.
+func (g graph) components() [][]resolver.Binding {
+	index := map[resolver.Binding]int{}
+	low := map[resolver.Binding]int{}
+	onStack := map[resolver.Binding]bool{}
+	stack := []resolver.Binding{}
+	components := [][]resolver.Binding{}
+
+	var connect func(resolver.Binding)
+	connect = func(v resolver.Binding) {
+		index[v] = len(index)
+		low[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range g.edges[v] {
+			if _, visited := index[w]; !visited {
+				connect(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+
+		if low[v] != index[v] {
+			return
+		}
+		members := map[resolver.Binding]bool{}
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			members[w] = true
+			if w == v {
+				break
+			}
+		}
+		components = append(components, g.inSourceOrder(members))
+	}
+
+	for _, v := range g.nodes {
+		if _, visited := index[v]; !visited {
+			connect(v)
+		}
+	}
+	return components
+}
+
+// recursive tells whether a strongly connected component denotes recursion:
+// either several definitions depending on each other, or a single definition
+// depending on itself.
+func (g graph) recursive(component []resolver.Binding) bool {
+	if len(component) > 1 {
+		return true
+	}
+	for _, w := range g.edges[component[0]] {
+		if w == component[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// cycle returns a path of definitions starting and ending with the first
+// member of a recursive component, e.g. even, odd, even. It is used to explain
+// the recursion to the user.
+func (g graph) cycle(component []resolver.Binding) []resolver.Binding {
+	members := map[resolver.Binding]bool{}
+	for _, b := range component {
+		members[b] = true
+	}
+
+	start := component[0]
+	visited := map[resolver.Binding]bool{}
+	var search func(resolver.Binding, []resolver.Binding) []resolver.Binding
+	search = func(v resolver.Binding, path []resolver.Binding) []resolver.Binding {
+		for _, w := range g.edges[v] {
+			if w == start {
+				return append(path, w)
+			}
+			if members[w] && !visited[w] {
+				visited[w] = true
+				if found := search(w, append(path, w)); found != nil {
+					return found
+				}
+			}
+		}
+		return nil
+	}
+	return search(start, []resolver.Binding{start})
+}
+
+// inSourceOrder lists the members of a set of definitions in source order.
+func (g graph) inSourceOrder(members map[resolver.Binding]bool) []resolver.Binding {
+	ordered := []resolver.Binding{}
+	for _, b := range g.nodes {
+		if members[b] {
+			ordered = append(ordered, b)
+		}
+	}
+	return ordered
+}
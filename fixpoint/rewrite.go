@@ -0,0 +1,162 @@
+package fixpoint
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+)
+
+// Rewrite returns a copy of the program in which every recursive definition
+// has been made non-recursive with the given fixpoint combinator. Other
+// statements are left untouched.
+//
+// The synthesized nodes are all positioned on the definition they stem from,
+// so that they can be traced back to the source text. As a consequence, their
+// positions are not unique, and the rewritten program is meant to be
+// evaluated or compiled rather than resolved again.
+func Rewrite(program parser.ASTNode, resolution resolver.Resolution, combinator Combinator) parser.ASTNode {
+	groups := Detect(program, resolution)
+	if len(groups) == 0 {
+		return program
+	}
+
+	g := newGraph(program, resolution)
+	taken := identifiers(program)
+	replacements := map[lexer.Position][]parser.ASTNode{}
+	for _, group := range groups {
+		// The helper of a tupled group, if any, is emitted along with the
+		// first member, so that every member stays at its original place.
+		statements := rewriteGroup(group, g, combinator, taken)
+		offset := len(statements) - len(group.members)
+		for i, b := range group.members {
+			replacements[b.Position()] = statements[i+offset : i+offset+1]
+		}
+		replacements[group.members[0].Position()] = statements[:offset+1]
+	}
+
+	statements := []parser.ASTNode{}
+	for _, statement := range program.Children() {
+		if statement.NodeType() == lexer.ASSIGN {
+			if replacement, ok := replacements[statement.Children()[0].Token().Position()]; ok {
+				statements = append(statements, replacement...)
+				continue
+			}
+		}
+		statements = append(statements, statement)
+	}
+	return parser.NewProgram(statements...)
+}
+
+// rewriteGroup produces the statements replacing a recursive group. For a
+// single definition, it is the definition itself. For mutually recursive
+// definitions, the helper definition comes first, followed by the members in
+// source order.
+func rewriteGroup(group Group, g graph, combinator Combinator, taken map[string]bool) []parser.ASTNode {
+	position := group.members[0].Position()
+	ident := func(name string) parser.ASTNode { return parser.NewIdent(name, position) }
+
+	if len(group.members) == 1 {
+		f := group.members[0]
+		return []parser.ASTNode{parser.NewAssign(
+			ident(f.Name()),
+			parser.NewApply(fix(combinator, position), parser.NewLambda(ident(f.Name()), g.bodies[f])),
+		)}
+	}
+
+	names := []string{}
+	for _, b := range group.members {
+		names = append(names, b.Name())
+	}
+	helper := fresh("_"+strings.Join(names, "_"), taken)
+	t, s, v := fresh("t", taken), fresh("s", taken), fresh("v", taken)
+
+	tuple := ident(s)
+	for _, b := range group.members {
+		tuple = parser.NewApply(tuple, g.bodies[b])
+	}
+	tuple = parser.NewLambda(ident(s), tuple)
+	for i := len(names) - 1; i >= 0; i-- {
+		tuple = parser.NewLambda(ident(names[i]), tuple)
+	}
+
+	knot := tuple
+	for i := range names {
+		var arg parser.ASTNode
+		if combinator == Z {
+			arg = parser.NewLambda(ident(v), parser.NewApply(ident(t), selector(names, i, position), ident(v)))
+		} else {
+			arg = parser.NewApply(ident(t), selector(names, i, position))
+		}
+		knot = parser.NewApply(knot, arg)
+	}
+
+	statements := []parser.ASTNode{parser.NewAssign(
+		ident(helper),
+		parser.NewApply(fix(combinator, position), parser.NewLambda(ident(t), knot)),
+	)}
+	for i, name := range names {
+		statements = append(statements, parser.NewAssign(
+			parser.NewIdent(name, group.members[i].Position()),
+			parser.NewApply(ident(helper), selector(names, i, position)),
+		))
+	}
+	return statements
+}
+
+// selector builds the projection of the i-th component of a Church tuple of
+// the given arity: `\_. ... \name. ... \_.name`.
+func selector(names []string, i int, position lexer.Position) parser.ASTNode {
+	body := parser.NewIdent(names[i], position)
+	for j := len(names) - 1; j >= 0; j-- {
+		param := "_"
+		if j == i {
+			param = names[i]
+		}
+		body = parser.NewLambda(parser.NewIdent(param, position), body)
+	}
+	return body
+}
+
+// fix builds the requested fixpoint combinator. Both combinators are closed
+// terms, hence cannot capture any name of the program.
+func fix(combinator Combinator, position lexer.Position) parser.ASTNode {
+	ident := func(name string) parser.ASTNode { return parser.NewIdent(name, position) }
+
+	// x x, or \v.x x v for the strict variant
+	selfApplication := parser.NewApply(ident("x"), ident("x"))
+	if combinator == Z {
+		selfApplication = parser.NewLambda(ident("v"), parser.NewApply(selfApplication, ident("v")))
+	}
+	half := parser.NewLambda(ident("x"), parser.NewApply(ident("f"), selfApplication))
+	return parser.NewLambda(ident("f"), parser.NewApply(half, half))
+}
+
+// identifiers collects every identifier occurring in a program.
+func identifiers(node parser.ASTNode) map[string]bool {
+	names := map[string]bool{}
+	var walk func(parser.ASTNode)
+	walk = func(node parser.ASTNode) {
+		if node.NodeType() == lexer.IDENT {
+			names[node.Token().Literal().String()] = true
+		}
+		for _, child := range node.Children() {
+			walk(child)
+		}
+	}
+	walk(node)
+	return names
+}
+
+// fresh returns a name derived from base that does not belong to taken, and
+// records it as taken.
+func fresh(base string, taken map[string]bool) string {
+	name := base
+	for i := 1; taken[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	taken[name] = true
+	return name
+}
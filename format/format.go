@@ -0,0 +1,249 @@
+// Package format implements the canonical formatting of λ.c source text, as
+// applied by "lambdac fmt".
+//
+// The canonical form is what the term package prints, one statement per line:
+//
+//   - Abstractions are written \x.body, applications with a single space
+//     between function and argument, and parentheses only where the grammar
+//     requires them.
+//   - Operators are surrounded by single spaces: i := \x.x, io | "std/io".
+//   - The operators of consecutive definitions, and of consecutive imports,
+//     are aligned. Comment lines do not break the alignment, blank lines do.
+//   - Runs of blank lines are reduced to a single one, and leading and
+//     trailing blank lines removed.
+//
+// Comments are preserved. Comments ending the line of a statement stay there;
+// comments within a statement spanning several lines are moved before it,
+// since the statement is joined on a single line.
+//
+// Formatting is idempotent: formatting canonical source text leaves it
+// unchanged.
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Source returns the canonical form of a source text, or the error that
+// prevents parsing it.
+func Source(source string) monad.Result[string, error] {
+	tokens := lexer.New().WithContent(source).Tokens()
+	program := parser.Parse(parser.NewState(tokens))
+	if program.Failure() {
+		return monad.Fail[string, error](program.Error())
+	}
+
+	items := segment(tokens)
+	statements := program.Value().Children()
+	next := 0
+	for i := range items {
+		if items[i].comment {
+			continue
+		}
+		if next >= len(statements) {
+			return monad.Fail[string, error](fmt.Errorf("statements and source lines do not match"))
+		}
+		line := render(statements[next])
+		if line.Failure() {
+			return monad.Fail[string, error](line.Error())
+		}
+		items[i].line = line.Value()
+		next++
+	}
+	return monad.Succeed[string, error](layout(items))
+}
+
+// item is a line of the canonical form: a statement, possibly followed by a
+// comment, or a comment alone.
+type item struct {
+	comment  bool
+	text     string
+	line     statementLine
+	trailing string
+	blank    bool
+}
+
+// statementLine is a statement rendered in canonical form, split around its
+// operator so that consecutive operators can be aligned.
+type statementLine struct {
+	kind     lexer.TokenType
+	left     string
+	operator string
+	right    string
+}
+
+// segment splits the tokens into items. Statements end with line breaks
+// outside parentheses; the comments met within a statement become comment
+// items preceding it, except for the comment ending its last line.
+func segment(tokens []lexer.Token) []item {
+	items := []item{}
+	lastRow := 0
+	var current []lexer.Token
+	var comments []lexer.Token
+	depth := 0
+
+	flush := func() {
+		if len(current) == 0 {
+			for _, c := range comments {
+				items = append(items, commentItem(c, c.Position().Row()-lastRow > 1))
+				lastRow = c.Position().Row()
+			}
+			comments = nil
+			return
+		}
+
+		first, last := current[0].Position().Row(), current[len(current)-1].Position().Row()
+		if len(comments) > 0 && comments[0].Position().Row() < first {
+			first = comments[0].Position().Row()
+		}
+		blank := first-lastRow > 1 && len(items) > 0
+		statement := item{}
+		for _, c := range comments {
+			if c.Position().Row() == last && c.Position().Col() > current[len(current)-1].Position().Col() {
+				statement.trailing = commentText(c)
+				continue
+			}
+			items = append(items, commentItem(c, blank))
+			blank = false
+		}
+		statement.blank = blank
+		items = append(items, statement)
+		lastRow = last
+		current, comments = nil, nil
+	}
+
+	for _, token := range tokens {
+		switch token.Type() {
+		case lexer.COMMENT:
+			comments = append(comments, token)
+		case lexer.EOL, lexer.EOF:
+			if depth == 0 {
+				flush()
+			}
+		case lexer.LPAREN:
+			depth++
+			current = append(current, token)
+		case lexer.RPAREN:
+			depth--
+			current = append(current, token)
+		default:
+			current = append(current, token)
+		}
+	}
+	flush()
+
+	if len(items) > 0 {
+		items[0].blank = false
+	}
+	return items
+}
+
+// commentItem returns the item of a comment on its own line.
+func commentItem(c lexer.Token, blank bool) item {
+	return item{comment: true, text: commentText(c), blank: blank}
+}
+
+// commentText renders a comment without its trailing blanks.
+func commentText(c lexer.Token) string {
+	return strings.TrimRight("--"+c.Literal().String(), " \t")
+}
+
+// render renders a statement in canonical form.
+func render(statement parser.ASTNode) monad.Result[statementLine, error] {
+	children := statement.Children()
+	switch statement.NodeType() {
+	case lexer.MODULE:
+		path := strings.ReplaceAll(children[1].Token().Literal().String(), `"`, `\"`)
+		return monad.Succeed[statementLine, error](statementLine{
+			kind:     lexer.MODULE,
+			left:     children[0].Token().Literal().String(),
+			operator: "|",
+			right:    `"` + path + `"`,
+		})
+	case lexer.ASSIGN:
+		t := term.FromSource(children[1])
+		if t.Failure() {
+			return monad.Fail[statementLine, error](t.Error())
+		}
+		return monad.Succeed[statementLine, error](statementLine{
+			kind:     lexer.ASSIGN,
+			left:     children[0].Token().Literal().String(),
+			operator: ":=",
+			right:    t.Value().String(),
+		})
+	default:
+		t := term.FromSource(statement)
+		if t.Failure() {
+			return monad.Fail[statementLine, error](t.Error())
+		}
+		return monad.Succeed[statementLine, error](statementLine{kind: lexer.APPLY, left: t.Value().String()})
+	}
+}
+
+// layout prints the items, aligning the operators of the blocks of consecutive
+// definitions or imports.
+func layout(items []item) string {
+	widths := alignments(items)
+
+	var b strings.Builder
+	for i, it := range items {
+		if it.blank {
+			b.WriteString("\n")
+		}
+		if it.comment {
+			b.WriteString(it.text + "\n")
+			continue
+		}
+
+		line := it.line.left
+		if it.line.operator != "" {
+			padding := widths[i] - utf8.RuneCountInString(it.line.left)
+			line += strings.Repeat(" ", padding) + " " + it.line.operator + " " + it.line.right
+		}
+		if it.trailing != "" {
+			line += " " + it.trailing
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// alignments returns, for each statement item, the width its left-hand side
+// is padded to: the widest left-hand side of its block.
+func alignments(items []item) []int {
+	widths := make([]int, len(items))
+	block := []int{}
+	closeBlock := func() {
+		width := 0
+		for _, i := range block {
+			width = max(width, utf8.RuneCountInString(items[i].line.left))
+		}
+		for _, i := range block {
+			widths[i] = width
+		}
+		block = block[:0]
+	}
+
+	for i, it := range items {
+		if it.blank {
+			closeBlock()
+		}
+		if it.comment {
+			continue
+		}
+		if len(block) > 0 && items[block[0]].line.kind != it.line.kind {
+			closeBlock()
+		}
+		block = append(block, i)
+	}
+	closeBlock()
+	return widths
+}
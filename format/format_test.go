@@ -0,0 +1,75 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"spacing", `i:=\x .  x`, "i := \\x.x\n"},
+		{"parentheses", `y := \f.(\x.f(x x))(\x.f(x x))`, "y := \\f.(\\x.f (x x)) (\\x.f (x x))\n"},
+		{"redundant parentheses", `((f) (a)) ((b c))`, "f a (b c)\n"},
+		{"imports", "io|\"std/io\"\nlist   |  \"std/list\"", "io   | \"std/io\"\nlist | \"std/list\"\n"},
+		{"escaped path", `q | "a\"b"`, "q | \"a\\\"b\"\n"},
+		{
+			"alignment",
+			"i := \\x.x\nconst := \\x.\\y.x\n\nflip := \\f.\\x.\\y.f y x\ns := \\x.\\y.\\z.x z (y z)\n",
+			"i     := \\x.x\nconst := \\x.\\y.x\n\nflip := \\f.\\x.\\y.f y x\ns    := \\x.\\y.\\z.x z (y z)\n",
+		},
+		{
+			"alignment per kind",
+			"io | \"std/io\"\nid := \\x.x\nlonger := id\nid id",
+			"io | \"std/io\"\nid     := \\x.x\nlonger := id\nid id\n",
+		},
+		{
+			"comments",
+			"-- Identity Function\ni := \\x.x   -- the simplest one   \n-- Constant Function\nconst := \\x.\\y.x\n--end",
+			"-- Identity Function\ni     := \\x.x -- the simplest one\n-- Constant Function\nconst := \\x.\\y.x\n--end\n",
+		},
+		{
+			"blank lines",
+			"\n\n-- a\n\n\n\ni := \\x.x\n\n  \n\nk := \\x.\\y.x\n\n\n-- z\n\n",
+			"-- a\n\ni := \\x.x\n\nk := \\x.\\y.x\n\n-- z\n",
+		},
+		{
+			"multiline statement",
+			"x := (f -- the function\n  a -- its argument\n)\ny := x",
+			"-- the function\n-- its argument\nx := f a\ny := x\n",
+		},
+		{"namespaces", "r := io -> read  x", "r := io->read x\n"},
+		{"strings", `greeting:=(("hé\"\n"))  x`, "greeting := \"hé\\\"\\n\" x\n"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			result := Source(testCase.input)
+			is.True(result.Success(), "%v", result.Error())
+			is.Equal(testCase.expected, result.Value())
+
+			again := Source(result.Value())
+			is.True(again.Success(), "%v", again.Error())
+			is.Equal(result.Value(), again.Value())
+		})
+	}
+}
+
+func TestSourceError(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	result := Source("i := \\x.")
+	is.Equal("1:8: error: unexpected end of input, expected an expression", result.Error().Error())
+}
@@ -0,0 +1,144 @@
+package format_test
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+
+	"github.com/denisdubochevalier/lambdac/format"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/term"
+	"github.com/denisdubochevalier/lambdac/term/termtest"
+)
+
+// untidy writes t with random spacing, redundant parentheses, and line breaks
+// and comments where parentheses allow them.
+func untidy(r *rand.Rand, t term.Term, nested bool) string {
+	space := func(required bool) string {
+		choices := []string{" ", "  ", "\t"}
+		if !required {
+			choices = append(choices, "")
+		}
+		if nested {
+			choices = append(choices, "\n  ", " -- note\n")
+		}
+		return choices[r.Intn(len(choices))]
+	}
+	parenthesize := func(s string) string {
+		return "(" + space(false) + s + space(false) + ")"
+	}
+
+	var s string
+	switch t := t.(type) {
+	case term.Var:
+		s = t.Name()
+	case term.Abs:
+		s = `\` + space(false) + t.Param() + space(false) + "." + space(false) + untidy(r, t.Body(), nested)
+	case term.App:
+		fun := untidy(r, t.Fun(), nested)
+		if _, ok := t.Fun().(term.Abs); ok {
+			fun = "(" + untidy(r, t.Fun(), true) + ")"
+		}
+		if _, ok := t.Arg().(term.Var); ok {
+			s = fun + space(true) + untidy(r, t.Arg(), nested)
+		} else {
+			s = fun + space(false) + "(" + untidy(r, t.Arg(), true) + ")"
+		}
+	}
+	if r.Intn(4) == 0 {
+		nested = true
+		s = parenthesize(s)
+	}
+	return s
+}
+
+// untidyProgram writes a random program made of the given terms, with random
+// imports, comments and blank lines.
+func untidyProgram(terms []term.Term, seed int64) string {
+	r := rand.New(rand.NewSource(seed))
+	names := []string{"a", "bb", "main", "longer_name"}
+	comments := []string{"--", "-- doc", "--tight", "-- with trailing blanks  "}
+	pick := func(choices []string) string { return choices[r.Intn(len(choices))] }
+
+	var b strings.Builder
+	for _, t := range terms {
+		if r.Intn(4) == 0 {
+			b.WriteString(strings.Repeat("\n", r.Intn(3)))
+		}
+		if r.Intn(4) == 0 {
+			b.WriteString(pick(comments) + "\n")
+		}
+		switch r.Intn(4) {
+		case 0:
+			b.WriteString(pick(names) + " | \"std/" + pick(names) + "\"")
+		case 1:
+			b.WriteString(untidy(r, t, false))
+		default:
+			b.WriteString(pick(names) + pick([]string{" ", ""}) + ":=" + " " + untidy(r, t, false))
+		}
+		if r.Intn(4) == 0 {
+			b.WriteString(" " + pick(comments))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// comments returns the comments of a source text, without trailing blanks.
+func comments(source string) []string {
+	result := []string{}
+	for _, token := range lexer.New().WithContent(source).Tokens() {
+		if token.Type() == lexer.COMMENT {
+			result = append(result, strings.TrimRight(token.Literal().String(), " \t"))
+		}
+	}
+	return result
+}
+
+func TestSourceProperties(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 500
+	properties := gopter.NewProperties(parameters)
+
+	programs := gopter.CombineGens(gen.SliceOf(termtest.Term(4), reflect.TypeOf((*term.Term)(nil)).Elem()), gen.Int64()).Map(
+		func(values []any) string {
+			return untidyProgram(values[0].([]term.Term), values[1].(int64))
+		},
+	)
+
+	properties.Property("formatting is idempotent", prop.ForAll(
+		func(source string) bool {
+			formatted := format.Source(source)
+			if formatted.Failure() {
+				return false
+			}
+			again := format.Source(formatted.Value())
+			return again.Success() && again.Value() == formatted.Value()
+		},
+		programs,
+	))
+
+	properties.Property("formatting preserves the syntax tree", prop.ForAll(
+		func(source string) bool {
+			before := parser.ParseString(source)
+			after := parser.ParseString(format.Source(source).Value())
+			return before.Success() && after.Success() && before.Value().String() == after.Value().String()
+		},
+		programs,
+	))
+
+	properties.Property("formatting preserves the comments", prop.ForAll(
+		func(source string) bool {
+			return strings.Join(comments(source), "\n") == strings.Join(comments(format.Source(source).Value()), "\n")
+		},
+		programs,
+	))
+
+	properties.TestingRun(t)
+}
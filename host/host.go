@@ -0,0 +1,174 @@
+// Package host lets applications embedding λ.c provide programs with Go
+// functions.
+//
+// Overview:
+//
+// A Registry gathers host modules, each a set of primitives named like
+// top-level definitions. Programs import host modules like any other, by
+// their import path, and call their primitives like functions:
+//
+//	os | "host/os"
+//
+//	main := os->getenv "HOME"
+//
+// The application hands the Registry to the module loader, so that the
+// imports resolve, and its primitives to the evaluator, which applies them:
+//
+//	registry := host.NewRegistry().
+//		WithModule("host/os", host.NewModule().WithFunc("getenv", os.Getenv))
+//	graph := module.NewLoader(roots...).WithHost(registry).Load(file, source)
+//	// link the graph and find the entry point, then
+//	result := eval.NewNormalOrder().WithPrimitives(registry.Primitives()).Evaluate(ctx, entry)
+//
+// Host modules take precedence over the files of the search path. By
+// convention, their import paths start with host/.
+//
+// Marshaling:
+//
+// Func turns a Go function into a Primitive, converting its arguments from
+// their Church encodings, and its result back:
+//
+//   - int values are Church numerals, and must not be negative;
+//   - bool values are Church booleans, \t.\f.t and \t.\f.f;
+//   - string values are Church lists of the numerals of their code points,
+//     as string literals are;
+//   - slices are Church lists of their elements, \c.\n.c a (c b n);
+//   - term.Term values are passed and returned as they are.
+//
+// The function may take a context.Context first, which is the one of the
+// evaluation, and may return an error after its result.
+//
+// Strictness:
+//
+// A primitive applies once it is given all its arguments. Arguments of Go
+// types other than term.Term are strict: they are reduced to normal form, in
+// normal order, before being converted. term.Term arguments are lazy, passed
+// with their redexes and possibly never evaluated, unless declared strict
+// with Primitive.WithStrict. A primitive whose strict arguments have free
+// variables, such as one applied to the parameter of an abstraction being
+// normalized, is stuck: it stays as it is until substitution closes them.
+//
+// Errors:
+//
+// Errors returned by the function, and arguments that are not the encoding
+// of a value of their Go type, make the evaluation fail with an error
+// naming the primitive, which wraps the error of the function if any.
+package host
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Module is a host module: named primitives. It follows the immutable
+// builder pattern: With returns an updated copy.
+type Module struct {
+	names      []string
+	primitives map[string]Primitive
+}
+
+// NewModule returns a Module without primitives.
+func NewModule() Module {
+	return Module{primitives: map[string]Primitive{}}
+}
+
+// With returns a copy of the module defining name as p, replacing any
+// previous primitive of the same name. It panics if name is not an
+// identifier, or is private (see resolver.IsPrivate).
+func (m Module) With(name string, p Primitive) Module {
+	if !isIdentifier(name) {
+		panic(fmt.Sprintf("host: %q is not an identifier", name))
+	}
+	if resolver.IsPrivate(name) {
+		panic(fmt.Sprintf("host: %q is private, and could not be imported", name))
+	}
+	primitives := make(map[string]Primitive, len(m.primitives)+1)
+	for n, primitive := range m.primitives {
+		primitives[n] = primitive
+	}
+	names := append([]string{}, m.names...)
+	if _, ok := primitives[name]; !ok {
+		names = append(names, name)
+	}
+	primitives[name] = p
+	return Module{names: names, primitives: primitives}
+}
+
+// WithFunc returns a copy of the module defining name as Func(fn).
+func (m Module) WithFunc(name string, fn any) Module {
+	return m.With(name, Func(fn))
+}
+
+// Names returns the names of the primitives of the module, in order of
+// first definition.
+func (m Module) Names() []string {
+	return append([]string{}, m.names...)
+}
+
+// isIdentifier tells whether name is a single λ.c identifier.
+func isIdentifier(name string) bool {
+	program := parser.ParseString(name)
+	if program.Failure() {
+		return false
+	}
+	// The identifier, then EOF
+	statements := program.Value().Children()
+	return len(statements) == 2 && statements[0].NodeType() == lexer.IDENT &&
+		statements[0].Token().Literal().String() == name
+}
+
+// Registry gathers the host modules of an application, by import path. It
+// implements module.Host.
+type Registry struct {
+	modules map[string]Module
+}
+
+// NewRegistry returns a Registry without modules.
+func NewRegistry() Registry {
+	return Registry{modules: map[string]Module{}}
+}
+
+// WithModule returns a copy of the registry providing m under the given
+// import path, replacing any previous module of the same path. It panics if
+// the import path is not valid, such as "host/os" is.
+func (r Registry) WithModule(importPath string, m Module) Registry {
+	if !fs.ValidPath(importPath) || importPath == "." {
+		panic(fmt.Sprintf("host: invalid import path %q", importPath))
+	}
+	modules := make(map[string]Module, len(r.modules)+1)
+	for p, module := range r.modules {
+		modules[p] = module
+	}
+	modules[importPath] = m
+	return Registry{modules: modules}
+}
+
+// Definitions returns the names of the primitives of the module of the given
+// import path, if the registry provides it.
+func (r Registry) Definitions(importPath string) monad.Maybe[[]string] {
+	if m, ok := r.modules[importPath]; ok {
+		return monad.Some(m.Names())
+	}
+	return monad.None[[]string]()
+}
+
+// Primitives returns the primitives of every module, for evaluators to
+// apply, under the names they bear in linked programs, such as
+// host/os->getenv.
+func (r Registry) Primitives() eval.Primitives {
+	primitives := eval.Primitives{}
+	for importPath, m := range r.modules {
+		for name, p := range m.primitives {
+			primitives[term.Qualify(importPath, name)] = p
+		}
+	}
+	return primitives
+}
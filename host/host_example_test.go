@@ -0,0 +1,46 @@
+package host_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/host"
+	"github.com/denisdubochevalier/lambdac/module"
+)
+
+func Example() {
+	registry := host.NewRegistry().WithModule("host/strings", host.NewModule().
+		WithFunc("upper", strings.ToUpper).
+		WithFunc("repeat", strings.Repeat))
+
+	source := `s | "host/strings"
+
+main := s->upper (s->repeat "ab" (\f.\x.f (f x)))
+`
+	graph := module.NewLoader(module.Stdlib()).WithHost(registry).Load("main.lc", source)
+	if graph.Failure() {
+		fmt.Println(graph.Error())
+		return
+	}
+	program := graph.Value().Link()
+	if program.Failure() {
+		fmt.Println(program.Error())
+		return
+	}
+	entry := program.Value().Entry("main")
+	if entry.Failure() {
+		fmt.Println(entry.Error())
+		return
+	}
+
+	evaluator := eval.NewNormalOrder().WithPrimitives(registry.Primitives())
+	result := evaluator.Evaluate(context.Background(), entry.Value())
+	if result.Failure() {
+		fmt.Println(result.Error())
+		return
+	}
+	fmt.Println(eval.ReadString(result.Value()).Value())
+	// Output: ABAB
+}
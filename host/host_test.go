@@ -0,0 +1,250 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/module"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+type contextKey struct{}
+
+var errNotFound = errors.New("not found")
+
+// registry provides host/test, whose primitives exercise every supported
+// type.
+var registry = NewRegistry().WithModule("host/test", NewModule().
+	WithFunc("greet", func(name string) string { return "hi " + name }).
+	WithFunc("add", func(m, n int) int { return m + n }).
+	WithFunc("not", func(b bool) bool { return !b }).
+	WithFunc("sum", func(ns []int) int {
+		total := 0
+		for _, n := range ns {
+			total += n
+		}
+		return total
+	}).
+	WithFunc("words", strings.Fields).
+	WithFunc("choose", func(b bool, x, y term.Term) term.Term {
+		if b {
+			return x
+		}
+		return y
+	}).
+	WithFunc("hold", func(t term.Term) []term.Term { return []term.Term{t} }).
+	With("quote", Func(func(t term.Term) []term.Term { return []term.Term{t} }).WithStrict(0)).
+	WithFunc("lookup", func(ctx context.Context, key string) (string, error) {
+		if value, ok := ctx.Value(contextKey{}).(string); ok && key == "user" {
+			return value, nil
+		}
+		return "", errNotFound
+	}).
+	WithFunc("negate", func(n int) int { return -n }).
+	WithFunc("version", func() int { return 1 }))
+
+// evaluate loads and links source, then evaluates its main definition with
+// the primitives of the registry.
+func evaluate(t *testing.T, e eval.Evaluator, source string) (term.Term, error) {
+	t.Helper()
+	g := module.NewLoader(module.Stdlib()).WithHost(registry).Load("main.lc", source)
+	require.True(t, g.Success(), "%v", g.Error())
+	require.Empty(t, g.Value().Root().Resolution().Diagnostics())
+	program := g.Value().Link()
+	require.True(t, program.Success(), "%v", program.Error())
+	entry := program.Value().Entry("main")
+	require.True(t, entry.Success(), "%v", entry.Error())
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "ada")
+	result := e.Evaluate(ctx, entry.Value())
+	return result.Value(), result.Error()
+}
+
+// show renders strings, numerals and lists legibly, and other terms as
+// they are. The empty string, zero and false are all rendered "".
+func show(t term.Term) string {
+	if s := eval.ReadString(t); s.Just() {
+		return strconv.Quote(s.Value())
+	}
+	if n := eval.ReadNumeral(t); n.Just() {
+		return strconv.Itoa(n.Value())
+	}
+	if l := eval.ReadList(t); l.Just() {
+		shown := make([]string, 0, len(l.Value()))
+		for _, e := range l.Value() {
+			shown = append(shown, show(e))
+		}
+		return "[" + strings.Join(shown, " ") + "]"
+	}
+	return t.String()
+}
+
+func TestPrimitives(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"string", `main := t->greet "ada"`, `"hi ada"`},
+		{"number", `n | "std/nat"` + "\n" + `main := t->add (n->succ n->one) n->one`, "3"},
+		{"boolean", `b | "std/bool"` + "\n" + `main := t->not (b->not b->true)`, `\t.\f.t`},
+		{"list", `l | "std/list"` + "\n" + `main := t->sum (l->cons (\f.\x.f x) (l->cons (\f.\x.f (f x)) l->nil))`, "3"},
+		{"list result", `l | "std/list"` + "\n" + `main := l->head "" (l->tail (t->words "ab  c d"))`, `"c"`},
+		{"lazy", `main := t->choose (\t.\f.t) (t->greet "") ((\x.x x) (\x.x x))`, `"hi "`},
+		{"context", `main := t->lookup "user"`, `"ada"`},
+		{"nested", `main := t->greet (t->greet "x")`, `"hi hi x"`},
+		{"constant", `main := t->add t->version t->version`, "2"},
+		{"stuck", `main := \x.t->greet x`, `\x.host/test->greet x`},
+		{"partial", `main := t->add (\f.\x.f x)`, `host/test->add (\f.\x.f x)`},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			source := "t | \"host/test\"\n" + testCase.source
+			result, err := evaluate(t, eval.NewNormalOrder().WithPrimitives(registry.Primitives()), source)
+			is.NoError(err)
+			is.Equal(testCase.expected, show(result))
+		})
+	}
+}
+
+func TestPrimitivesStrategies(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	primitives := registry.Primitives()
+	for _, e := range []eval.Evaluator{
+		eval.NewNormalOrder().WithPrimitives(primitives),
+		eval.NewApplicativeOrder().WithPrimitives(primitives),
+		eval.NewCallByValue().WithPrimitives(primitives),
+		eval.NewCallByName().WithPrimitives(primitives),
+		eval.NewCallByNeed().WithPrimitives(primitives),
+		eval.NewHeadReduction().WithPrimitives(primitives),
+	} {
+		result, err := evaluate(t, e, "t | \"host/test\"\nmain := (\\g.t->greet (g \"x\")) t->greet")
+		is.NoError(err)
+		is.Equal(`"hi hi x"`, show(result), "%T", e)
+	}
+
+	var events []eval.Event
+	tracer := eval.TracerFunc(func(e eval.Event) error {
+		events = append(events, e)
+		return nil
+	})
+	_, err := evaluate(t, eval.NewNormalOrder().WithPrimitives(primitives).WithTracer(tracer),
+		"t | \"host/test\"\nmain := \\y.t->add ((\\x.x) t->version) y")
+	is.NoError(err)
+	is.Len(events, 2)
+	is.Equal(`\y.host/test->add [[((\x.x) host/test->version)]] y`, events[0].Highlight("[[", "]]"))
+	is.Equal(`\y.host/test->add [[(host/test->version)]] y`, events[1].Highlight("[[", "]]"))
+
+	// Call by name stops at the list, leaving its element as it is, unless
+	// the primitive is strict
+	e := eval.NewCallByName().WithPrimitives(primitives)
+	held, err := evaluate(t, e, "t | \"host/test\"\nmain := t->hold ((\\x.x) (\\y.y))")
+	is.NoError(err)
+	is.IsType(term.App{}, eval.ReadList(held).Value()[0])
+	quoted, err := evaluate(t, e, "t | \"host/test\"\nmain := t->quote ((\\x.x) (\\y.y))")
+	is.NoError(err)
+	is.IsType(term.Abs{}, eval.ReadList(quoted).Value()[0])
+}
+
+func TestPrimitiveErrors(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	e := eval.NewNormalOrder().WithPrimitives(registry.Primitives())
+	_, err := evaluate(t, e, "t | \"host/test\"\nmain := t->lookup \"nobody\"")
+	is.ErrorIs(err, errNotFound)
+	is.EqualError(err, "host/test->lookup: not found")
+
+	_, err = evaluate(t, e, "t | \"host/test\"\nmain := t->greet (\\x.x)")
+	is.EqualError(err, "host/test->greet: argument 1 is not a string")
+	_, err = evaluate(t, e, "t | \"host/test\"\nmain := t->sum (t->words \"a\")")
+	is.EqualError(err, "host/test->sum: argument 1 is not a list of numbers")
+	_, err = evaluate(t, e, "t | \"host/test\"\nmain := t->negate (\\f.\\x.f x)")
+	is.EqualError(err, "host/test->negate: result: -1 is negative, and has no Church numeral")
+
+	// Without the primitives, host/test->greet is a mere free variable
+	result, err := evaluate(t, eval.NewNormalOrder(), "t | \"host/test\"\nmain := t->greet (\\x.x)")
+	is.NoError(err)
+	is.Equal(`host/test->greet (\x.x)`, result.String())
+}
+
+func TestRegistrationPanics(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	for _, fn := range []any{
+		42,
+		(func(int) int)(nil),
+		func(...int) int { return 0 },
+		func(float64) int { return 0 },
+		func(int) map[int]int { return nil },
+		func() {},
+		func() (int, int) { return 0, 0 },
+		func(int, context.Context) int { return 0 },
+	} {
+		is.Panics(func() { Func(fn) }, "%T", fn)
+	}
+	is.NotPanics(func() { Func(func() (bool, error) { return true, nil }) })
+
+	is.PanicsWithValue(`host: "a b" is not an identifier`, func() { NewModule().WithFunc("a b", strings.Fields) })
+	is.Panics(func() { NewModule().WithFunc("a->b", strings.Fields) })
+	is.Panics(func() { NewModule().WithFunc("_fields", strings.Fields) })
+	is.Panics(func() { Func(strings.Fields).WithStrict(1) })
+	is.Panics(func() { NewRegistry().WithModule("/host/os", NewModule()) })
+
+	m := NewModule().WithFunc("b", strings.Fields).WithFunc("a", strings.Fields).WithFunc("b", strings.ToUpper)
+	is.Equal([]string{"b", "a"}, m.Names())
+	is.Equal([]string{"b", "a"}, NewRegistry().WithModule("host/s", m).Definitions("host/s").Value())
+	is.True(NewRegistry().Definitions("host/s").Nothing())
+}
+
+func TestMarshalingProperties(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 200
+	parameters.MaxSize = 20
+	properties := gopter.NewProperties(parameters)
+
+	// roundTrip encodes and decodes v through the codec of its type
+	roundTrip := func(v any) bool {
+		c, err := codecOf(reflect.TypeOf(v))
+		if err != nil {
+			return false
+		}
+		encoded, err := c.encode(reflect.ValueOf(v))
+		if err != nil {
+			return false
+		}
+		decoded, ok := c.decode(encoded)
+		return ok && reflect.DeepEqual(decoded.Interface(), v)
+	}
+
+	properties.Property("lists of strings round trip", prop.ForAll(
+		func(s []string) bool { return roundTrip(s) },
+		gen.SliceOf(gen.AlphaString()),
+	))
+	properties.Property("lists of numbers and booleans round trip", prop.ForAll(
+		func(ns []int, bs []bool) bool { return roundTrip(ns) && roundTrip(bs) },
+		gen.SliceOf(gen.IntRange(0, 300)), gen.SliceOf(gen.Bool()),
+	))
+
+	properties.TestingRun(t)
+}
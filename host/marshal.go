@@ -0,0 +1,168 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+var termType = reflect.TypeOf((*term.Term)(nil)).Elem()
+
+// codec converts between the values of a Go type and their Church encodings.
+type codec struct {
+	// name and plural describe the encoding in messages, such as "a number"
+	// and "numbers"
+	name   string
+	plural string
+	// decode converts a term in normal form, reporting whether it encodes a
+	// value of the type
+	decode func(term.Term) (reflect.Value, bool)
+	encode func(reflect.Value) (term.Term, error)
+}
+
+// codecOf returns the codec of the values of type t.
+func codecOf(t reflect.Type) (codec, error) {
+	switch {
+	case t == termType:
+		return codec{name: "a term", plural: "terms", decode: decodeTerm, encode: encodeTerm}, nil
+	case t.Kind() == reflect.Int:
+		return codec{name: "a number", plural: "numbers", decode: decodeNumber(t), encode: encodeNumber}, nil
+	case t.Kind() == reflect.Bool:
+		return codec{name: "a boolean", plural: "booleans", decode: decodeBoolean(t), encode: encodeBoolean}, nil
+	case t.Kind() == reflect.String:
+		return codec{name: "a string", plural: "strings", decode: decodeString(t), encode: encodeString}, nil
+	case t.Kind() == reflect.Slice:
+		element, err := codecOf(t.Elem())
+		if err != nil {
+			return codec{}, err
+		}
+		return codec{
+			name:   "a list of " + element.plural,
+			plural: "lists of " + element.plural,
+			decode: decodeList(t, element),
+			encode: encodeList(element),
+		}, nil
+	}
+	return codec{}, fmt.Errorf("unsupported type %s", t)
+}
+
+func decodeTerm(t term.Term) (reflect.Value, bool) {
+	v := reflect.New(termType).Elem()
+	v.Set(reflect.ValueOf(t))
+	return v, true
+}
+
+func encodeTerm(v reflect.Value) (term.Term, error) {
+	if v.IsNil() {
+		return nil, errors.New("nil term")
+	}
+	return v.Interface().(term.Term), nil
+}
+
+func decodeNumber(t reflect.Type) func(term.Term) (reflect.Value, bool) {
+	return func(encoded term.Term) (reflect.Value, bool) {
+		n := eval.ReadNumeral(encoded)
+		if n.Nothing() {
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(n.Value()).Convert(t), true
+	}
+}
+
+func encodeNumber(v reflect.Value) (term.Term, error) {
+	n := int(v.Int())
+	if n < 0 {
+		return nil, fmt.Errorf("%d is negative, and has no Church numeral", n)
+	}
+	return eval.Numeral(n), nil
+}
+
+// decodeBoolean reads \t.\f.t and \t.\f.f, whatever the names of their
+// parameters.
+func decodeBoolean(t reflect.Type) func(term.Term) (reflect.Value, bool) {
+	return func(encoded term.Term) (reflect.Value, bool) {
+		outer, ok := encoded.(term.Abs)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		inner, ok := outer.Body().(term.Abs)
+		if !ok || inner.Param() == outer.Param() {
+			return reflect.Value{}, false
+		}
+		v, ok := inner.Body().(term.Var)
+		if !ok || v.Name() != outer.Param() && v.Name() != inner.Param() {
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(v.Name() == outer.Param()).Convert(t), true
+	}
+}
+
+func encodeBoolean(v reflect.Value) (term.Term, error) {
+	chosen := "f"
+	if v.Bool() {
+		chosen = "t"
+	}
+	return term.NewAbs("t", term.NewAbs("f", term.NewVar(chosen))), nil
+}
+
+func decodeString(t reflect.Type) func(term.Term) (reflect.Value, bool) {
+	return func(encoded term.Term) (reflect.Value, bool) {
+		s := eval.ReadString(encoded)
+		if s.Nothing() {
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(s.Value()).Convert(t), true
+	}
+}
+
+func encodeString(v reflect.Value) (term.Term, error) {
+	return term.Literal(v.String()), nil
+}
+
+func decodeList(t reflect.Type, element codec) func(term.Term) (reflect.Value, bool) {
+	return func(encoded term.Term) (reflect.Value, bool) {
+		elements := eval.ReadList(encoded)
+		if elements.Nothing() {
+			return reflect.Value{}, false
+		}
+		list := reflect.MakeSlice(t, 0, len(elements.Value()))
+		for _, e := range elements.Value() {
+			v, ok := element.decode(e)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			list = reflect.Append(list, v)
+		}
+		return list, true
+	}
+}
+
+// encodeList builds \c.\n.c a (c b n), renaming c and n if need be for
+// them not to capture the free variables of the elements.
+func encodeList(element codec) func(reflect.Value) (term.Term, error) {
+	return func(v reflect.Value) (term.Term, error) {
+		elements := make([]term.Term, v.Len())
+		avoid := map[string]bool{}
+		for i := range elements {
+			e, err := element.encode(v.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i+1, err)
+			}
+			elements[i] = e
+			for name := range term.FreeVars(e) {
+				avoid[name] = true
+			}
+		}
+		cons := term.Fresh("c", avoid)
+		empty := term.Fresh("n", avoid)
+
+		var list term.Term = term.NewVar(empty)
+		for i := len(elements) - 1; i >= 0; i-- {
+			list = term.NewApp(term.NewApp(term.NewVar(cons), elements[i]), list)
+		}
+		return term.NewAbs(cons, term.NewAbs(empty, list)), nil
+	}
+}
@@ -0,0 +1,134 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Primitive is a Go function callable from λ.c programs. It implements
+// eval.Primitive.
+type Primitive struct {
+	fn reflect.Value
+	// context tells whether fn takes the context of the evaluation first
+	context bool
+	params  []codec
+	result  codec
+	// fails tells whether fn returns an error after its result
+	fails  bool
+	strict []bool
+}
+
+// Func returns the Primitive calling fn, which must be a function whose
+// parameters, but a leading context.Context, and result are of the types
+// listed in the package documentation, possibly followed by an error. It
+// panics otherwise: like building a regular expression with
+// regexp.MustCompile, registering primitives is done once, at start-up, and
+// a function of the wrong type is a programming error.
+func Func(fn any) Primitive {
+	p, err := newPrimitive(fn)
+	if err != nil {
+		panic(fmt.Sprintf("host: %v", err))
+	}
+	return p
+}
+
+// newPrimitive implements Func.
+func newPrimitive(fn any) (Primitive, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return Primitive{}, fmt.Errorf("%T is not a function", fn)
+	}
+	t := v.Type()
+	if t.IsVariadic() {
+		return Primitive{}, fmt.Errorf("%s is variadic", t)
+	}
+
+	p := Primitive{fn: v}
+	first := 0
+	if t.NumIn() > 0 && t.In(0) == contextType {
+		p.context, first = true, 1
+	}
+	for i := first; i < t.NumIn(); i++ {
+		c, err := codecOf(t.In(i))
+		if err != nil {
+			return Primitive{}, fmt.Errorf("parameter %d of %s: %w", i+1, t, err)
+		}
+		p.params = append(p.params, c)
+		p.strict = append(p.strict, t.In(i) != termType)
+	}
+
+	switch {
+	case t.NumOut() == 2 && t.Out(1) == errorType:
+		p.fails = true
+	case t.NumOut() != 1:
+		return Primitive{}, fmt.Errorf("%s does not return a value, possibly followed by an error", t)
+	}
+	c, err := codecOf(t.Out(0))
+	if err != nil {
+		return Primitive{}, fmt.Errorf("result of %s: %w", t, err)
+	}
+	p.result = c
+	return p, nil
+}
+
+// WithStrict returns a copy of the primitive normalizing the term.Term
+// arguments of the given indices, starting at 0, before calling the
+// function. Other arguments are strict anyway. It panics if an index is out
+// of range.
+func (p Primitive) WithStrict(indices ...int) Primitive {
+	strict := append([]bool{}, p.strict...)
+	for _, i := range indices {
+		if i < 0 || i >= len(strict) {
+			panic(fmt.Sprintf("host: %s has no argument %d", p.fn.Type(), i))
+		}
+		strict[i] = true
+	}
+	p.strict = strict
+	return p
+}
+
+// Arity returns the number of arguments of the function, its context
+// excluded.
+func (p Primitive) Arity() int {
+	return len(p.params)
+}
+
+// Strict tells whether the argument of the given index is normalized before
+// the function is called.
+func (p Primitive) Strict(i int) bool {
+	return p.strict[i]
+}
+
+// Apply converts args, calls the function and converts its result. Strict
+// arguments are expected in normal form.
+func (p Primitive) Apply(ctx context.Context, args []term.Term) (term.Term, error) {
+	in := make([]reflect.Value, 0, len(args)+1)
+	if p.context {
+		in = append(in, reflect.ValueOf(&ctx).Elem())
+	}
+	for i, arg := range args {
+		v, ok := p.params[i].decode(arg)
+		if !ok {
+			return nil, fmt.Errorf("argument %d is not %s", i+1, p.params[i].name)
+		}
+		in = append(in, v)
+	}
+
+	out := p.fn.Call(in)
+	if p.fails && !out[1].IsNil() {
+		return nil, out[1].Interface().(error)
+	}
+	t, err := p.result.encode(out[0])
+	if err != nil {
+		return nil, fmt.Errorf("result: %w", err)
+	}
+	return t, nil
+}
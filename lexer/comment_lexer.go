@@ -0,0 +1,29 @@
+package lexer
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/denisdubochevalier/monad"
+)
+
+// commentLexer is a LexerFunc dedicated to comments, which start with "--" and
+// extend to the end of the line. It is invoked by spaceLexer when it meets
+// "--" at the start of a token.
+//
+// The literal of the resulting COMMENT token is the text following "--", up to
+// but excluding the line break, which is left for eolLexer. Comments carry no
+// meaning and are discarded by the parser, but tools such as the formatter
+// need them back.
+func commentLexer(l Lexer) (monad.Maybe[Token], Lexer) {
+	text, rest, found := strings.Cut(l.content[2:], "\n")
+	if found {
+		rest = "\n" + rest
+	}
+	text = strings.TrimSuffix(text, "\r")
+
+	return monad.Some(Token{COMMENT, l.position, Literal(text)}), l.
+		WithPosition(l.position.advanceColBy(2 + utf8.RuneCountInString(text))).
+		WithContent(rest).
+		WithNextLexerFunc(eofLexer)
+}
@@ -0,0 +1,51 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentLexer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input   string
+		literal Literal
+		rest    string
+	}{
+		{"--", "", ""},
+		{"-- identity", " identity", ""},
+		{"-- identity\ni := \\x.x", " identity", "\ni := \\x.x"},
+		{"--λ\r\n", "λ", "\n"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.input, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			token, l := commentLexer(New().WithContent(testCase.input))
+			is.Equal(Token{COMMENT, StartPosition(), testCase.literal}, token.Value())
+			is.Equal(testCase.rest, l.content)
+		})
+	}
+}
+
+func TestComments(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	tokens := New().WithContent("-- doc\ni := f--x -- trailing\n").Tokens()
+	is.Equal([]Token{
+		{COMMENT, NewPosition(1, 0), " doc"},
+		{EOL, NewPosition(1, 6), ""},
+		{IDENT, NewPosition(2, 0), "i"},
+		{ASSIGN, NewPosition(2, 2), ":="},
+		{IDENT, NewPosition(2, 5), "f"},
+		{COMMENT, NewPosition(2, 6), "x -- trailing"},
+		{EOL, NewPosition(2, 21), ""},
+		{EOF, NewPosition(3, 0), ""},
+	}, tokens)
+}
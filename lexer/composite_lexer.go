@@ -27,13 +27,13 @@ func compositeLexer(l Lexer) (monad.Maybe[Token], Lexer) {
 			xs := xs[size:]
 			if x == ':' && x2 == '=' {
 				return monad.Some(Token{ASSIGN, l.position, Literal([]rune{x, x2})}), l.
-					WithPosition(l.position.advanceCol()).
+					WithPosition(l.position.advanceColBy(2)).
 					WithContent(xs).
 					WithNextLexerFunc(eofLexer)
 			}
 			if x == '-' && x2 == '>' {
 				return monad.Some(Token{NSDEREF, l.position, Literal([]rune{x, x2})}), l.
-					WithPosition(l.position.advanceCol()).
+					WithPosition(l.position.advanceColBy(2)).
 					WithContent(xs).
 					WithNextLexerFunc(eofLexer)
 			}
@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"strings"
 	"unicode/utf8"
 
 	"github.com/denisdubochevalier/monad"
@@ -28,17 +29,14 @@ func eolLexer(l Lexer) (monad.Maybe[Token], Lexer) {
 
 	if x == '\n' {
 		l = l.
-			WithPosition(l.position).
+			WithPosition(l.position.newRow()).
 			WithContent(xs).
 			WithNextLexerFunc(eofLexer)
 
-		for x == '\n' {
-			x, size = utf8.DecodeRuneInString(l.content)
-			xs = l.content[size:]
+		for strings.HasPrefix(l.content, "\n") {
 			l = l.
 				WithPosition(l.position.newRow()).
-				WithContent(xs).
-				WithNextLexerFunc(eofLexer)
+				WithContent(l.content[1:])
 		}
 
 		return monad.Some(Token{EOL, startPosition, ""}), l
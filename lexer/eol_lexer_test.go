@@ -87,3 +87,16 @@ func TestEolLexerWithNonEolCharacter(t *testing.T) {
 	nlf2 := reflect.ValueOf(updatedLexer.nextLexerFunc)
 	is.Equal(nlf1.Pointer(), nlf2.Pointer())
 }
+
+// Testing eolLexer leaves the first character of the next line untouched
+func TestEolLexerKeepsNextLine(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	lexer := New().WithContent("\n\nx").WithNextLexerFunc(eolLexer)
+	result, updatedLexer := lexer.Next()
+
+	is.Equal(monad.Some(Token{EOL, StartPosition(), ""}), result)
+	is.Equal(Position{row: 3, col: 0}, updatedLexer.position)
+	is.Equal("x", updatedLexer.content)
+}
@@ -39,11 +39,12 @@ func identifierLexer(l Lexer) (monad.Maybe[Token], Lexer) {
 	return idLexRecursively(l)
 }
 
-// checkCompositeOps checks for composite operators like ":=" and "->"
+// checkCompositeOps checks for composite operators like ":=" and "->", and for
+// the start of a comment, none of which may be part of an identifier.
 func checkCompositeOps(x rune, xs string) bool {
 	if len(xs) > 0 {
 		x2, _ := utf8.DecodeRuneInString(xs)
-		return (x == ':' && x2 == '=') || (x == '-' && x2 == '>')
+		return (x == ':' && x2 == '=') || (x == '-' && (x2 == '>' || x2 == '-'))
 	}
 	return false
 }
@@ -135,8 +136,9 @@ func idLexRecursively(l Lexer) (monad.Maybe[Token], Lexer) {
 			return finalizeIdentifierToken(l)
 		}
 
-		// Continue with the recursion, consuming the character
-		nextLexer := updateLexerForRecursion(l, size, xs)
+		// Continue with the recursion, consuming the character, which takes
+		// a single column however many bytes it is encoded with
+		nextLexer := updateLexerForRecursion(l, 1, xs)
 		nextToken, remainingLexer := idLexRecursively(nextLexer)
 		mergedToken := mergeLiterals(nextToken, x)
 		return monad.Some(Token{IDENT, l.position, mergedToken.Value().Literal()}), remainingLexer
@@ -231,6 +231,21 @@ func TestIdLexRecursivelyBasic(t *testing.T) {
 		},
 	)
 
+	t.Run(
+		"when content contains valid identifier with comment mid way",
+		func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			l := New().WithContent("a--b")
+			result, rest := identifierLexer(l)
+
+			is.True(result.Just())
+			is.Equal(Literal("a"), result.Value().Literal())
+			is.Equal("--b", rest.content)
+		},
+	)
+
 	t.Run(
 		"when content contains valid identifier with simple operator mid way",
 		func(t *testing.T) {
@@ -528,7 +543,15 @@ func TestCheckCompositeOps(t *testing.T) {
 		is.True(checkCompositeOps(x, xs))
 	})
 
-	// Test Case 3: Invalid composite operator
+	// Test Case 3: Start of a comment "--"
+	t.Run("Start of a comment --", func(t *testing.T) {
+		t.Parallel()
+		x := '-'
+		xs := "-b"
+		is.True(checkCompositeOps(x, xs))
+	})
+
+	// Test Case 4: Invalid composite operator
 	t.Run("Invalid composite operator", func(t *testing.T) {
 		t.Parallel()
 		x := ':'
@@ -536,7 +559,7 @@ func TestCheckCompositeOps(t *testing.T) {
 		is.False(checkCompositeOps(x, xs))
 	})
 
-	// Test Case 4: Invalid character but valid remaining string
+	// Test Case 5: Invalid character but valid remaining string
 	t.Run("Invalid character but valid remaining string", func(t *testing.T) {
 		t.Parallel()
 		x := '+'
@@ -544,7 +567,7 @@ func TestCheckCompositeOps(t *testing.T) {
 		is.False(checkCompositeOps(x, xs))
 	})
 
-	// Test Case 5: Valid character but invalid remaining string
+	// Test Case 6: Valid character but invalid remaining string
 	t.Run("Valid character but invalid remaining string", func(t *testing.T) {
 		t.Parallel()
 		x := ':'
@@ -552,7 +575,7 @@ func TestCheckCompositeOps(t *testing.T) {
 		is.False(checkCompositeOps(x, xs))
 	})
 
-	// Test Case 6: Both invalid character and invalid remaining string
+	// Test Case 7: Both invalid character and invalid remaining string
 	t.Run("Both invalid character and invalid remaining string", func(t *testing.T) {
 		t.Parallel()
 		x := '&'
@@ -560,7 +583,7 @@ func TestCheckCompositeOps(t *testing.T) {
 		is.False(checkCompositeOps(x, xs))
 	})
 
-	// Test Case 7: Empty remaining string
+	// Test Case 8: Empty remaining string
 	t.Run("Empty remaining string", func(t *testing.T) {
 		t.Parallel()
 		x := ':'
@@ -568,7 +591,7 @@ func TestCheckCompositeOps(t *testing.T) {
 		is.False(checkCompositeOps(x, xs))
 	})
 
-	// Test Case 8: Valid composite operator with extra characters
+	// Test Case 9: Valid composite operator with extra characters
 	t.Run("Valid composite operator with extra characters", func(t *testing.T) {
 		t.Parallel()
 		x := ':'
@@ -576,7 +599,7 @@ func TestCheckCompositeOps(t *testing.T) {
 		is.True(checkCompositeOps(x, xs))
 	})
 
-	// Test Case 9: UTF-8 valid characters
+	// Test Case 10: UTF-8 valid characters
 	t.Run("UTF-8 valid characters", func(t *testing.T) {
 		t.Parallel()
 		x := '→'
@@ -646,6 +669,21 @@ func TestIdentifierLexer(t *testing.T) {
 		},
 	)
 
+	t.Run(
+		"when content contains valid identifier with comment mid way",
+		func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			l := New().WithContent("a--b")
+			result, rest := identifierLexer(l)
+
+			is.True(result.Just())
+			is.Equal(Literal("a"), result.Value().Literal())
+			is.Equal("--b", rest.content)
+		},
+	)
+
 	t.Run(
 		"when content contains valid identifier with simple operator mid way",
 		func(t *testing.T) {
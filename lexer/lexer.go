@@ -157,3 +157,24 @@ func (l Lexer) WithNextLexerFunc(f lexerFunc) Lexer {
 func (l Lexer) Next() (monad.Maybe[Token], Lexer) {
 	return l.nextLexerFunc(l)
 }
+
+// Tokens drives the Lexer to completion and gathers every emitted Token in
+// source order. Iteration stops after the EOF token or at the first ILLEGAL
+// token, which is kept as the last element so that callers can report it.
+// This is the convenient entry point for the later stages of the compiler,
+// which consume the token stream as a whole.
+func (l Lexer) Tokens() []Token {
+	tokens := []Token{}
+	for l.nextLexerFunc != nil {
+		var token monad.Maybe[Token]
+		token, l = l.Next()
+		if token.Nothing() {
+			continue
+		}
+		tokens = append(tokens, token.Value())
+		if t := token.Value().Type(); t == EOF || t == ILLEGAL {
+			break
+		}
+	}
+	return tokens
+}
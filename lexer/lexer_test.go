@@ -75,3 +75,39 @@ func TestNext(t *testing.T) {
 	is.Equal(IDENT, token.tokenType)
 	is.Equal(Literal("x"), token.Literal())
 }
+
+// TestTokens verifies that Tokens collects the whole token stream.
+func TestTokens(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	tokens := New().WithContent("i := \\x.x").Tokens()
+	types := []TokenType{}
+	for _, token := range tokens {
+		types = append(types, token.Type())
+	}
+	is.Equal([]TokenType{IDENT, ASSIGN, LAMBDA, IDENT, DOT, IDENT, EOF}, types)
+}
+
+// TestTokensColumnsCountRunes verifies that columns count characters rather
+// than bytes, whatever the token.
+func TestTokensColumnsCountRunes(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	tokens := New().WithContent(`λ := \α.\β."é" α -- ω`).Tokens()
+	cols := []int{}
+	for _, token := range tokens {
+		cols = append(cols, token.Position().Col())
+	}
+	is.Equal([]int{0, 2, 5, 6, 7, 8, 9, 10, 11, 15, 17, 21}, cols)
+}
+
+// TestTokensStopsOnIllegal verifies that Tokens stops at the first ILLEGAL token.
+func TestTokensStopsOnIllegal(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	tokens := New().WithContent("m | \"unterminated").Tokens()
+	is.Equal(ILLEGAL, tokens[len(tokens)-1].Type())
+}
@@ -44,8 +44,8 @@ func TestOperatorLexer(t *testing.T) {
 				is.Equal(testCase.expectedLit, token.literal)
 			}
 
-			// Ensure that the new lexer's position and content have been updated correctly
-			is.Equal(StartPosition().advanceCol(), newLexer.position)
+			// Ensure that the new lexer's position has advanced past the whole operator
+			is.Equal(StartPosition().advanceColBy(len(testCase.expectedLit)), newLexer.position)
 		})
 	}
 }
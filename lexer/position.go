@@ -56,3 +56,10 @@ func (p Position) advanceColBy(n int) Position {
 	p.col += n
 	return p
 }
+
+// NewPosition builds a Position from a row and a column. It is meant for
+// consumers that need to designate a location in the source text without
+// lexing it, for instance when translating editor coordinates.
+func NewPosition(row, col int) Position {
+	return Position{row: row, col: col}
+}
@@ -23,7 +23,8 @@ import (
 // continues the lexer process by setting the next lexer function to eofLexer.
 //
 // If a space is not encountered, it inspects the character to determine
-// which lexer function should be called next, for example, operatorLexer or stringLexer.
+// which lexer function should be called next, for example, commentLexer,
+// operatorLexer or stringLexer.
 func spaceLexer(l Lexer) (monad.Maybe[Token], Lexer) {
 	x, size := utf8.DecodeRuneInString(l.content)
 	xs := l.content[size:]
@@ -35,6 +36,10 @@ func spaceLexer(l Lexer) (monad.Maybe[Token], Lexer) {
 			WithNextLexerFunc(eofLexer)
 	}
 
+	if x == '-' && strings.HasPrefix(xs, "-") {
+		return commentLexer(l)
+	}
+
 	if strings.ContainsAny(string(x), "\\.()|:-") {
 		return operatorLexer(l)
 	}
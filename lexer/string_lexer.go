@@ -1,7 +1,6 @@
 package lexer
 
 import (
-	"strings"
 	"unicode/utf8"
 
 	"github.com/denisdubochevalier/monad"
@@ -36,11 +35,15 @@ func stringLexer(l Lexer) (monad.Maybe[Token], Lexer) {
 	_, size := utf8.DecodeRuneInString(l.content)
 	xs := l.content[size:]
 
-	val := recursiveStringLexer(Token{STRING, l.position, ""}, xs)
-	content, _ := strings.CutPrefix(l.content, "\""+string(val.Value().literal)+"\"")
+	val, content := recursiveStringLexer(Token{STRING, l.position, ""}, xs)
+	if val.Value().Type() == ILLEGAL {
+		return monad.Some(val.Value()), l.WithNextLexerFunc(nil)
+	}
 
 	return monad.Some(val.Value()), l.
-		WithPosition(l.position.advanceColBy(utf8.RuneCountInString(val.Value().literal.String()))).
+		WithPosition(l.position.advanceColBy(
+			utf8.RuneCountInString(l.content) - utf8.RuneCountInString(content),
+		)).
 		WithContent(content).
 		WithNextLexerFunc(eofLexer)
 }
@@ -62,21 +65,23 @@ func appendStringToken(t Token, x rune) Token {
 	return t
 }
 
-// recursiveStringLexer is the recursive function to handle string lexing.
-func recursiveStringLexer(t Token, xs string) monad.Either[Token] {
+// recursiveStringLexer is the recursive function to handle string lexing. Along
+// with the resulting token, it returns the content left after the closing
+// quote, so that the caller knows how much of the input was consumed.
+func recursiveStringLexer(t Token, xs string) (monad.Either[Token], string) {
 	if len(xs) == 0 {
-		return monad.NewRVal(Token{ILLEGAL, t.Position(), ""})
+		return monad.NewRVal(Token{ILLEGAL, t.Position(), ""}), xs
 	}
 
 	x, size := utf8.DecodeRuneInString(xs)
 	xs = xs[size:]
 
 	if x == '\n' {
-		return monad.NewRVal(Token{ILLEGAL, t.Position(), ""})
+		return monad.NewRVal(Token{ILLEGAL, t.Position(), ""}), xs
 	}
 
 	if x == '"' {
-		return monad.NewRVal(Token{STRING, t.Position(), t.Literal()})
+		return monad.NewRVal(Token{STRING, t.Position(), t.Literal()}), xs
 	}
 
 	if x == '\\' {
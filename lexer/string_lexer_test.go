@@ -65,3 +65,13 @@ func TestStringLexerProperty(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+func TestStringLexerConsumesEscapes(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	tokenMaybe, l := stringLexer(New().WithContent(`"a\"b" rest`))
+	is.Equal(Token{STRING, StartPosition(), `a"b`}, tokenMaybe.Value())
+	is.Equal(" rest", l.content)
+	is.Equal(StartPosition().advanceColBy(6), l.position)
+}
@@ -28,3 +28,10 @@ func (t Token) Position() Position {
 func (t Token) Literal() Literal {
 	return t.literal
 }
+
+// NewToken builds a Token from its parts. The lexer itself never needs it, but
+// later compilation stages occasionally have to synthesize tokens for nodes
+// that have no direct lexical counterpart, such as function applications.
+func NewToken(tokenType TokenType, position Position, literal Literal) Token {
+	return Token{tokenType, position, literal}
+}
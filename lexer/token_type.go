@@ -16,6 +16,9 @@ const (
 	STRING                   // STRING represents a string literal (e.g., "github.com/foo/bar", "text/lexer", ...).
 	LPAREN                   // LPAREN represents the left parenthesis (().
 	RPAREN                   // RPAREN represents the right parenthesis ()).
+	COMMENT                  // COMMENT represents a comment, from -- to the end of the line. The parser ignores it.
+	APPLY                    // APPLY represents a function application. It is never emitted by the lexer and only tags AST nodes.
+	PROGRAM                  // PROGRAM represents a whole program. It is never emitted by the lexer and only tags the root of ASTs.
 )
 
 var values = []string{
@@ -31,6 +34,9 @@ var values = []string{
 	MODULE:  "|",
 	NSDEREF: "->",
 	ASSIGN:  ":=",
+	COMMENT: "COMMENT",
+	APPLY:   "APPLY",
+	PROGRAM: "PROGRAM",
 }
 
 // String returns a string representation of the TokenType.
@@ -26,6 +26,9 @@ func TestTokenTypeString(t *testing.T) {
 		{MODULE, "|"},
 		{NSDEREF, "->"},
 		{ASSIGN, ":="},
+		{COMMENT, "COMMENT"},
+		{APPLY, "APPLY"},
+		{PROGRAM, "PROGRAM"},
 		{TokenType(-1), "UNKNOWN"},   // Negative value
 		{TokenType(1000), "UNKNOWN"}, // Out-of-bounds value
 	}
@@ -0,0 +1,205 @@
+// Package lint implements the warnings of λ.c: code that is valid, yet most
+// likely not what its author meant.
+//
+// Overview:
+//
+// Church-encoded programs are made of little more than lambdas and names,
+// which makes a couple of mistakes both easy to make and hard to spot:
+//
+//   - A lambda parameter silently shadowing a top-level combinator, a
+//     namespace or an enclosing parameter, e.g. `k := \x.\x.x`.
+//   - A lambda whose parameter is never used by mistake. Parameters that are
+//     deliberately ignored can be named `_` to silence the warning.
+//   - A top-level definition that is never referenced, directly or not, from
+//     an entry point of the program.
+//
+// The entry points are the bare expression statements of the program and the
+// definitions whose names were given to WithEntryPoints (`main` by default).
+// A program without any entry point is considered a library, whose
+// definitions are all meant to be used from elsewhere, hence never reported
+// as unused.
+//
+// Usage:
+//
+//	resolution := resolver.Resolve(program)
+//	warnings := lint.New().Run(program, resolution)
+package lint
+
+import (
+	"sort"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+)
+
+// Blank is the parameter name that marks a deliberately unused parameter.
+const Blank = "_"
+
+// Linter holds the configuration of the lint pass. It follows the same
+// immutable builder pattern as the lexer: configuration methods return
+// updated copies.
+type Linter struct {
+	entryPoints []string
+}
+
+// New returns a Linter treating the `main` definition as the entry point of
+// the programs it checks.
+func New() Linter {
+	return Linter{entryPoints: []string{"main"}}
+}
+
+// WithEntryPoints replaces the names of the definitions considered as entry
+// points of the program.
+func (l Linter) WithEntryPoints(names ...string) Linter {
+	l.entryPoints = append([]string{}, names...)
+	return l
+}
+
+// Run checks a program, given its AST and the result of its scope resolution,
+// and returns the warnings found, sorted by position. Run does not report
+// errors: those are the business of the resolver.
+func (l Linter) Run(program parser.ASTNode, resolution resolver.Resolution) []diagnostic.Diagnostic {
+	warnings := []diagnostic.Diagnostic{}
+	for _, statement := range program.Children() {
+		switch statement.NodeType() {
+		case lexer.ASSIGN:
+			warnings = append(warnings, checkExpression(statement.Children()[1], resolution, nil)...)
+		case lexer.MODULE, lexer.EOF:
+		default:
+			warnings = append(warnings, checkExpression(statement, resolution, nil)...)
+		}
+	}
+	warnings = append(warnings, l.unusedDefinitions(program, resolution)...)
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		a, b := warnings[i].Position(), warnings[j].Position()
+		return a.Row() < b.Row() || (a.Row() == b.Row() && a.Col() < b.Col())
+	})
+	return warnings
+}
+
+// checkExpression reports shadowing and unused parameters within an
+// expression. The enclosing parameters are passed innermost first.
+func checkExpression(
+	node parser.ASTNode,
+	resolution resolver.Resolution,
+	enclosing []resolver.Binding,
+) []diagnostic.Diagnostic {
+	warnings := []diagnostic.Diagnostic{}
+	children := node.Children()
+	if node.NodeType() != lexer.LAMBDA {
+		for _, child := range children {
+			warnings = append(warnings, checkExpression(child, resolution, enclosing)...)
+		}
+		return warnings
+	}
+
+	param := resolution.Lookup(children[0].Token().Position())
+	if param.Nothing() {
+		return checkExpression(children[1], resolution, enclosing)
+	}
+	b := param.Value()
+
+	if b.Name() != Blank {
+		if shadowed, ok := shadowedBinding(b.Name(), resolution, enclosing); ok {
+			warnings = append(warnings, diagnostic.Warningf(
+				b.Position(), "parameter %q shadows %s", b.Name(), shadowed.Kind(),
+			).WithHint("declared at %d:%d", shadowed.Position().Row(), shadowed.Position().Col()))
+		}
+		if len(resolution.References(b)) == 0 {
+			warnings = append(warnings, diagnostic.Warningf(
+				b.Position(), "parameter %q is never used", b.Name(),
+			).WithHint("name it %s if this is intended", Blank))
+		}
+	}
+
+	return append(warnings, checkExpression(
+		children[1], resolution, append([]resolver.Binding{b}, enclosing...),
+	)...)
+}
+
+// shadowedBinding finds the binding a parameter named name would hide: an
+// enclosing parameter first, then a top-level definition or namespace.
+func shadowedBinding(
+	name string,
+	resolution resolver.Resolution,
+	enclosing []resolver.Binding,
+) (resolver.Binding, bool) {
+	for _, b := range enclosing {
+		if b.Name() == name {
+			return b, true
+		}
+	}
+	if d := resolution.Definition(name); d.Just() {
+		return d.Value(), true
+	}
+	for _, ns := range resolution.Namespaces() {
+		if ns.Name() == name {
+			return ns, true
+		}
+	}
+	return resolver.Binding{}, false
+}
+
+// unusedDefinitions reports the top-level definitions that cannot be reached
+// from any entry point, following references between definitions.
+func (l Linter) unusedDefinitions(program parser.ASTNode, resolution resolver.Resolution) []diagnostic.Diagnostic {
+	bodies := map[resolver.Binding]parser.ASTNode{}
+	roots := []resolver.Binding{}
+	hasEntryPoint := false
+	for _, statement := range program.Children() {
+		switch statement.NodeType() {
+		case lexer.ASSIGN:
+			b := resolution.Lookup(statement.Children()[0].Token().Position())
+			if b.Nothing() || b.Value().Position() != statement.Children()[0].Token().Position() {
+				continue // duplicate definition, already reported by the resolver
+			}
+			bodies[b.Value()] = statement.Children()[1]
+			if l.isEntryPoint(b.Value().Name()) {
+				roots = append(roots, b.Value())
+				hasEntryPoint = true
+			}
+		case lexer.MODULE, lexer.EOF:
+		default:
+			roots = append(roots, resolution.DefinitionsUsedBy(statement)...)
+			hasEntryPoint = true
+		}
+	}
+	if !hasEntryPoint {
+		return nil
+	}
+
+	reachable := map[resolver.Binding]bool{}
+	for len(roots) > 0 {
+		b := roots[0]
+		roots = roots[1:]
+		if reachable[b] {
+			continue
+		}
+		reachable[b] = true
+		roots = append(roots, resolution.DefinitionsUsedBy(bodies[b])...)
+	}
+
+	warnings := []diagnostic.Diagnostic{}
+	for _, b := range resolution.Definitions() {
+		if !reachable[b] {
+			warnings = append(warnings, diagnostic.Warningf(
+				b.Position(), "definition %q is never used", b.Name(),
+			))
+		}
+	}
+	return warnings
+}
+
+// isEntryPoint tells whether a definition is one of the configured entry
+// points.
+func (l Linter) isEntryPoint(name string) bool {
+	for _, entry := range l.entryPoints {
+		if entry == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+)
+
+func TestLinterRun(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		linter   Linter
+		input    string
+		expected []string
+	}{
+		{
+			"clean program",
+			New(),
+			"k := \\x.\\_.x\nmain := k k",
+			[]string{},
+		},
+		{
+			"unused parameter",
+			New(),
+			"main := \\x.\\y.x",
+			[]string{`1:12: warning: parameter "y" is never used (name it _ if this is intended)`},
+		},
+		{
+			"parameter shadowing a parameter",
+			New(),
+			"main := \\x.\\x.x",
+			[]string{
+				`1:9: warning: parameter "x" is never used (name it _ if this is intended)`,
+				`1:12: warning: parameter "x" shadows parameter (declared at 1:9)`,
+			},
+		},
+		{
+			"parameter shadowing a definition",
+			New(),
+			"k := \\x.\\_.x\nmain := k (\\k.k)",
+			[]string{`2:12: warning: parameter "k" shadows definition (declared at 1:0)`},
+		},
+		{
+			"parameter shadowing a namespace",
+			New(),
+			"io | \"std/io\"\nmain := \\io.io",
+			[]string{`2:9: warning: parameter "io" shadows namespace (declared at 1:0)`},
+		},
+		{
+			"unused definitions",
+			New(),
+			"i := \\x.x\nk := \\x.\\_.x\ns := k\nmain := s i",
+			[]string{},
+		},
+		{
+			"unreachable definitions",
+			New(),
+			"i := \\x.x\nk := \\x.\\_.x\nloop := k loop\nmain := i",
+			[]string{
+				`2:0: warning: definition "k" is never used`,
+				`3:0: warning: definition "loop" is never used`,
+			},
+		},
+		{
+			"bare expression entry point",
+			New(),
+			"i := \\x.x\nk := \\x.\\_.x\ni i",
+			[]string{`2:0: warning: definition "k" is never used`},
+		},
+		{
+			"custom entry point",
+			New().WithEntryPoints("start"),
+			"i := \\x.x\nstart := i\nmain := i",
+			[]string{`3:0: warning: definition "main" is never used`},
+		},
+		{
+			"library without entry point",
+			New(),
+			"i := \\x.x\nk := \\x.\\_.x",
+			[]string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			program := parser.ParseString(testCase.input)
+			is.True(program.Success(), "%v", program.Error())
+			resolution := resolver.Resolve(program.Value())
+
+			messages := []string{}
+			for _, d := range testCase.linter.Run(program.Value(), resolution) {
+				is.Equal(diagnostic.Warning, d.Severity())
+				messages = append(messages, d.Error())
+			}
+			is.Equal(testCase.expected, messages)
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/fixpoint"
+	"github.com/denisdubochevalier/lambdac/format"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/lint"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+const (
+	// HoverSteps bounds the reduction steps spent computing the normal form
+	// of a definition shown on hover.
+	HoverSteps = 10_000
+	// HoverTimeout bounds the time spent computing the normal form of a
+	// definition shown on hover.
+	HoverTimeout = 500 * time.Millisecond
+)
+
+// document is an open text document along with the outcome of its analysis,
+// recomputed on every change.
+type document struct {
+	uri         string
+	text        string
+	lines       []string
+	tokens      []lexer.Token
+	program     monad.Maybe[parser.ASTNode]
+	resolution  resolver.Resolution
+	diagnostics []diagnostic.Diagnostic
+}
+
+// analyze runs the front end over the text of a document, the same way
+// `lambdac check` does.
+func analyze(uri, text string) document {
+	doc := document{
+		uri:     uri,
+		text:    text,
+		lines:   strings.Split(text, "\n"),
+		tokens:  lexer.New().WithContent(text).Tokens(),
+		program: monad.None[parser.ASTNode](),
+	}
+
+	ast := parser.ParseString(text)
+	if ast.Failure() {
+		var d diagnostic.Diagnostic
+		if !errors.As(ast.Error(), &d) {
+			d = diagnostic.Errorf(lexer.StartPosition(), "%v", ast.Error())
+		}
+		doc.diagnostics = []diagnostic.Diagnostic{d}
+		return doc
+	}
+
+	program := ast.Value()
+	doc.program = monad.Some(program)
+	doc.resolution = resolver.Resolve(program)
+	doc.diagnostics = append(doc.resolution.Diagnostics(), lint.New().Run(program, doc.resolution)...)
+	doc.diagnostics = append(doc.diagnostics, fixpoint.Diagnostics(fixpoint.Detect(program, doc.resolution))...)
+	diagnostic.Sort(doc.diagnostics)
+	return doc
+}
+
+// protocolDiagnostics converts the diagnostics of the document, each
+// spanning the token it points at.
+func (doc document) protocolDiagnostics() []protocolDiagnostic {
+	diagnostics := make([]protocolDiagnostic, 0, len(doc.diagnostics))
+	for _, d := range doc.diagnostics {
+		message := d.Message()
+		if d.Hint() != "" {
+			message += " (" + d.Hint() + ")"
+		}
+		diagnostics = append(diagnostics, protocolDiagnostic{
+			Range:    doc.tokenRange(doc.tokenAt(d.Position()).OrElse(lexer.NewToken(lexer.ILLEGAL, d.Position(), ""))),
+			Severity: severity(d.Severity()),
+			Source:   "lambdac",
+			Message:  message,
+		})
+	}
+	return diagnostics
+}
+
+// tokenAt returns the token covering the given position, if any.
+func (doc document) tokenAt(p lexer.Position) monad.Maybe[lexer.Token] {
+	for _, token := range doc.tokens {
+		start := token.Position()
+		if start.Row() == p.Row() && start.Col() <= p.Col() && p.Col() < start.Col()+tokenWidth(token) {
+			return monad.Some(token)
+		}
+	}
+	return monad.None[lexer.Token]()
+}
+
+// tokenWidth returns the number of columns a token spans, at least one. The
+// quotes of strings and the dashes of comments are not part of their literal.
+func tokenWidth(token lexer.Token) int {
+	switch token.Type() {
+	case lexer.STRING, lexer.COMMENT:
+		return utf8.RuneCountInString(token.Literal().String()) + 2
+	default:
+		return max(utf8.RuneCountInString(token.Literal().String()), 1)
+	}
+}
+
+// tokenRange returns the range a token spans.
+func (doc document) tokenRange(token lexer.Token) textRange {
+	start := token.Position()
+	end := lexer.NewPosition(start.Row(), start.Col()+tokenWidth(token))
+	return textRange{Start: toProtocol(doc.lines, start), End: toProtocol(doc.lines, end)}
+}
+
+// binding returns the binding of the identifier at the given position. The
+// name of `alias->name` has no binding of its own until the module it lives
+// in is loaded, and leads to the import of the alias.
+func (doc document) binding(p lexer.Position) monad.Maybe[resolver.Binding] {
+	if doc.program.Nothing() {
+		return monad.None[resolver.Binding]()
+	}
+	token := doc.tokenAt(p)
+	if token.Nothing() || token.Value().Type() != lexer.IDENT {
+		return monad.None[resolver.Binding]()
+	}
+	if b := doc.resolution.Lookup(token.Value().Position()); b.Just() {
+		return b
+	}
+	for _, q := range doc.resolution.Qualified() {
+		if q.Name().Position() == token.Value().Position() {
+			return monad.Some(q.Namespace())
+		}
+	}
+	return monad.None[resolver.Binding]()
+}
+
+// definition returns the location of the binding of the identifier at the
+// given position.
+func (doc document) definition(p lexer.Position) monad.Maybe[location] {
+	b := doc.binding(p)
+	if b.Nothing() {
+		return monad.None[location]()
+	}
+	return monad.Some(location{URI: doc.uri, Range: doc.tokenRange(b.Value().Token())})
+}
+
+// hover describes the binding of the identifier at the given position: the
+// statement of a definition along with its normal form, the statement of an
+// import, or the kind of a lambda parameter.
+func (doc document) hover(ctx context.Context, p lexer.Position) monad.Maybe[hover] {
+	b := doc.binding(p)
+	if b.Nothing() {
+		return monad.None[hover]()
+	}
+	binding := b.Value()
+
+	var contents string
+	switch binding.Kind() {
+	case resolver.Definition:
+		contents = doc.describeDefinition(ctx, binding)
+	case resolver.Namespace:
+		contents = fmt.Sprintf("```lambdac\n%s | %q\n```\nnamespace", binding.Name(), doc.importPath(binding))
+	default:
+		contents = fmt.Sprintf("```lambdac\n%s\n```\nparameter", binding.Name())
+	}
+	return monad.Some(hover{
+		Contents: markupContent{Kind: "markdown", Value: contents},
+		Range:    doc.tokenRange(doc.tokenAt(p).Value()),
+	})
+}
+
+// describeDefinition renders a definition and its normal form, computed by
+// normal-order reduction within HoverSteps steps and HoverTimeout.
+func (doc document) describeDefinition(ctx context.Context, binding resolver.Binding) string {
+	program := doc.program.Value()
+	var b strings.Builder
+	for _, statement := range program.Children() {
+		if statement.NodeType() == lexer.ASSIGN && statement.Children()[0].Token() == binding.Token() {
+			if t := term.FromSource(statement.Children()[1]); t.Success() {
+				fmt.Fprintf(&b, "```lambdac\n%s := %s\n```\n", binding.Name(), t.Value())
+			}
+		}
+	}
+
+	loaded := eval.Load(program)
+	if loaded.Failure() {
+		return b.String()
+	}
+	closed := loaded.Value().Environment().Close(term.NewVar(binding.Name()))
+	if closed.Failure() {
+		fmt.Fprintf(&b, "no normal form: %s", message(closed.Error()))
+		return b.String()
+	}
+	ctx, cancel := context.WithTimeout(ctx, HoverTimeout)
+	defer cancel()
+	normal := eval.NewNormalOrder().WithMaxSteps(HoverSteps).Evaluate(ctx, closed.Value())
+	if normal.Failure() {
+		fmt.Fprintf(&b, "no normal form: %s", message(normal.Error()))
+		return b.String()
+	}
+	fmt.Fprintf(&b, "normal form:\n```lambdac\n%s\n```", normal.Value())
+	return b.String()
+}
+
+// importPath returns the path imported under the given namespace binding.
+func (doc document) importPath(binding resolver.Binding) string {
+	for _, statement := range doc.program.Value().Children() {
+		if statement.NodeType() == lexer.MODULE && statement.Children()[0].Token() == binding.Token() {
+			return statement.Children()[1].Token().Literal().String()
+		}
+	}
+	return ""
+}
+
+// symbols lists the top-level definitions of the document. The range of a
+// definition spans its whole statement.
+func (doc document) symbols() []documentSymbol {
+	symbols := []documentSymbol{}
+	if doc.program.Nothing() {
+		return symbols
+	}
+	for _, b := range doc.resolution.Definitions() {
+		symbols = append(symbols, documentSymbol{
+			Name:           b.Name(),
+			Kind:           symbolFunction,
+			Range:          textRange{Start: toProtocol(doc.lines, b.Position()), End: doc.statementEnd(b.Token())},
+			SelectionRange: doc.tokenRange(b.Token()),
+		})
+	}
+	return symbols
+}
+
+// statementEnd returns the end of the statement starting with the given
+// token: the first line break, comment or end of file outside parentheses.
+func (doc document) statementEnd(first lexer.Token) position {
+	depth := 0
+	started := false
+	for _, token := range doc.tokens {
+		if token == first {
+			started = true
+		}
+		if !started {
+			continue
+		}
+		switch token.Type() {
+		case lexer.LPAREN:
+			depth++
+		case lexer.RPAREN:
+			depth--
+		case lexer.EOL, lexer.EOF, lexer.COMMENT:
+			if depth <= 0 {
+				return toProtocol(doc.lines, token.Position())
+			}
+		}
+	}
+	return toProtocol(doc.lines, lexer.NewPosition(len(doc.lines), utf8.RuneCountInString(doc.lines[len(doc.lines)-1])))
+}
+
+// formatting returns the edits turning the document into its canonical form.
+func (doc document) formatting() monad.Result[[]textEdit, error] {
+	formatted := format.Source(doc.text)
+	if formatted.Failure() {
+		return monad.Fail[[]textEdit, error](formatted.Error())
+	}
+	if formatted.Value() == doc.text {
+		return monad.Succeed[[]textEdit, error]([]textEdit{})
+	}
+	last := len(doc.lines) - 1
+	end := toProtocol(doc.lines, lexer.NewPosition(last+1, utf8.RuneCountInString(doc.lines[last])))
+	return monad.Succeed[[]textEdit, error]([]textEdit{{
+		Range:   textRange{End: end},
+		NewText: formatted.Value(),
+	}})
+}
+
+// message returns the message of an error, without the position diagnostics
+// carry, which is irrelevant on hover.
+func message(err error) string {
+	var d diagnostic.Diagnostic
+	if errors.As(err, &d) {
+		return d.Message()
+	}
+	return err.Error()
+}
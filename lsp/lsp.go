@@ -0,0 +1,311 @@
+// Package lsp implements a Language Server Protocol server for λ.c.
+//
+// Overview:
+//
+// The server speaks JSON-RPC over a pair of streams, typically the standard
+// input and output of `lambdac lsp` launched by an editor. Documents are
+// synchronized in full: every change sends the whole text, which is analyzed
+// again from scratch, as λ.c files are small. The server offers:
+//
+//   - Diagnostics from the lexer, parser, resolver, linter and recursion
+//     detection, published whenever a document is opened or changed.
+//   - Go-to-definition for identifiers, and for `alias->name`, which leads to
+//     the import of the alias.
+//   - Hover, showing the definition of a name and its normal form, computed
+//     within HoverSteps reduction steps and HoverTimeout.
+//   - Document symbols for the top-level `:=` definitions.
+//   - Formatting, in the canonical form of the format package.
+//
+// Usage:
+//
+//	err := lsp.New(os.Stdin, os.Stdout).Run(ctx)
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrExitWithoutShutdown is returned by Run when the client asks the server to
+// exit without asking it to shut down first, which the protocol deems
+// abnormal.
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown")
+
+// Server is a language server reading requests from one stream and writing
+// responses and notifications to another.
+type Server struct {
+	in          io.Reader
+	out         io.Writer
+	documents   map[string]document
+	initialized bool
+	shutdown    bool
+}
+
+// New returns a Server reading from in and writing to out.
+func New(in io.Reader, out io.Writer) *Server {
+	return &Server{in: in, out: out, documents: map[string]document{}}
+}
+
+// handler handles a request or notification, and returns the result of
+// requests.
+type handler func(s *Server, ctx context.Context, params json.RawMessage) (any, error)
+
+// handlers maps the supported methods to their handler.
+var handlers = map[string]handler{
+	"initialize":                  (*Server).initialize,
+	"initialized":                 ignore,
+	"shutdown":                    (*Server).shutDown,
+	"textDocument/didOpen":        (*Server).didOpen,
+	"textDocument/didChange":      (*Server).didChange,
+	"textDocument/didClose":       (*Server).didClose,
+	"textDocument/definition":     (*Server).definition,
+	"textDocument/hover":          (*Server).hover,
+	"textDocument/documentSymbol": (*Server).documentSymbol,
+	"textDocument/formatting":     (*Server).formatting,
+}
+
+// Run serves requests until the client sends the exit notification, the input
+// ends or ctx is done. It returns nil after an orderly shutdown or at the end
+// of the input.
+func (s *Server) Run(ctx context.Context) error {
+	type incoming struct {
+		content []byte
+		err     error
+	}
+	messages := make(chan incoming)
+	go func() {
+		r := bufio.NewReader(s.in)
+		for {
+			content, err := readMessage(r)
+			select {
+			case messages <- incoming{content, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m := <-messages:
+			if errors.Is(m.err, io.EOF) {
+				return nil
+			}
+			if m.err != nil {
+				return m.err
+			}
+			var req request
+			if err := json.Unmarshal(m.content, &req); err != nil {
+				if err := s.respond(nil, nil, &responseError{codeParseError, err.Error()}); err != nil {
+					return err
+				}
+				continue
+			}
+			if req.Method == "exit" {
+				if !s.shutdown {
+					return ErrExitWithoutShutdown
+				}
+				return nil
+			}
+			if err := s.handle(ctx, req); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// handle dispatches a request to its handler and responds to it. It only
+// fails when the response cannot be written.
+func (s *Server) handle(ctx context.Context, req request) error {
+	h, ok := handlers[req.Method]
+	var result any
+	var err error
+	switch {
+	case !ok:
+		err = &responseError{codeMethodNotFound, fmt.Sprintf("method %q not supported", req.Method)}
+	case !s.initialized && req.Method != "initialize":
+		err = &responseError{codeServerNotInitialized, "server not initialized"}
+	case s.shutdown:
+		err = &responseError{codeInvalidRequest, "server is shutting down"}
+	default:
+		result, err = h(s, ctx, req.Params)
+	}
+
+	if req.isNotification() {
+		return nil
+	}
+	var failure *responseError
+	if err != nil && !errors.As(err, &failure) {
+		failure = &responseError{codeRequestFailed, err.Error()}
+	}
+	return s.respond(req.ID, result, failure)
+}
+
+// respond writes the response to the request of the given ID.
+func (s *Server) respond(id json.RawMessage, result any, failure *responseError) error {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	res := response{JSONRPC: "2.0", ID: id, Error: failure}
+	if failure == nil {
+		content, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		res.Result = content
+	}
+	return writeMessage(s.out, res)
+}
+
+// notify sends a notification to the client.
+func (s *Server) notify(method string, params any) error {
+	return writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// decode unmarshals the parameters of a request.
+func decode[T any](params json.RawMessage) (T, error) {
+	var v T
+	if err := json.Unmarshal(params, &v); err != nil {
+		return v, &responseError{codeInvalidParams, err.Error()}
+	}
+	return v, nil
+}
+
+// ignore handles the notifications that require no action.
+func ignore(*Server, context.Context, json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+func (s *Server) initialize(context.Context, json.RawMessage) (any, error) {
+	s.initialized = true
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":           syncFull,
+			"definitionProvider":         true,
+			"hoverProvider":              true,
+			"documentSymbolProvider":     true,
+			"documentFormattingProvider": true,
+		},
+		"serverInfo": map[string]string{"name": "lambdac"},
+	}, nil
+}
+
+func (s *Server) shutDown(context.Context, json.RawMessage) (any, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+func (s *Server) didOpen(_ context.Context, params json.RawMessage) (any, error) {
+	p, err := decode[didOpenParams](params)
+	if err != nil {
+		return nil, err
+	}
+	return nil, s.update(analyze(p.TextDocument.URI, p.TextDocument.Text))
+}
+
+func (s *Server) didChange(_ context.Context, params json.RawMessage) (any, error) {
+	p, err := decode[didChangeParams](params)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.ContentChanges) == 0 {
+		return nil, nil
+	}
+	text := p.ContentChanges[len(p.ContentChanges)-1].Text
+	return nil, s.update(analyze(p.TextDocument.URI, text))
+}
+
+func (s *Server) didClose(_ context.Context, params json.RawMessage) (any, error) {
+	p, err := decode[didCloseParams](params)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.documents, p.TextDocument.URI)
+	return nil, s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI: p.TextDocument.URI, Diagnostics: []protocolDiagnostic{},
+	})
+}
+
+// update stores a freshly analyzed document and publishes its diagnostics.
+func (s *Server) update(doc document) error {
+	s.documents[doc.uri] = doc
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI: doc.uri, Diagnostics: doc.protocolDiagnostics(),
+	})
+}
+
+// document returns the open document a request refers to.
+func (s *Server) document(uri string) (document, error) {
+	doc, ok := s.documents[uri]
+	if !ok {
+		return document{}, &responseError{codeInvalidParams, fmt.Sprintf("document %q is not open", uri)}
+	}
+	return doc, nil
+}
+
+func (s *Server) definition(_ context.Context, params json.RawMessage) (any, error) {
+	p, err := decode[textDocumentPositionParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	if l := doc.definition(fromProtocol(doc.lines, p.Position)); l.Just() {
+		return l.Value(), nil
+	}
+	return nil, nil
+}
+
+func (s *Server) hover(ctx context.Context, params json.RawMessage) (any, error) {
+	p, err := decode[textDocumentPositionParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	if h := doc.hover(ctx, fromProtocol(doc.lines, p.Position)); h.Just() {
+		return h.Value(), nil
+	}
+	return nil, nil
+}
+
+func (s *Server) documentSymbol(_ context.Context, params json.RawMessage) (any, error) {
+	p, err := decode[documentParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	return doc.symbols(), nil
+}
+
+func (s *Server) formatting(_ context.Context, params json.RawMessage) (any, error) {
+	p, err := decode[documentParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	edits := doc.formatting()
+	if edits.Failure() {
+		return nil, &responseError{codeRequestFailed, message(edits.Error())}
+	}
+	return edits.Value(), nil
+}
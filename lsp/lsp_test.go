@@ -0,0 +1,269 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// client is a scripted JSON-RPC client talking to a Server over pipes.
+type client struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *bufio.Reader
+	nextID int
+	done   chan error
+}
+
+// start runs a Server and returns a client connected to it.
+func start(t *testing.T) *client {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{t: t, in: clientOut, out: bufio.NewReader(clientIn), done: make(chan error, 1)}
+	go func() {
+		c.done <- New(serverIn, serverOut).Run(context.Background())
+		serverOut.Close()
+	}()
+	t.Cleanup(func() { clientOut.Close() })
+	return c
+}
+
+// send writes a message to the server.
+func (c *client) send(message map[string]any) {
+	c.t.Helper()
+	message["jsonrpc"] = "2.0"
+	require.NoError(c.t, writeMessage(c.in, message))
+}
+
+// receive reads the next message from the server.
+func (c *client) receive() map[string]any {
+	c.t.Helper()
+	content, err := readMessage(c.out)
+	require.NoError(c.t, err)
+	var message map[string]any
+	require.NoError(c.t, json.Unmarshal(content, &message))
+	return message
+}
+
+// request sends a request and returns its response.
+func (c *client) request(method string, params any) map[string]any {
+	c.t.Helper()
+	c.nextID++
+	c.send(map[string]any{"id": c.nextID, "method": method, "params": params})
+	response := c.receive()
+	require.Equal(c.t, float64(c.nextID), response["id"])
+	return response
+}
+
+// notify sends a notification.
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	c.send(map[string]any{"method": method, "params": params})
+}
+
+// open initializes the server, opens a document and returns the diagnostics
+// published for it.
+func (c *client) open(uri, text string) []any {
+	c.t.Helper()
+	c.request("initialize", map[string]any{})
+	c.notify("initialized", map[string]any{})
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lambdac", "version": 1, "text": text},
+	})
+	published := c.receive()
+	require.Equal(c.t, "textDocument/publishDiagnostics", published["method"])
+	return published["params"].(map[string]any)["diagnostics"].([]any)
+}
+
+// at builds the parameters designating a position in a document.
+func at(uri string, line, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": line, "character": character},
+	}
+}
+
+// span builds the JSON form of a range within a single line.
+func span(line, start, end int) map[string]any {
+	return map[string]any{
+		"start": map[string]any{"line": float64(line), "character": float64(start)},
+		"end":   map[string]any{"line": float64(line), "character": float64(end)},
+	}
+}
+
+const program = `io | "std/io"
+two := \f.\x.f (f x)
+id := \x.x
+main := two id (io->read y)
+`
+
+func TestLifecycle(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	c := start(t)
+
+	response := c.request("textDocument/hover", at("file:///a.lc", 0, 0))
+	is.Equal(float64(codeServerNotInitialized), response["error"].(map[string]any)["code"])
+
+	response = c.request("initialize", map[string]any{})
+	capabilities := response["result"].(map[string]any)["capabilities"].(map[string]any)
+	is.Equal(float64(syncFull), capabilities["textDocumentSync"])
+	is.Equal(true, capabilities["hoverProvider"])
+
+	response = c.request("textDocument/rename", map[string]any{})
+	is.Equal(float64(codeMethodNotFound), response["error"].(map[string]any)["code"])
+
+	response = c.request("shutdown", nil)
+	is.Contains(response, "result")
+	is.Nil(response["result"])
+	c.notify("exit", nil)
+	is.NoError(<-c.done)
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	t.Parallel()
+	c := start(t)
+
+	c.notify("exit", nil)
+	require.ErrorIs(t, <-c.done, ErrExitWithoutShutdown)
+}
+
+func TestDiagnostics(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	c := start(t)
+
+	diagnostics := c.open("file:///a.lc", "identity := \\x.x\nmain := identity identiy λy\n")
+	is.Len(diagnostics, 2)
+	unbound := diagnostics[0].(map[string]any)
+	is.Equal(span(1, 17, 24), unbound["range"])
+	is.Equal(float64(severityError), unbound["severity"])
+	is.Equal(`unbound identifier "identiy" (did you mean "identity"?)`, unbound["message"])
+	is.Equal(span(1, 25, 27), diagnostics[1].(map[string]any)["range"])
+
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": "file:///a.lc", "version": 2},
+		"contentChanges": []any{map[string]any{"text": "main := (\\x.x"}},
+	})
+	published := c.receive()["params"].(map[string]any)
+	is.Len(published["diagnostics"], 1)
+	is.Equal("unclosed parenthesis", published["diagnostics"].([]any)[0].(map[string]any)["message"])
+
+	c.notify("textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": "file:///a.lc"}})
+	is.Empty(c.receive()["params"].(map[string]any)["diagnostics"])
+	response := c.request("textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": "file:///a.lc"}})
+	is.Equal(float64(codeInvalidParams), response["error"].(map[string]any)["code"])
+}
+
+func TestDefinition(t *testing.T) {
+	t.Parallel()
+	c := start(t)
+	c.open("file:///a.lc", program)
+
+	testCases := []struct {
+		name           string
+		line, char     int
+		expectedTarget any
+	}{
+		{"definition", 3, 9, span(1, 0, 3)},
+		{"parameter", 1, 13, span(1, 8, 9)},
+		{"binding site", 2, 1, span(2, 0, 2)},
+		{"namespace alias", 3, 17, span(0, 0, 2)},
+		{"dereferenced name", 3, 21, span(0, 0, 2)},
+		{"operator", 1, 4, nil},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			response := c.request("textDocument/definition", at("file:///a.lc", testCase.line, testCase.char))
+			if testCase.expectedTarget == nil {
+				require.Nil(t, response["result"])
+				return
+			}
+			result := response["result"].(map[string]any)
+			require.Equal(t, "file:///a.lc", result["uri"])
+			require.Equal(t, testCase.expectedTarget, result["range"])
+		})
+	}
+}
+
+func TestHover(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	c := start(t)
+	c.open("file:///a.lc", program+"ω := (\\x.x x) (\\x.x x)\n")
+
+	contents := func(line, char int) string {
+		result := c.request("textDocument/hover", at("file:///a.lc", line, char))["result"].(map[string]any)
+		return result["contents"].(map[string]any)["value"].(string)
+	}
+
+	is.Equal("```lambdac\ntwo := \\f.\\x.f (f x)\n```\nnormal form:\n```lambdac\n\\f.\\x.f (f x)\n```", contents(3, 8))
+	is.Equal("```lambdac\nf\n```\nparameter", contents(1, 13))
+	is.Equal("```lambdac\nio | \"std/io\"\n```\nnamespace", contents(3, 16))
+	is.Contains(contents(4, 0), "no normal form: step limit exceeded: gave up after 10000 steps")
+	is.Nil(c.request("textDocument/hover", at("file:///a.lc", 3, 5))["result"])
+}
+
+func TestDocumentSymbol(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	c := start(t)
+	c.open("file:///a.lc", program)
+
+	response := c.request("textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": "file:///a.lc"}})
+	symbols := response["result"].([]any)
+	is.Len(symbols, 3)
+	two := symbols[0].(map[string]any)
+	is.Equal("two", two["name"])
+	is.Equal(float64(symbolFunction), two["kind"])
+	is.Equal(span(1, 0, 20), two["range"])
+	is.Equal(span(1, 0, 3), two["selectionRange"])
+	is.Equal("main", symbols[2].(map[string]any)["name"])
+}
+
+func TestFormatting(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	c := start(t)
+	c.open("file:///a.lc", "i:=\\x. x\n")
+
+	params := map[string]any{"textDocument": map[string]any{"uri": "file:///a.lc"}}
+	edits := c.request("textDocument/formatting", params)["result"].([]any)
+	is.Len(edits, 1)
+	edit := edits[0].(map[string]any)
+	is.Equal("i := \\x.x\n", edit["newText"])
+	is.Equal(map[string]any{
+		"start": map[string]any{"line": float64(0), "character": float64(0)},
+		"end":   map[string]any{"line": float64(1), "character": float64(0)},
+	}, edit["range"])
+
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": "file:///a.lc"},
+		"contentChanges": []any{map[string]any{"text": "i := \\x.x\n"}},
+	})
+	c.receive()
+	is.Empty(c.request("textDocument/formatting", params)["result"])
+
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": "file:///a.lc"},
+		"contentChanges": []any{map[string]any{"text": "i := (\n"}},
+	})
+	c.receive()
+	is.Equal(float64(codeRequestFailed), c.request("textDocument/formatting", params)["error"].(map[string]any)["code"])
+}
+
+func TestPositions(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	lines := []string{"𝕀 := \\x.x"}
+	is.Equal(position{Line: 0, Character: 3}, toProtocol(lines, fromProtocol(lines, position{Line: 0, Character: 3})))
+	is.Equal(2, fromProtocol(lines, position{Line: 0, Character: 3}).Col())
+	is.Equal(position{Line: 0, Character: 2}, toProtocol(lines, fromProtocol(lines, position{Line: 0, Character: 2})))
+}
@@ -0,0 +1,163 @@
+package lsp
+
+import (
+	"unicode/utf8"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+// The types below are the subset of the Language Server Protocol the server
+// speaks. Field names follow the specification.
+
+// position is a location in a text document: a zero-based line and a
+// zero-based character offset counted in UTF-16 code units.
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// textRange is a range in a text document, end exclusive.
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type location struct {
+	URI   string    `json:"uri"`
+	Range textRange `json:"range"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentItem struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	// ContentChanges carries the whole text of the document, as the server
+	// only supports full synchronization.
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type didCloseParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     position               `json:"position"`
+}
+
+type documentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type protocolDiagnostic struct {
+	Range    textRange `json:"range"`
+	Severity int       `json:"severity"`
+	Source   string    `json:"source"`
+	Message  string    `json:"message"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string               `json:"uri"`
+	Diagnostics []protocolDiagnostic `json:"diagnostics"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    textRange     `json:"range"`
+}
+
+type documentSymbol struct {
+	Name           string    `json:"name"`
+	Detail         string    `json:"detail,omitempty"`
+	Kind           int       `json:"kind"`
+	Range          textRange `json:"range"`
+	SelectionRange textRange `json:"selectionRange"`
+}
+
+type textEdit struct {
+	Range   textRange `json:"range"`
+	NewText string    `json:"newText"`
+}
+
+// Constants of the protocol.
+const (
+	syncFull = 1
+
+	severityError   = 1
+	severityWarning = 2
+
+	symbolFunction = 12
+)
+
+// severity converts the severity of a diagnostic.
+func severity(s diagnostic.Severity) int {
+	if s == diagnostic.Warning {
+		return severityWarning
+	}
+	return severityError
+}
+
+// toProtocol converts a lexer position, whose row starts at 1 and whose
+// column counts runes, to a protocol position within lines.
+func toProtocol(lines []string, p lexer.Position) position {
+	line := p.Row() - 1
+	if line < 0 || line >= len(lines) {
+		return position{Line: max(line, 0)}
+	}
+	character := 0
+	col := 0
+	for _, r := range lines[line] {
+		if col == p.Col() {
+			break
+		}
+		character += utf16Len(r)
+		col++
+	}
+	return position{Line: line, Character: character + p.Col() - col}
+}
+
+// fromProtocol converts a protocol position within lines to a lexer
+// position.
+func fromProtocol(lines []string, p position) lexer.Position {
+	if p.Line < 0 || p.Line >= len(lines) {
+		return lexer.NewPosition(p.Line+1, p.Character)
+	}
+	character := 0
+	col := 0
+	for _, r := range lines[p.Line] {
+		if character >= p.Character {
+			break
+		}
+		character += utf16Len(r)
+		col++
+	}
+	return lexer.NewPosition(p.Line+1, col)
+}
+
+// utf16Len returns the number of UTF-16 code units encoding r.
+func utf16Len(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
@@ -1,10 +1,18 @@
-// Package main implements the lambdac compiler entry point.
+// Package main implements the lambdac compiler entry point. It is equivalent
+// to cmd/lambdac, and kept so that "go install ." keeps working.
 package main
 
 import (
+	"context"
+	"os"
+
 	// importing ragel to force presence in go.mod
 	_ "github.com/db47h/ragel/v2"
+
+	"github.com/denisdubochevalier/lambdac/driver"
 )
 
 //go:generate ragel -Z -G2 -o lex.go lex.rl
-func main() {}
+func main() {
+	os.Exit(driver.Main(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
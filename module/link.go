@@ -0,0 +1,104 @@
+package module
+
+import (
+	"strings"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Link converts the modules of the graph into a single eval.Program. The
+// definitions of the root module keep their names, while those of imported
+// modules are qualified with their import path, and every reference is
+// renamed accordingly. The expressions of the Program are those of the root
+// module: bare expressions of imported modules are ignored.
+func (g Graph) Link() monad.Result[eval.Program, error] {
+	env := eval.NewEnvironment()
+	var expressions []term.Term
+	root := g.Root().path
+	for _, m := range g.modules {
+		names := m.definitions()
+		for _, statement := range m.program.Children() {
+			switch statement.NodeType() {
+			case lexer.MODULE, lexer.EOF:
+				continue
+			case lexer.ASSIGN:
+				children := statement.Children()
+				t := term.FromAST(children[1])
+				if t.Failure() {
+					return monad.Fail[eval.Program, error](Error{file: m.file, source: m.source, err: t.Error()})
+				}
+				name := children[0].Token().Literal().String()
+				env = env.Define(m.global(root, name), m.qualify(root, t.Value(), names, nil))
+			default:
+				if m.path != root {
+					continue
+				}
+				t := term.FromAST(statement)
+				if t.Failure() {
+					return monad.Fail[eval.Program, error](Error{file: m.file, source: m.source, err: t.Error()})
+				}
+				expressions = append(expressions, m.qualify(root, t.Value(), names, nil))
+			}
+		}
+	}
+	return monad.Succeed[eval.Program, error](eval.NewProgram(env, expressions...))
+}
+
+// definitions returns the names of the top-level definitions of the module.
+func (m Module) definitions() map[string]bool {
+	names := map[string]bool{}
+	for _, statement := range m.program.Children() {
+		if statement.NodeType() == lexer.ASSIGN {
+			names[statement.Children()[0].Token().Literal().String()] = true
+		}
+	}
+	return names
+}
+
+// global returns the name a definition of the module bears once linked in a
+// graph rooted at the module of the given path.
+func (m Module) global(root, name string) string {
+	if m.path == root {
+		return name
+	}
+	return term.Qualify(m.path, name)
+}
+
+// qualify renames the free variables of t referring to the definitions of the
+// module, whose names are given, or of the modules it imports. Global names
+// contain the namespace dereference operator, which no identifier does, so
+// that renaming cannot capture anything.
+func (m Module) qualify(root string, t term.Term, names, bound map[string]bool) term.Term {
+	switch t := t.(type) {
+	case term.Var:
+		name := t.Name()
+		if bound[name] {
+			return t
+		}
+		if names[name] {
+			return term.NewVar(m.global(root, name)).WithPosition(t.Position())
+		}
+		if alias, rest, ok := strings.Cut(name, lexer.NSDEREF.String()); ok {
+			if imported, ok := m.imports[alias]; ok {
+				return term.NewVar(Module{path: imported}.global(root, rest)).WithPosition(t.Position())
+			}
+		}
+		return t
+	case term.Abs:
+		inner := make(map[string]bool, len(bound)+1)
+		for name := range bound {
+			inner[name] = true
+		}
+		inner[t.Param()] = true
+		return term.NewAbs(t.Param(), m.qualify(root, t.Body(), names, inner)).WithPosition(t.Position())
+	case term.App:
+		return term.NewApp(
+			m.qualify(root, t.Fun(), names, bound), m.qualify(root, t.Arg(), names, bound),
+		).WithPosition(t.Position())
+	}
+	return t
+}
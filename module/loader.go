@@ -0,0 +1,410 @@
+package module
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/cache"
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+	"github.com/denisdubochevalier/lambdac/stdlib"
+)
+
+// Root is a directory of the search path.
+type Root struct {
+	name   string
+	fsys   fs.FS
+	prefix string
+}
+
+// Dir returns the Root of a directory of the file system.
+func Dir(dir string) Root {
+	return Root{name: dir, fsys: os.DirFS(dir)}
+}
+
+// FS returns a Root reading modules from fsys, such as an embed.FS. The name
+// stands for the root in file names.
+func FS(name string, fsys fs.FS) Root {
+	return Root{name: name, fsys: fsys}
+}
+
+// WithPrefix returns a copy of the root only providing the import paths
+// starting with the given prefix, which is stripped to find the module file:
+// with the prefix example.com/p, the import path example.com/p/util
+// designates the file util.lc of the root.
+func (r Root) WithPrefix(prefix string) Root {
+	r.prefix = strings.TrimSuffix(prefix, "/")
+	return r
+}
+
+// Name returns the name of the root.
+func (r Root) Name() string {
+	return r.name
+}
+
+// file returns the name of the file of the root that would hold the module
+// of the given import path, if the root can provide it.
+func (r Root) file(importPath string) (string, bool) {
+	if r.prefix == "" {
+		return importPath + Extension, true
+	}
+	rest, ok := strings.CutPrefix(importPath, r.prefix+"/")
+	return rest + Extension, ok
+}
+
+// importPath returns the import path of a file of the root.
+func (r Root) importPath(file string) string {
+	importPath := strings.TrimSuffix(file, Extension)
+	if r.prefix == "" {
+		return importPath
+	}
+	return r.prefix + "/" + importPath
+}
+
+// SearchPath returns the default search path of a project rooted at the given
+// directory: the directory itself, then the EnvironmentRoots.
+func SearchPath(root string) []Root {
+	return append([]Root{Dir(root)}, EnvironmentRoots()...)
+}
+
+// StdlibName stands for the embedded standard library in file names.
+const StdlibName = "<stdlib>"
+
+// Stdlib returns the root of the standard library embedded in lambdac.
+func Stdlib() Root {
+	return FS(StdlibName, stdlib.FS)
+}
+
+// EnvironmentRoots returns the roots of the search path that the environment
+// configures: the directories listed in LAMBDAC_PATH, then the standard
+// library directory LAMBDAC_STDLIB if set, the embedded Stdlib otherwise.
+func EnvironmentRoots() []Root {
+	var roots []Root
+	for _, dir := range filepath.SplitList(os.Getenv("LAMBDAC_PATH")) {
+		if dir != "" {
+			roots = append(roots, Dir(dir))
+		}
+	}
+	if dir := os.Getenv("LAMBDAC_STDLIB"); dir != "" {
+		return append(roots, Dir(dir))
+	}
+	return append(roots, Stdlib())
+}
+
+// HostName stands for the host application in the file names of the
+// modules it provides.
+const HostName = "<host>"
+
+// Host provides modules that are not written in λ.c, but consist of the
+// primitives of the application embedding λ.c (see package host). Linking
+// leaves the definitions of host modules undefined, so that their global
+// names, such as host/os->getenv, are free variables of the linked program,
+// for evaluators to apply as primitives (see eval.Primitive).
+type Host interface {
+	// Definitions returns the names the host module of the given import path
+	// defines, or None when the host provides no such module.
+	Definitions(importPath string) monad.Maybe[[]string]
+}
+
+// Loader loads modules from a search path. It follows the same immutable
+// builder pattern as the lexer: configuration methods return updated copies.
+type Loader struct {
+	roots []Root
+	cache cache.Cache
+	host  Host
+}
+
+// NewLoader returns a Loader looking for imported modules in the given
+// roots, in order.
+func NewLoader(roots ...Root) Loader {
+	return Loader{roots: roots}
+}
+
+// WithRoots returns a copy of the loader with the given roots appended to
+// its search path.
+func (l Loader) WithRoots(roots ...Root) Loader {
+	l.roots = append(append([]Root{}, l.roots...), roots...)
+	return l
+}
+
+// WithCache returns a copy of the loader storing the syntax trees and the
+// resolutions of the modules it loads in c, and reading them back instead of
+// parsing and resolving modules that did not change.
+func (l Loader) WithCache(c cache.Cache) Loader {
+	l.cache = c
+	return l
+}
+
+// WithHost returns a copy of the loader importing the modules provided by
+// host, which take precedence over the files of the search path.
+func (l Loader) WithHost(host Host) Loader {
+	l.host = host
+	return l
+}
+
+// Roots returns the search path of the loader.
+func (l Loader) Roots() []Root {
+	return append([]Root{}, l.roots...)
+}
+
+// Load parses the source text of the named file, then loads the modules it
+// imports, directly or not.
+func (l Loader) Load(file, source string) monad.Result[Graph, error] {
+	s := &loading{loader: l, loaded: map[string]int{}}
+	if err := s.visit("", file, source); err != nil {
+		return monad.Fail[Graph, error](err)
+	}
+	return monad.Succeed[Graph, error](Graph{modules: s.modules, root: len(s.modules) - 1})
+}
+
+// LoadTree loads every source file of a root, along with the modules they
+// import, each file being the module of the import path the root provides it
+// under. The root comes first in the search path, so that the files can
+// import each other. Files are loaded in lexical order, and directories whose
+// name starts with a dot or that hold a Manifest of their own are skipped.
+// The root of the returned graph is the last module loaded; see
+// Graph.WithRoot.
+func (l Loader) LoadTree(root Root) monad.Result[Graph, error] {
+	var files []string
+	err := fs.WalkDir(root.fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case entry.IsDir() && name != ".":
+			if strings.HasPrefix(entry.Name(), ".") {
+				return fs.SkipDir
+			}
+			if _, err := fs.Stat(root.fsys, path.Join(name, ManifestName)); err == nil {
+				return fs.SkipDir
+			}
+		case !entry.IsDir() && path.Ext(name) == Extension:
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return monad.Fail[Graph, error](err)
+	}
+	if len(files) == 0 {
+		return monad.Fail[Graph, error](fmt.Errorf("no %s files in %s", Extension, root.name))
+	}
+
+	s := &loading{loader: NewLoader(root).WithRoots(l.roots...).WithCache(l.cache).WithHost(l.host), loaded: map[string]int{}}
+	for _, name := range files {
+		importPath := root.importPath(name)
+		if _, ok := s.loaded[importPath]; ok {
+			continue
+		}
+		content, err := fs.ReadFile(root.fsys, name)
+		if err != nil {
+			return monad.Fail[Graph, error](err)
+		}
+		if err := s.visit(importPath, filepath.Join(root.name, filepath.FromSlash(name)), string(content)); err != nil {
+			return monad.Fail[Graph, error](err)
+		}
+	}
+	return monad.Succeed[Graph, error](Graph{modules: s.modules, root: len(s.modules) - 1})
+}
+
+// loading is the state of a Load in progress.
+type loading struct {
+	loader  Loader
+	modules []Module
+	// loaded maps the import paths of the modules loaded so far to their
+	// index in modules.
+	loaded map[string]int
+	// stack lists the import paths of the modules being loaded, the last one
+	// importing the module visited next.
+	stack []string
+}
+
+// visit parses a module, loads the modules it imports and resolves it.
+func (s *loading) visit(importPath, file, source string) error {
+	ast := s.parse(source)
+	if ast.Failure() {
+		return Error{file: file, source: source, err: ast.Error()}
+	}
+	m := Module{
+		path:    importPath,
+		file:    file,
+		source:  source,
+		program: ast.Value(),
+		imports: map[string]string{},
+	}
+
+	s.stack = append(s.stack, importPath)
+	defer func() { s.stack = s.stack[:len(s.stack)-1] }()
+
+	resolutions := map[string]resolver.Import{}
+	for _, statement := range m.program.Children() {
+		if statement.NodeType() != lexer.MODULE {
+			continue
+		}
+		alias := statement.Children()[0].Token().Literal().String()
+		target := statement.Children()[1].Token()
+		if _, duplicate := m.imports[alias]; duplicate {
+			// Reported by the resolver
+			continue
+		}
+		imported, err := s.dependency(target)
+		if err != nil {
+			return Error{file: file, source: source, err: err}.flatten()
+		}
+		m.imports[alias] = imported.path
+		resolutions[alias] = resolver.NewImport(imported.file, imported.resolution)
+	}
+
+	inputs := []string{"module", importPath, source}
+	aliases := make([]string, 0, len(m.imports))
+	for alias := range m.imports {
+		aliases = append(aliases, alias)
+	}
+	slices.Sort(aliases)
+	files := []string{"resolution"}
+	for _, alias := range aliases {
+		imported := s.modules[s.loaded[m.imports[alias]]]
+		inputs = append(inputs, alias, imported.key.String())
+		// Diagnostics name the files of the imported modules
+		files = append(files, imported.file)
+	}
+	m.key = cache.NewKey(inputs...)
+	m.resolution = s.resolve(cache.NewKey(append(files, m.key.String())...), m.program, resolutions)
+	s.loaded[importPath] = len(s.modules)
+	s.modules = append(s.modules, m)
+	return nil
+}
+
+// declare adds the module of the host defining the given names, which has no
+// source text.
+func (s *loading) declare(importPath string, names []string) {
+	m := Module{
+		path:       importPath,
+		file:       filepath.Join(HostName, filepath.FromSlash(importPath)),
+		program:    parser.NewProgram(),
+		resolution: resolver.Declare(names...),
+		imports:    map[string]string{},
+		key:        cache.NewKey(append([]string{"host", importPath}, names...)...),
+	}
+	s.loaded[importPath] = len(s.modules)
+	s.modules = append(s.modules, m)
+}
+
+// parse parses the source text of a module, unless the cache of the loader
+// holds its syntax tree.
+func (s *loading) parse(source string) monad.Result[parser.ASTNode, error] {
+	key := cache.NewKey("syntax", source)
+	if data := s.loader.cache.Get(key); data.Just() {
+		if ast := parser.Decode(data.Value()); ast.Success() {
+			return ast
+		}
+	}
+	ast := parser.ParseString(source)
+	if ast.Success() {
+		// The cache is an optimization: failing to fill it is not an error
+		_ = s.loader.cache.Put(key, parser.Encode(ast.Value()))
+	}
+	return ast
+}
+
+// resolve resolves a module against the modules it imports, unless the cache
+// of the loader holds its resolution under key.
+func (s *loading) resolve(key cache.Key, program parser.ASTNode, imports map[string]resolver.Import) resolver.Resolution {
+	if data := s.loader.cache.Get(key); data.Just() {
+		if resolution := resolver.Decode(data.Value()); resolution.Success() {
+			return resolution.Value()
+		}
+	}
+	resolution := resolver.Resolve(program).ResolveQualified(imports)
+	// The cache is an optimization: failing to fill it is not an error
+	_ = s.loader.cache.Put(key, resolver.Encode(resolution))
+	return resolution
+}
+
+// dependency returns the module imported by the given STRING token, loading
+// it if need be.
+func (s *loading) dependency(target lexer.Token) (Module, error) {
+	importPath := target.Literal().String()
+	if !fs.ValidPath(importPath) || importPath == "." || strings.HasSuffix(importPath, Extension) {
+		return Module{}, diagnostic.Errorf(target.Position(), "invalid import path %q", importPath).
+			WithHint(`import paths are slash-separated and have no extension, such as "std/list"`)
+	}
+	for i, p := range s.stack {
+		if p == importPath {
+			chain := append(append([]string{}, s.stack[i:]...), importPath)
+			return Module{}, diagnostic.Errorf(target.Position(), "import cycle: %s", quoteChain(chain))
+		}
+	}
+	if i, ok := s.loaded[importPath]; ok {
+		return s.modules[i], nil
+	}
+	if s.loader.host != nil {
+		if names := s.loader.host.Definitions(importPath); names.Just() {
+			s.declare(importPath, names.Value())
+			return s.modules[len(s.modules)-1], nil
+		}
+	}
+
+	for _, root := range s.loader.roots {
+		name, ok := root.file(importPath)
+		if !ok {
+			continue
+		}
+		content, err := fs.ReadFile(root.fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		file := filepath.Join(root.name, filepath.FromSlash(name))
+		if err != nil {
+			return Module{}, err
+		}
+		if err := s.visit(importPath, file, string(content)); err != nil {
+			return Module{}, err
+		}
+		return s.modules[len(s.modules)-1], nil
+	}
+
+	names := make([]string, 0, len(s.loader.roots))
+	for _, root := range s.loader.roots {
+		names = append(names, root.name)
+	}
+	return Module{}, diagnostic.Errorf(target.Position(), "cannot find module %q", importPath).
+		WithHint("searched %s", strings.Join(names, ", "))
+}
+
+// flatten returns the innermost Error when the error wraps one, which
+// happens when the failure stems from an imported module.
+func (e Error) flatten() Error {
+	var inner Error
+	if errors.As(e.err, &inner) {
+		return inner
+	}
+	return e
+}
+
+// quoteChain renders a chain of imports, such as `"a" imports "b", which
+// imports "a"`.
+func quoteChain(chain []string) string {
+	var b strings.Builder
+	for i, p := range chain {
+		switch i {
+		case 0:
+		case 1:
+			b.WriteString(" imports ")
+		default:
+			b.WriteString(", which imports ")
+		}
+		b.WriteString(strconv.Quote(p))
+	}
+	return b.String()
+}
@@ -0,0 +1,163 @@
+package module
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+// ManifestName is the name of the file marking the root of a project.
+const ManifestName = "lambdac.mod"
+
+// DefaultEntry is the entry point of projects whose manifest does not name
+// one.
+const DefaultEntry = "main"
+
+// Manifest describes a project: the directory it is rooted at, the import
+// path of that directory, the definition programs start from, and additional
+// directories to look for modules in. It is read from a lambdac.mod file made
+// of directives, one per line, comments starting with "--" as in λ.c:
+//
+//	-- lambdac.mod
+//	module example.com/calculator
+//	entry main
+//	path vendor ../shared
+//
+// All directives are optional. The directories of path are relative to the
+// manifest.
+type Manifest struct {
+	dir    string
+	module string
+	entry  string
+	path   []string
+}
+
+// DefaultManifest returns the manifest of a project rooted at dir that has no
+// lambdac.mod file.
+func DefaultManifest(dir string) Manifest {
+	return Manifest{dir: dir, entry: DefaultEntry}
+}
+
+// Dir returns the root directory of the project.
+func (m Manifest) Dir() string {
+	return m.dir
+}
+
+// Module returns the import path of the root directory of the project, or the
+// empty string if the manifest does not name one, in which case modules of
+// the project are imported by their path relative to the root directory.
+func (m Manifest) Module() string {
+	return m.module
+}
+
+// Entry returns the name of the definition programs start from.
+func (m Manifest) Entry() string {
+	return m.entry
+}
+
+// Root returns the search path root of the project directory itself.
+func (m Manifest) Root() Root {
+	return Dir(m.dir).WithPrefix(m.module)
+}
+
+// SearchPath returns the search path of the project: its root directory,
+// the directories of the path directive, then the EnvironmentRoots.
+func (m Manifest) SearchPath() []Root {
+	roots := []Root{m.Root()}
+	for _, dir := range m.path {
+		roots = append(roots, Dir(filepath.Join(m.dir, filepath.FromSlash(dir))))
+	}
+	return append(roots, EnvironmentRoots()...)
+}
+
+// ParseManifest parses the content of the lambdac.mod file of the project
+// rooted at dir. Errors are diagnostics positioned in the content.
+func ParseManifest(dir, content string) monad.Result[Manifest, error] {
+	m := DefaultManifest(dir)
+	seen := map[string]bool{}
+	for i, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "--")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		position := lexer.NewPosition(i+1, strings.Index(line, fields[0]))
+		directive, args := fields[0], fields[1:]
+		if seen[directive] {
+			return monad.Fail[Manifest, error](diagnostic.Errorf(position, "duplicate %s directive", directive))
+		}
+		seen[directive] = true
+
+		switch directive {
+		case "module", "entry":
+			if len(args) != 1 {
+				return monad.Fail[Manifest, error](diagnostic.Errorf(
+					position, "the %s directive takes exactly one argument", directive,
+				))
+			}
+			if directive == "module" {
+				m.module = strings.TrimSuffix(args[0], "/")
+			} else {
+				m.entry = args[0]
+			}
+		case "path":
+			m.path = args
+		default:
+			return monad.Fail[Manifest, error](diagnostic.Errorf(
+				position, "unknown directive %q", directive,
+			).WithHint("expected module, entry or path"))
+		}
+	}
+	return monad.Succeed[Manifest, error](m)
+}
+
+// FindManifest looks for the lambdac.mod file of the project the directory
+// dir belongs to, in dir and its parents, and parses it. Without any, dir is
+// the root of a project with a DefaultManifest.
+func FindManifest(dir string) monad.Result[Manifest, error] {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return monad.Fail[Manifest, error](err)
+	}
+	for current := abs; ; current = filepath.Dir(current) {
+		file := filepath.Join(current, ManifestName)
+		content, err := os.ReadFile(file)
+		switch {
+		case err == nil:
+			root := relative(dir, abs, current)
+			manifest := ParseManifest(root, string(content))
+			if manifest.Failure() {
+				return monad.Fail[Manifest, error](NewError(
+					filepath.Join(root, ManifestName), string(content), manifest.Error(),
+				))
+			}
+			return manifest
+		case !errors.Is(err, fs.ErrNotExist):
+			return monad.Fail[Manifest, error](err)
+		}
+		if filepath.Dir(current) == current {
+			return monad.Succeed[Manifest, error](DefaultManifest(dir))
+		}
+	}
+}
+
+// relative returns the directory found while walking up from dir, whose
+// absolute path is abs, relative to dir when dir is relative, so that file
+// names in messages stay as the user typed them.
+func relative(dir, abs, found string) string {
+	if filepath.IsAbs(dir) {
+		return found
+	}
+	rel, err := filepath.Rel(abs, found)
+	if err != nil {
+		return found
+	}
+	return filepath.Join(dir, rel)
+}
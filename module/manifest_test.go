@@ -0,0 +1,78 @@
+package module
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseManifest(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	m := ParseManifest("proj", "-- calculator\nmodule example.com/calc/\n\nentry  start -- not main\npath vendor ../shared\n")
+	is.True(m.Success(), "%v", m.Error())
+	is.Equal("proj", m.Value().Dir())
+	is.Equal("example.com/calc", m.Value().Module())
+	is.Equal("start", m.Value().Entry())
+
+	names := []string{}
+	for _, root := range m.Value().SearchPath() {
+		names = append(names, root.Name())
+	}
+	is.Equal([]string{"proj", filepath.Join("proj", "vendor"), "shared"}, names[:3])
+
+	m = ParseManifest("proj", "")
+	is.True(m.Success())
+	is.Equal(DefaultManifest("proj"), m.Value())
+	is.Equal("main", m.Value().Entry())
+}
+
+func TestParseManifestErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		content string
+		message string
+	}{
+		{"module a\nmodule b", "2:0: error: duplicate module directive"},
+		{"entry", "1:0: error: the entry directive takes exactly one argument"},
+		{"  main := x", `1:2: error: unknown directive "main" (expected module, entry or path)`},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.content, func(t *testing.T) {
+			t.Parallel()
+			m := ParseManifest(".", testCase.content)
+			require.True(t, m.Failure())
+			require.Equal(t, testCase.message, m.Error().Error())
+		})
+	}
+}
+
+func TestFindManifest(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "a", "b")
+	is.NoError(os.MkdirAll(nested, 0o700))
+
+	m := FindManifest(nested)
+	is.True(m.Success(), "%v", m.Error())
+	is.Equal(DefaultManifest(nested), m.Value())
+
+	is.NoError(os.WriteFile(filepath.Join(dir, ManifestName), []byte("entry go\n"), 0o600))
+	m = FindManifest(nested)
+	is.True(m.Success(), "%v", m.Error())
+	is.Equal(dir, m.Value().Dir())
+	is.Equal("go", m.Value().Entry())
+
+	is.NoError(os.WriteFile(filepath.Join(dir, ManifestName), []byte("entry\n"), 0o600))
+	m = FindManifest(nested)
+	is.True(m.Failure())
+	is.Equal(filepath.Join(dir, ManifestName)+":1:0: error: the entry directive takes exactly one argument", m.Error().Error())
+}
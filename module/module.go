@@ -0,0 +1,229 @@
+// Package module loads λ.c programs spanning several files.
+//
+// Overview:
+//
+// An import statement `alias | "path"` designates another source file by an
+// import path: slash-separated, without extension, relative to one of the
+// directories of the search path. The import `std | "std/list"` loads the
+// first std/list.lc file found in:
+//
+//  1. the root of the project;
+//  2. the directories listed in the LAMBDAC_PATH environment variable;
+//  3. the standard library directory, LAMBDAC_STDLIB, by default the standard
+//     library embedded in lambdac (see package stdlib).
+//
+// Applications embedding λ.c can provide modules of their own, made of Go
+// functions rather than λ.c source, through a Host (see package host). Host
+// modules are found before the files of the search path.
+//
+// There is no package manager: a module is a file, and the hierarchy of
+// modules is the hierarchy of directories. Every top-level definition of a
+// module is exported, except those whose names start with an underscore (see
+// resolver.IsPrivate).
+//
+// Loading:
+//
+// A Loader parses a module, then the modules it imports, depth first, and
+// resolves each of them once the modules it depends on are resolved, so that
+// `alias->name` can be checked against the definitions of the imported module
+// (see resolver.Resolution.ResolveQualified). Modules imported several times
+// are loaded once. Imports forming a cycle are rejected, the error spelling
+// out the chain of imports. Given a build cache, the Loader reads back the
+// syntax trees and resolutions of the modules that did not change instead of
+// parsing and resolving them, and every Module carries a Key covering its
+// source and those of the modules it depends on, for tools to cache their own
+// artifacts.
+//
+// The result is a Graph of Modules in dependency order. Graph.Link turns it
+// into a single eval.Program, in which the definitions of an imported module
+// are named after the module: `read` in std/io becomes `std/io->read`, a name
+// no identifier can clash with.
+//
+// Errors:
+//
+// Problems that prevent loading, such as a syntax error or a missing module,
+// are returned as an Error telling which file they belong to. Problems that do
+// not, such as unbound identifiers, are left in the resolutions of the
+// modules, for callers to report along with their own diagnostics.
+package module
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/cache"
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+)
+
+// Extension is the extension of λ.c source files.
+const Extension = ".lc"
+
+// Module is a parsed and resolved source file.
+type Module struct {
+	path       string
+	file       string
+	source     string
+	program    parser.ASTNode
+	resolution resolver.Resolution
+	imports    map[string]string
+	key        cache.Key
+}
+
+// Path returns the import path of the module, or the empty string for the
+// module given to Loader.Load.
+func (m Module) Path() string {
+	return m.path
+}
+
+// File returns the name of the file the module was read from, as it should
+// be shown in messages.
+func (m Module) File() string {
+	return m.file
+}
+
+// Source returns the source text of the module.
+func (m Module) Source() string {
+	return m.source
+}
+
+// Program returns the syntax tree of the module.
+func (m Module) Program() parser.ASTNode {
+	return m.program
+}
+
+// WithProgram returns a copy of the module with another syntax tree, such as
+// the one returned by fixpoint.Rewrite. The resolution is left untouched.
+func (m Module) WithProgram(program parser.ASTNode) Module {
+	m.program = program
+	return m
+}
+
+// Resolution returns the outcome of the scope resolution of the module,
+// qualified references included.
+func (m Module) Resolution() resolver.Resolution {
+	return m.resolution
+}
+
+// Imports returns the import paths of the modules imported by the module,
+// keyed by alias.
+func (m Module) Imports() map[string]string {
+	imports := make(map[string]string, len(m.imports))
+	for alias, path := range m.imports {
+		imports[alias] = path
+	}
+	return imports
+}
+
+// Key returns the cache key of the module, which changes whenever the
+// module, or any module it imports directly or not, changes. Artifacts
+// derived from the module, such as the diagnostics of its analysis, can be
+// stored under keys built from it.
+func (m Module) Key() cache.Key {
+	return m.key
+}
+
+// Graph is a root module along with the modules it imports, directly or not,
+// and possibly other modules, when loaded by LoadTree.
+type Graph struct {
+	modules []Module
+	root    int
+}
+
+// Modules returns the modules of the graph in dependency order: every module
+// comes after the modules it imports.
+func (g Graph) Modules() []Module {
+	return append([]Module{}, g.modules...)
+}
+
+// Root returns the module the program starts from: the one given to
+// Loader.Load, or the one chosen with WithRoot.
+func (g Graph) Root() Module {
+	return g.modules[g.root]
+}
+
+// WithRoot returns a copy of the graph rooted at the module of the given
+// import path, if part of the graph.
+func (g Graph) WithRoot(path string) monad.Maybe[Graph] {
+	for i, m := range g.modules {
+		if m.path == path {
+			g.root = i
+			return monad.Some(g)
+		}
+	}
+	return monad.None[Graph]()
+}
+
+// Defining returns the modules of the graph holding a top-level definition of
+// the given name, in dependency order.
+func (g Graph) Defining(name string) []Module {
+	var modules []Module
+	for _, m := range g.modules {
+		if m.definitions()[name] {
+			modules = append(modules, m)
+		}
+	}
+	return modules
+}
+
+// Module returns the module of the given import path, if part of the graph.
+func (g Graph) Module(path string) monad.Maybe[Module] {
+	for _, m := range g.modules {
+		if m.path == path {
+			return monad.Some(m)
+		}
+	}
+	return monad.None[Module]()
+}
+
+// Map returns a copy of the graph in which every module has been replaced by
+// the result of f.
+func (g Graph) Map(f func(Module) Module) Graph {
+	modules := make([]Module, 0, len(g.modules))
+	for _, m := range g.modules {
+		modules = append(modules, f(m))
+	}
+	return Graph{modules: modules, root: g.root}
+}
+
+// Error is an error located in a module, which need not be the module given
+// to the Loader.
+type Error struct {
+	file   string
+	source string
+	err    error
+}
+
+// NewError locates an error in the given file, for the tools reading source
+// files around modules, such as project manifests.
+func NewError(file, source string, err error) Error {
+	return Error{file: file, source: source, err: err}
+}
+
+// File returns the name of the file the error belongs to.
+func (e Error) File() string {
+	return e.file
+}
+
+// Source returns the source text of the file the error belongs to.
+func (e Error) Source() string {
+	return e.source
+}
+
+// Error prefixes the message of the error with the name of the file, and the
+// position of diagnostics.
+func (e Error) Error() string {
+	var d diagnostic.Diagnostic
+	if errors.As(e.err, &d) {
+		return fmt.Sprintf("%s:%v", e.file, d)
+	}
+	return fmt.Sprintf("%s: %v", e.file, e.err)
+}
+
+// Unwrap returns the error, typically a diagnostic.Diagnostic.
+func (e Error) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,280 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/denisdubochevalier/monad"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/cache"
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/eval"
+)
+
+// library is a search path root holding a couple of modules.
+var library = FS("lib", fstest.MapFS{
+	"std/bool.lc": {Data: []byte("true := \\t.\\f.t\nfalse := \\t.\\f.f\n_flip := \\b.\\t.\\f.b f t\nnot := \\b._flip b\n")},
+	"std/pair.lc": {Data: []byte("b | \"std/bool\"\npair := \\x.\\y.\\s.s x y\nfirst := \\p.p b->true\n")},
+	"cycle/a.lc":  {Data: []byte("b | \"cycle/b\"\n")},
+	"cycle/b.lc":  {Data: []byte("c | \"cycle/c\"\n")},
+	"cycle/c.lc":  {Data: []byte("a | \"cycle/a\"\n")},
+	"broken.lc":   {Data: []byte("x := (\n")},
+})
+
+// run links the graph and evaluates the entry point of its root module.
+func run(t *testing.T, g Graph) string {
+	t.Helper()
+	program := g.Link()
+	require.True(t, program.Success(), "%v", program.Error())
+	entry := program.Value().Entry("main")
+	require.True(t, entry.Success(), "%v", entry.Error())
+	result := eval.NewNormalOrder().Evaluate(context.Background(), entry.Value())
+	require.True(t, result.Success(), "%v", result.Error())
+	return result.Value().String()
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	source := "b | \"std/bool\"\np | \"std/pair\"\n" +
+		"true := \\x.x\n" +
+		"main := p->first (p->pair (b->not b->false) true)\n"
+	g := NewLoader(library).Load("main.lc", source)
+	is.True(g.Success(), "%v", g.Error())
+
+	modules := g.Value().Modules()
+	is.Len(modules, 3)
+	is.Equal("std/bool", modules[0].Path())
+	is.Equal(filepath.Join("lib", "std", "bool.lc"), modules[0].File())
+	is.Equal("std/pair", modules[1].Path())
+	is.Equal(map[string]string{"b": "std/bool"}, modules[1].Imports())
+	is.Equal("", g.Value().Root().Path())
+	is.Equal("main.lc", g.Value().Root().File())
+	is.True(g.Value().Module("std/pair").Just())
+	is.True(g.Value().Module("std/list").Nothing())
+
+	for _, m := range modules {
+		is.Empty(m.Resolution().Diagnostics(), m.Path())
+	}
+	qualified := g.Value().Root().Resolution().Qualified()
+	is.Equal("not", qualified[2].Definition().Value().Name())
+
+	// The true of the root module does not interfere with the one of
+	// std/bool, used by first.
+	is.Equal(`\t.\f.t`, run(t, g.Value()))
+}
+
+func TestLoadInvisibleNames(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	g := NewLoader(library).Load("main.lc", "b | \"std/bool\"\nmain := b->ture b->_flip\n")
+	is.True(g.Success(), "%v", g.Error())
+
+	messages := []string{}
+	for _, d := range g.Value().Root().Resolution().Diagnostics() {
+		messages = append(messages, d.Error())
+	}
+	is.Equal([]string{
+		`2:11: error: namespace "b" has no definition "ture" (did you mean "true"?)`,
+		`2:19: error: "_flip" is private to namespace "b" (defined at ` + filepath.Join("lib", "std", "bool.lc") +
+			`:3:0, names starting with _ are only visible in their own module)`,
+	}, messages)
+}
+
+// hostModules is a Host providing the modules of the map.
+type hostModules map[string][]string
+
+func (h hostModules) Definitions(importPath string) monad.Maybe[[]string] {
+	if names, ok := h[importPath]; ok {
+		return monad.Some(names)
+	}
+	return monad.None[[]string]()
+}
+
+func TestLoadHost(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	source := "os | \"host/os\"\nb | \"std/bool\"\nmain := os->getenv (os->args b->true) os->exit\n"
+	g := NewLoader(library).WithHost(hostModules{"host/os": {"getenv", "args"}}).Load("main.lc", source)
+	is.True(g.Success(), "%v", g.Error())
+
+	host := g.Value().Module("host/os")
+	is.True(host.Just())
+	is.Equal(filepath.Join(HostName, "host", "os"), host.Value().File())
+	is.Len(host.Value().Resolution().Definitions(), 2)
+	is.Len(g.Value().Root().Resolution().Diagnostics(), 1)
+	is.Equal(`3:42: error: namespace "os" has no definition "exit"`,
+		g.Value().Root().Resolution().Diagnostics()[0].Error())
+
+	// The primitives are left free, for the evaluator to apply
+	is.Equal(`host/os->getenv (host/os->args (\t.\f.t)) host/os->exit`, run(t, g.Value()))
+
+	missing := NewLoader(library).WithHost(hostModules{}).Load("main.lc", source)
+	is.ErrorContains(missing.Error(), `cannot find module "host/os"`)
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		source  string
+		file    string
+		message string
+	}{
+		{
+			"missing module", "l | \"std/list\"\n", "main.lc",
+			`main.lc:1:4: error: cannot find module "std/list" (searched other, lib)`,
+		},
+		{
+			"import cycle", "a | \"cycle/a\"\n", filepath.Join("lib", "cycle", "c.lc"),
+			filepath.Join("lib", "cycle", "c.lc") +
+				`:1:4: error: import cycle: "cycle/a" imports "cycle/b", which imports "cycle/c", which imports "cycle/a"`,
+		},
+		{
+			"syntax error in imported module", "x | \"broken\"\n", filepath.Join("lib", "broken.lc"),
+			filepath.Join("lib", "broken.lc") + `:2:0: error: unexpected end of input, expected an expression`,
+		},
+		{
+			"syntax error in main module", "main := )\n", "main.lc",
+			`main.lc:1:8: error: unexpected ")", expected an expression`,
+		},
+		{
+			"invalid path", "x | \"../secret\"\n", "main.lc",
+			`main.lc:1:4: error: invalid import path "../secret" ` +
+				`(import paths are slash-separated and have no extension, such as "std/list")`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			g := NewLoader(FS("other", fstest.MapFS{})).WithRoots(library).Load("main.lc", testCase.source)
+			is.True(g.Failure())
+			is.Equal(testCase.message, g.Error().Error())
+
+			var e Error
+			is.True(errors.As(g.Error(), &e))
+			is.Equal(testCase.file, e.File())
+			var d diagnostic.Diagnostic
+			is.True(errors.As(g.Error(), &d))
+		})
+	}
+}
+
+func TestSearchPathOrder(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	local := FS("local", fstest.MapFS{"std/bool.lc": {Data: []byte("true := \\a.\\b.b\n")}})
+	g := NewLoader(local, library).Load("main.lc", "b | \"std/bool\"\nmain := b->true\n")
+	is.True(g.Success(), "%v", g.Error())
+	is.Equal(filepath.Join("local", "std", "bool.lc"), g.Value().Modules()[0].File())
+	is.Equal(`\a.\b.b`, run(t, g.Value()))
+}
+
+func TestLoadTree(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	project := FS("calc", fstest.MapFS{
+		"main.lc":            {Data: []byte("u | \"example.com/calc/util/id\"\nmain := u->id u->id\n")},
+		"util/id.lc":         {Data: []byte("id := \\x.x\n")},
+		"apps/other.lc":      {Data: []byte("m | \"example.com/calc/main\"\nb | \"std/bool\"\ntwice := m->main b->true\n")},
+		".hidden/x.lc":       {Data: []byte("x := (\n")},
+		"nested/lambdac.mod": {Data: []byte("")},
+		"nested/y.lc":        {Data: []byte("y := (\n")},
+		"README.md":          {Data: []byte("# calc\n")},
+	}).WithPrefix("example.com/calc")
+
+	g := NewLoader(library).LoadTree(project)
+	is.True(g.Success(), "%v", g.Error())
+	paths := []string{}
+	for _, m := range g.Value().Modules() {
+		paths = append(paths, m.Path())
+	}
+	is.Equal([]string{"example.com/calc/util/id", "example.com/calc/main", "std/bool", "example.com/calc/apps/other"}, paths)
+	is.Equal(filepath.Join("calc", "util", "id.lc"), g.Value().Modules()[0].File())
+
+	defining := g.Value().Defining("main")
+	is.Len(defining, 1)
+	rooted := g.Value().WithRoot(defining[0].Path())
+	is.True(rooted.Just())
+	is.Equal(`\x.x`, run(t, rooted.Value()))
+
+	// Only the definitions of the root module keep their names
+	other := g.Value().WithRoot("example.com/calc/apps/other").Value()
+	program := other.Link()
+	is.True(program.Success(), "%v", program.Error())
+	is.True(program.Value().Environment().Lookup("twice").Just())
+	is.True(program.Value().Environment().Lookup("example.com/calc/main->main").Just())
+	is.True(program.Value().Environment().Lookup("main").Nothing())
+	is.True(g.Value().WithRoot("example.com/calc/missing").Nothing())
+
+	// Prefixed roots only provide prefixed import paths
+	g = NewLoader(project).Load("main.lc", "u | \"util/id\"\n")
+	is.True(g.Failure())
+
+	g = NewLoader().LoadTree(FS("empty", fstest.MapFS{}))
+	is.EqualError(g.Error(), "no .lc files in empty")
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	store := cache.Open(t.TempDir())
+	is.True(store.Success(), "%v", store.Error())
+	loader := NewLoader(library).WithCache(store.Value())
+	source := "p | \"std/pair\"\nmain := p->first (p->pair a b)\n"
+
+	// The second load reads the syntax trees and resolutions back from the
+	// cache
+	first := loader.Load("main.lc", source)
+	is.True(first.Success(), "%v", first.Error())
+	second := loader.Load("main.lc", source)
+	is.True(second.Success(), "%v", second.Error())
+	for i, m := range second.Value().Modules() {
+		is.Equal(first.Value().Modules()[i].Program().String(), m.Program().String())
+		resolution, cached := first.Value().Modules()[i].Resolution(), m.Resolution()
+		is.Equal(resolution.Definitions(), cached.Definitions())
+		is.Equal(resolution.Qualified(), cached.Qualified())
+		is.Equal(resolution.Diagnostics(), cached.Diagnostics())
+		for _, d := range resolution.Definitions() {
+			is.Equal(resolution.References(d), cached.References(d))
+		}
+		is.Equal(first.Value().Modules()[i].Key(), m.Key())
+	}
+	is.Equal("a", run(t, second.Value()))
+
+	// Keys change along with the modules imported, directly or not
+	changed := FS("lib", fstest.MapFS{
+		"std/bool.lc": {Data: []byte("true := \\t.\\f.f\n")},
+		"std/pair.lc": library.fsys.(fstest.MapFS)["std/pair.lc"],
+	})
+	third := NewLoader(changed).WithCache(store.Value()).Load("main.lc", source)
+	is.True(third.Success(), "%v", third.Error())
+	is.NotEqual(first.Value().Root().Key(), third.Value().Root().Key())
+	is.Equal("b", run(t, third.Value()))
+}
+
+func TestSearchPath(t *testing.T) {
+	t.Setenv("LAMBDAC_PATH", "one"+string(filepath.ListSeparator)+"two")
+	t.Setenv("LAMBDAC_STDLIB", "std")
+
+	names := []string{}
+	for _, root := range SearchPath("project") {
+		names = append(names, root.Name())
+	}
+	require.Equal(t, []string{"project", "one", "two", "std"}, names)
+}
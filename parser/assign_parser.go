@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+// assignParser handles definitions, i.e. statements of the form
+// `name := expression`. It is reached through `moduleParser` once the
+// identifier opening the statement has been appended to the AST by
+// `identParser`.
+//
+// Operational Cadence:
+//   - If the current token is not an assignment operator, the statement is a
+//     bare expression and the task is delegated to `expressionStatementParser`.
+//   - Otherwise, the last ASTNode (which must be the identifier directly
+//     preceding the operator) becomes the first child of a new ASSIGN node.
+//   - The right-hand side is parsed by `expressionParser` and appended as the
+//     second child, before the ASSIGN node replaces the identifier in the AST.
+//   - Control is finally handed to `statementEndParser`, since a definition
+//     spans the whole statement.
+//
+// Parameters:
+//   - State: The current state of the parser.
+//
+// Returns:
+//   - A Result monad that either encapsulates the AST or an error.
+//   - An updated parser State.
+func assignParser(state State) (monad.Result[ASTNode, error], State) {
+	if state.done() {
+		return monad.Fail[ASTNode, error](
+			fmt.Errorf("unexpected end of input"),
+		), state
+	}
+
+	if state.currentToken().Type() != lexer.ASSIGN {
+		return expressionStatementParser(state)
+	}
+
+	assignToken := state.currentToken()
+	ident := state.ast().lastChild().Filter(
+		func(node ASTNode) bool {
+			return node.NodeType() == lexer.IDENT && precededByIdent(state)
+		},
+	)
+	if ident.Nothing() {
+		return monad.Fail[ASTNode, error](
+			diagnostic.Errorf(assignToken.Position(), "assignment operator without previous ident"),
+		), state
+	}
+
+	expr, next := expressionParser(state.advance())
+	if expr.Failure() {
+		return expr, next
+	}
+
+	node := newASTNode(lexer.ASSIGN, assignToken).
+		appendChild(ident.Value()).
+		appendChild(expr.Value())
+	if result := next.ast().replaceLastChild(node); result.Just() {
+		return statementEndParser(next.withAST(result.Value()))
+	}
+
+	return monad.Fail[ASTNode, error](
+		fmt.Errorf("inserting assignment into ast"),
+	), state
+}
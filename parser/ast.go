@@ -1,6 +1,10 @@
 package parser
 
 import (
+	"slices"
+	"strconv"
+	"strings"
+
 	"github.com/denisdubochevalier/monad"
 
 	"github.com/denisdubochevalier/lambdac/lexer"
@@ -176,3 +180,45 @@ func (a ASTNode) lastChild() monad.Maybe[ASTNode] {
 func (a ASTNode) NodeType() NodeType {
 	return a.nodeType
 }
+
+// Token returns the lexical token the calling ASTNode was built from. For
+// nodes without a lexical counterpart, such as applications, the token is
+// synthesized by the parser and positioned at the start of the construct.
+func (a ASTNode) Token() lexer.Token {
+	return a.token
+}
+
+// Children returns a copy of the child nodes of the calling ASTNode, so that
+// callers cannot alter the tree through the returned slice.
+//
+// The layout of the children depends on the NodeType:
+//   - MODULE:  the alias IDENT and the path STRING.
+//   - ASSIGN:  the defined IDENT and the expression it is bound to.
+//   - LAMBDA:  the parameter IDENT and the body expression.
+//   - APPLY:   the applied expression and its argument.
+//   - NSDEREF: the namespace IDENT and the dereferenced IDENT.
+//   - IDENT, STRING and EOF nodes are leaves.
+//
+// The root of a parsed program, a PROGRAM node, holds one child per top-level
+// statement, the last one being the EOF node.
+func (a ASTNode) Children() []ASTNode {
+	return slices.Clone(a.children)
+}
+
+// String renders the subtree rooted at the calling ASTNode as a compact
+// S-expression, e.g. `(\ x (APPLY f x))`. Leaves are rendered with their
+// literal. It is primarily meant for debugging and testing.
+func (a ASTNode) String() string {
+	switch a.nodeType {
+	case lexer.IDENT:
+		return a.token.Literal().String()
+	case lexer.STRING:
+		return strconv.Quote(a.token.Literal().String())
+	}
+
+	parts := []string{a.nodeType.String()}
+	for _, child := range a.children {
+		parts = append(parts, child.String())
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
@@ -0,0 +1,64 @@
+package parser
+
+import "github.com/denisdubochevalier/lambdac/lexer"
+
+// The functions below let later compilation stages synthesize ASTNodes, for
+// instance when a program is rewritten. They produce the very same shapes as
+// the parser, so that rewritten trees can flow through the same passes as
+// parsed ones. Every synthesized token is positioned at the location given by
+// the caller, typically the construct the new nodes stem from.
+
+// NewIdent builds an IDENT leaf for the given name.
+func NewIdent(name string, position lexer.Position) ASTNode {
+	return newASTNode(lexer.IDENT, lexer.NewToken(lexer.IDENT, position, lexer.Literal(name)))
+}
+
+// NewLambda builds a LAMBDA node binding param, which must be an IDENT node,
+// in body.
+func NewLambda(param, body ASTNode) ASTNode {
+	return newASTNode(lexer.LAMBDA, lexer.NewToken(lexer.LAMBDA, param.Token().Position(), "\\")).
+		appendChild(param).
+		appendChild(body)
+}
+
+// NewApply builds the left-nested application of fn to the given arguments.
+// Without arguments, fn is returned as is.
+func NewApply(fn ASTNode, args ...ASTNode) ASTNode {
+	for _, arg := range args {
+		fn = newASTNode(lexer.APPLY, lexer.NewToken(lexer.APPLY, fn.Token().Position(), "")).
+			appendChild(fn).
+			appendChild(arg)
+	}
+	return fn
+}
+
+// NewAssign builds a definition binding name, which must be an IDENT node, to
+// expr.
+func NewAssign(name, expr ASTNode) ASTNode {
+	return newASTNode(lexer.ASSIGN, lexer.NewToken(lexer.ASSIGN, name.Token().Position(), ":=")).
+		appendChild(name).
+		appendChild(expr)
+}
+
+// NewProgram builds the root of a program from its statements. An EOF node is
+// appended when the last statement is not one already, to match the shape of
+// parsed programs.
+func NewProgram(statements ...ASTNode) ASTNode {
+	root := newProgram()
+	for _, statement := range statements {
+		root = root.appendChild(statement)
+	}
+	if last := root.lastChild(); last.Nothing() || last.Value().NodeType() != lexer.EOF {
+		position := lexer.StartPosition()
+		if last.Just() {
+			position = last.Value().Token().Position()
+		}
+		root = root.appendChild(newASTNode(lexer.EOF, lexer.NewToken(lexer.EOF, position, "")))
+	}
+	return root
+}
+
+// newProgram returns the root of an empty program.
+func newProgram() ASTNode {
+	return newASTNode(lexer.PROGRAM, lexer.NewToken(lexer.PROGRAM, lexer.StartPosition(), ""))
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+// wireNode is the serialized form of an ASTNode.
+type wireNode struct {
+	NodeType  NodeType
+	TokenType lexer.TokenType
+	Row, Col  int
+	Literal   string
+	Children  []wireNode
+}
+
+// Encode serializes a syntax tree, so that it can be stored, for instance in
+// a build cache, and restored with Decode rather than parsed again.
+func Encode(node ASTNode) []byte {
+	var b bytes.Buffer
+	// Encoding into memory a type known to be encodable cannot fail
+	_ = gob.NewEncoder(&b).Encode(toWire(node))
+	return b.Bytes()
+}
+
+// Decode restores a syntax tree serialized by Encode. It fails if the data is
+// not the output of Encode, typically because it has been corrupted.
+func Decode(data []byte) monad.Result[ASTNode, error] {
+	var w wireNode
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&w); err != nil {
+		return monad.Fail[ASTNode, error](err)
+	}
+	return monad.Succeed[ASTNode, error](fromWire(w))
+}
+
+func toWire(node ASTNode) wireNode {
+	token := node.Token()
+	w := wireNode{
+		NodeType:  node.nodeType,
+		TokenType: token.Type(),
+		Row:       token.Position().Row(),
+		Col:       token.Position().Col(),
+		Literal:   token.Literal().String(),
+	}
+	for _, child := range node.children {
+		w.Children = append(w.Children, toWire(child))
+	}
+	return w
+}
+
+func fromWire(w wireNode) ASTNode {
+	node := newASTNode(w.NodeType, lexer.NewToken(w.TokenType, lexer.NewPosition(w.Row, w.Col), lexer.Literal(w.Literal)))
+	for _, child := range w.Children {
+		node = node.appendChild(fromWire(child))
+	}
+	return node
+}
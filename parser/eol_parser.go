@@ -18,8 +18,9 @@ import (
 //   - Validates that the parser has not reached the end of the token list.
 //     If it has, an "unexpected end of input" error is returned.
 //   - Checks the type of the current token. If it is an EOL, the parser state is
-//     simply advanced to the next token, bypassing the creation of a new AST node.
-//   - Delegates control to spaceParser for additional parsing if the current token
+//     simply advanced to the next token, bypassing the creation of a new AST node,
+//     and a new statement is parsed starting from eofParser.
+//   - Delegates control to identParser for additional parsing if the current token
 //     is not of type EOL.
 //
 // Parameters:
@@ -38,7 +39,7 @@ func eolParser(state State) (monad.Result[ASTNode, error], State) {
 	}
 
 	if state.currentToken().Type() == lexer.EOL {
-		return stringParser(state.advance())
+		return eofParser(state.advance())
 	}
 
 	return identParser(state)
@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+// expressionParser is the entry point of the expression grammar of λ.c:
+//
+//	Expression  ::= Application
+//	Application ::= Term { Term }
+//	Term        ::= Variable | String | "(" Expression ")" | Lambda
+//	Lambda      ::= "\" Identifier "." Expression
+//	Variable    ::= Identifier [ "->" Identifier ]
+//
+// Unlike the statement parsers, the expression parsers do not graft their
+// results onto the AST held by the State: they return the parsed expression
+// in the Result monad and let the caller decide where it belongs. The State
+// they return is positioned right after the expression.
+//
+// A String is a string literal, which stands for the list of its characters.
+//
+// Application is left-associative (`f x y` is `(f x) y`) and the body of a
+// lambda extends as far right as possible (`\x.f x` is `\x.(f x)`).
+func expressionParser(state State) (monad.Result[ASTNode, error], State) {
+	return applicationParser(state)
+}
+
+// applicationParser parses a non-empty sequence of terms and folds it into
+// left-nested APPLY nodes. A single term is returned as is.
+func applicationParser(state State) (monad.Result[ASTNode, error], State) {
+	term, next := termParser(state)
+	if term.Failure() {
+		return term, next
+	}
+	return applicationTailParser(term.Value(), next)
+}
+
+// applicationTailParser recursively applies the expression parsed so far to
+// the following terms, until a token that cannot start a term is reached.
+func applicationTailParser(fn ASTNode, state State) (monad.Result[ASTNode, error], State) {
+	next := state.skipLineBreaks()
+	if next.done() || !startsTerm(next.currentToken()) {
+		return monad.Succeed[ASTNode, error](fn), next
+	}
+
+	arg, next := termParser(next)
+	if arg.Failure() {
+		return arg, next
+	}
+
+	application := newASTNode(
+		lexer.APPLY,
+		lexer.NewToken(lexer.APPLY, fn.Token().Position(), ""),
+	).appendChild(fn).appendChild(arg.Value())
+	return applicationTailParser(application, next)
+}
+
+// startsTerm reports whether a token may open a term.
+func startsTerm(t lexer.Token) bool {
+	switch t.Type() {
+	case lexer.IDENT, lexer.STRING, lexer.LPAREN, lexer.LAMBDA:
+		return true
+	default:
+		return false
+	}
+}
+
+// termParser parses a single term: a variable, a string literal, a
+// parenthesized expression or a lambda abstraction.
+func termParser(state State) (monad.Result[ASTNode, error], State) {
+	state = state.skipLineBreaks()
+	if state.done() {
+		return monad.Fail[ASTNode, error](
+			fmt.Errorf("unexpected end of input"),
+		), state
+	}
+
+	switch t := state.currentToken(); t.Type() {
+	case lexer.IDENT:
+		return variableParser(state)
+	case lexer.STRING:
+		return monad.Succeed[ASTNode, error](newASTNode(lexer.STRING, t)), state.advance()
+	case lexer.LAMBDA:
+		return lambdaParser(state)
+	case lexer.LPAREN:
+		return groupParser(state)
+	default:
+		return monad.Fail[ASTNode, error](
+			diagnostic.Errorf(t.Position(), "unexpected %s, expected an expression", describeToken(t)),
+		), state
+	}
+}
+
+// variableParser parses an identifier, optionally dereferenced from a
+// namespace with the "->" operator. A dereference yields an NSDEREF node whose
+// children are the namespace and the dereferenced identifiers.
+func variableParser(state State) (monad.Result[ASTNode, error], State) {
+	ident := newASTNode(lexer.IDENT, state.currentToken())
+	state = state.advance()
+	if state.done() || state.currentToken().Type() != lexer.NSDEREF {
+		return monad.Succeed[ASTNode, error](ident), state
+	}
+
+	deref := newASTNode(lexer.NSDEREF, state.currentToken())
+	name, state := expectParser(state.advance(), lexer.IDENT, "after namespace dereference")
+	if name.Failure() {
+		return name, state
+	}
+	return monad.Succeed[ASTNode, error](deref.appendChild(ident).appendChild(name.Value())), state
+}
+
+// groupParser parses an expression enclosed in parentheses. Line breaks are
+// ignored until the matching closing parenthesis.
+func groupParser(state State) (monad.Result[ASTNode, error], State) {
+	open := state.currentToken()
+	expr, next := expressionParser(state.advance().enterGroup())
+	if expr.Failure() {
+		return expr, next
+	}
+
+	next = next.skipLineBreaks()
+	if next.done() || next.currentToken().Type() != lexer.RPAREN {
+		return monad.Fail[ASTNode, error](
+			diagnostic.Errorf(open.Position(), "unclosed parenthesis"),
+		), next
+	}
+	return monad.Succeed[ASTNode, error](expr.Value()), next.advance().leaveGroup()
+}
+
+// expectParser consumes a token of the expected type and returns it as a leaf
+// ASTNode, or fails with an error describing what was found instead.
+func expectParser(state State, tokenType lexer.TokenType, context string) (monad.Result[ASTNode, error], State) {
+	state = state.skipLineBreaks()
+	if state.done() {
+		return monad.Fail[ASTNode, error](
+			fmt.Errorf("unexpected end of input"),
+		), state
+	}
+
+	if t := state.currentToken(); t.Type() != tokenType {
+		return monad.Fail[ASTNode, error](
+			diagnostic.Errorf(
+				t.Position(),
+				"unexpected %s, expected %q %s", describeToken(t), tokenType.String(), context,
+			),
+		), state
+	}
+	return monad.Succeed[ASTNode, error](newASTNode(tokenType, state.currentToken())), state.advance()
+}
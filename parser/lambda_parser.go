@@ -1,13 +1,39 @@
 package parser
 
 import (
-	"fmt"
-
 	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
 )
 
+// lambdaParser parses a lambda abstraction `\x.body`. It yields a LAMBDA node
+// whose children are the parameter identifier and the body expression. The
+// body is parsed by `expressionParser`, hence extends as far right as
+// possible.
+//
+// Parameters:
+//   - State: The current state of the parser, positioned on the "\" token.
+//
+// Returns:
+//   - A Result monad encapsulating either the LAMBDA node or an error.
+//   - An updated parser State, positioned after the body.
 func lambdaParser(state State) (monad.Result[ASTNode, error], State) {
-	return monad.Fail[ASTNode, error](
-		fmt.Errorf("not implemented"),
-	), state
+	lambda := newASTNode(lexer.LAMBDA, state.currentToken())
+
+	param, state := expectParser(state.advance(), lexer.IDENT, "as lambda parameter")
+	if param.Failure() {
+		return param, state
+	}
+
+	dot, state := expectParser(state, lexer.DOT, "after lambda parameter")
+	if dot.Failure() {
+		return dot, state
+	}
+
+	body, state := expressionParser(state)
+	if body.Failure() {
+		return body, state
+	}
+
+	return monad.Succeed[ASTNode, error](lambda.appendChild(param.Value()).appendChild(body.Value())), state
 }
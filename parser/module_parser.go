@@ -5,6 +5,7 @@ import (
 
 	"github.com/denisdubochevalier/monad"
 
+	"github.com/denisdubochevalier/lambdac/diagnostic"
 	"github.com/denisdubochevalier/lambdac/lexer"
 )
 
@@ -22,9 +23,9 @@ import (
 // Operational Cadence:
 //   - Initially checks if the token list has been exhausted; returns an error if true.
 //   - If the current token is not 'module', the responsibility of parsing is transferred
-//     to `lambdaParser`.
+//     to `assignParser`.
 //   - Utilizes chained monadic operations (`FlatMap`) to:
-//     a. Verify the last ASTNode as an identifier.
+//     a. Verify the last ASTNode as an identifier, directly preceding the operator.
 //     b. Append this identifier to a new 'module' ASTNode.
 //     c. Replace the last child of the AST with this newly augmented 'module' node.
 //   - On successful completion, the parsing task is delegated to `stringParser` after
@@ -43,13 +44,13 @@ func moduleParser(state State) (monad.Result[ASTNode, error], State) {
 	}
 
 	if state.currentToken().Type() != lexer.MODULE {
-		return lambdaParser(state)
+		return assignParser(state)
 	}
 
 	newNode := newASTNode(lexer.MODULE, state.currentToken())
 	if result := state.ast().lastChild().FlatMap(
 		func(node ASTNode) monad.Maybe[ASTNode] {
-			if node.NodeType() != lexer.IDENT {
+			if node.NodeType() != lexer.IDENT || !precededByIdent(state) {
 				return monad.None[ASTNode]()
 			}
 			return monad.Some(node)
@@ -67,6 +68,14 @@ func moduleParser(state State) (monad.Result[ASTNode, error], State) {
 	}
 
 	return monad.Fail[ASTNode, error](
-		fmt.Errorf("module operator without previous ident"),
+		diagnostic.Errorf(state.currentToken().Position(), "module operator without previous ident"),
 	), state
 }
+
+// precededByIdent reports whether the token right before the current one is an
+// identifier, which is the left operand expected by infix statement operators.
+func precededByIdent(state State) bool {
+	return state.previousToken().Filter(
+		func(t lexer.Token) bool { return t.Type() == lexer.IDENT },
+	).Just()
+}
@@ -4,10 +4,16 @@
 // to encapsulate state transformations, and striving for functional purity by
 // avoiding in-place mutations.
 //
-// Work-In-Progress warning:
+// Grammar:
 //
-// This package is currently a WIP. Please do not use it while it's not fully
-// implemented.
+// A program is a sequence of statements separated by line breaks. Each
+// statement is an import, a definition or a bare expression:
+//
+//	Program    ::= { Statement EOL } EOF
+//	Statement  ::= Identifier "|" String | Identifier ":=" Expression | Expression
+//
+// The expression grammar is documented on expressionParser. Line breaks are
+// ignored inside parentheses, which allows expressions to span several lines.
 //
 // Architecture:
 //
@@ -53,12 +59,16 @@
 //	}
 //
 //	ast := result.Value()
+//
+// ParseString wraps both the lexing and the parsing of a source text.
 package parser
 
 import (
 	"fmt"
 
 	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
 )
 
 // Parse is the main orchestrator function for the parser. It initiates the
@@ -71,7 +81,8 @@ import (
 //
 // The function begins by creating an initial Result monad containing a failure
 // due to an empty token list. Then, it iteratively runs the specialized parsing
-// function 'eofParser', updating the state and Result value accordingly.
+// function 'eofParser', updating the state and Result value accordingly, and
+// stops at the first failure.
 //
 // Finally, it returns the Result monad containing either a successfully parsed
 // ASTNode or an error.
@@ -88,6 +99,15 @@ func Parse(state State) monad.Result[ASTNode, error] {
 		val, state = monad.NewState[State, monad.Result[ASTNode, error]](
 			eofParser,
 		).Run(state)
+		if val.Failure() {
+			break
+		}
 	}
 	return val
 }
+
+// ParseString lexes and parses a whole source text. Illegal tokens produced by
+// the lexer, such as unterminated strings, are reported as parsing errors.
+func ParseString(content string) monad.Result[ASTNode, error] {
+	return Parse(NewState(lexer.New().WithContent(content).Tokens()))
+}
@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+func TestParseString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"identity", `i := \x.x`, `(PROGRAM (:= i (\ x x)) (EOF))`},
+		{"application is left-associative", `f a b`, `(PROGRAM (APPLY (APPLY f a) b) (EOF))`},
+		{"lambda extends to the right", `\x.f x`, `(PROGRAM (\ x (APPLY f x)) (EOF))`},
+		{"lambda as last argument", `f \x.x y`, `(PROGRAM (APPLY f (\ x (APPLY x y))) (EOF))`},
+		{
+			"y combinator",
+			`y := \f.(\x.f(x x))(\x.f(x x))`,
+			`(PROGRAM (:= y (\ f (APPLY (\ x (APPLY f (APPLY x x))) (\ x (APPLY f (APPLY x x)))))) (EOF))`,
+		},
+		{"module", `io | "std/io"`, `(PROGRAM (| io "std/io") (EOF))`},
+		{"namespace dereference", `r := io->read x`, `(PROGRAM (:= r (APPLY (-> io read) x)) (EOF))`},
+		{
+			"several statements",
+			"k := \\x.\\y.x\n\n\nk a b\n",
+			`(PROGRAM (:= k (\ x (\ y x))) (APPLY (APPLY k a) b) (EOF))`,
+		},
+		{"line breaks inside parentheses", "x := (f\n  a\n  b)", `(PROGRAM (:= x (APPLY (APPLY f a) b)) (EOF))`},
+		{
+			"comments",
+			"-- identity\ni := \\x.x -- trailing\n-- end",
+			`(PROGRAM (:= i (\ x x)) (EOF))`,
+		},
+		{"comment inside parentheses", "x := (f -- function\n  a)", `(PROGRAM (:= x (APPLY f a)) (EOF))`},
+		{"string", `greet "world" x`, `(PROGRAM (APPLY (APPLY greet "world") x) (EOF))`},
+		{"bare string", `"hello"`, `(PROGRAM "hello" (EOF))`},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			result := ParseString(testCase.input)
+			is.True(result.Success(), "%v", result.Error())
+			is.Equal(testCase.expected, result.Value().String())
+		})
+	}
+}
+
+func TestParseStringErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		position lexer.Position
+		message  string
+	}{
+		{"module without ident", `| "x"`, lexer.NewPosition(1, 0), "module operator without previous ident"},
+		{"assign without ident", `(a) := b`, lexer.NewPosition(1, 4), "unexpected \":=\" at end of statement"},
+		{"missing body", `x := `, lexer.NewPosition(1, 5), "unexpected end of input, expected an expression"},
+		{"unclosed parenthesis", `x := (a b`, lexer.NewPosition(1, 5), "unclosed parenthesis"},
+		{"missing dot", `\x y`, lexer.NewPosition(1, 3), "unexpected identifier \"y\", expected \".\" after lambda parameter"},
+		{"dangling parenthesis", `a b)`, lexer.NewPosition(1, 3), "unexpected \")\" at end of statement"},
+		{"illegal token", `m | "x`, lexer.NewPosition(1, 4), "unexpected token type: ILLEGAL"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			result := ParseString(testCase.input)
+			is.True(result.Failure())
+
+			var d diagnostic.Diagnostic
+			is.True(errors.As(result.Error(), &d), "%v", result.Error())
+			is.Equal(testCase.position, d.Position())
+			is.Equal(testCase.message, d.Message())
+		})
+	}
+}
+
+func TestASTNodeAccessors(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	root := ParseString(`i := \x.x`).Value()
+	children := root.Children()
+	is.Len(children, 2)
+
+	assign := children[0]
+	is.Equal(lexer.ASSIGN, assign.NodeType())
+	is.Equal(lexer.NewPosition(1, 2), assign.Token().Position())
+
+	lambda := assign.Children()[1]
+	is.Equal(lexer.LAMBDA, lambda.NodeType())
+	is.Equal("x", lambda.Children()[0].Token().Literal().String())
+
+	// Mutating the returned slice does not alter the tree
+	children[0] = ASTNode{}
+	is.Equal(lexer.ASSIGN, root.Children()[0].NodeType())
+}
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	p := lexer.StartPosition()
+	program := NewProgram(
+		NewAssign(NewIdent("k", p), NewLambda(NewIdent("x", p), NewLambda(NewIdent("y", p), NewIdent("x", p)))),
+		NewApply(NewIdent("k", p), NewIdent("a", p), NewIdent("b", p)),
+	)
+	is.Equal(ParseString("k := \\x.\\y.x\nk a b").Value().String(), program.String())
+	is.Equal(program.String(), NewProgram(program.Children()...).String())
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	program := ParseString("io | \"std/io\"\nk := \\x.\\y.x\nk (io->read a) b\n").Value()
+	decoded := Decode(Encode(program))
+	is.True(decoded.Success(), "%v", decoded.Error())
+	is.Equal(program.String(), decoded.Value().String())
+
+	assign := decoded.Value().Children()[1]
+	is.Equal(lexer.ASSIGN, assign.NodeType())
+	is.Equal(program.Children()[1].Token(), assign.Token())
+	is.Equal(lexer.NewPosition(2, 6), assign.Children()[1].Children()[0].Token().Position())
+
+	is.True(Decode([]byte("garbage")).Failure())
+}
@@ -1,6 +1,10 @@
 package parser
 
-import "github.com/denisdubochevalier/lambdac/lexer"
+import (
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
 
 // State encapsulates the internal state of the parser during the parsing
 // phase of the compilation process. The state comprises two critical
@@ -20,6 +24,7 @@ type State struct {
 	tokens   []lexer.Token
 	position int
 	astRoot  ASTNode
+	nesting  int
 }
 
 // NewState is a constructor function for initializing the State structure that
@@ -27,7 +32,8 @@ type State struct {
 // by the lexer and constructs a new State instance with the given tokens.
 //
 // The initialized State comprises:
-//   - A list of lexer tokens (`tokens`) that are to be parsed.
+//   - A list of lexer tokens (`tokens`) that are to be parsed, without the
+//     COMMENT tokens, which carry no meaning.
 //   - The current position (`position`) within that list, initially set to 0.
 //   - An empty AST node (`astRoot`), which serves as the starting point for
 //     building the Abstract Syntax Tree (AST) during the parsing process.
@@ -43,10 +49,16 @@ type State struct {
 //   - A newly initialized State instance, prepared for the commencement of the
 //     parsing process.
 func NewState(tokens []lexer.Token) State {
+	meaningful := make([]lexer.Token, 0, len(tokens))
+	for _, token := range tokens {
+		if token.Type() != lexer.COMMENT {
+			meaningful = append(meaningful, token)
+		}
+	}
 	return State{
-		tokens:   tokens,
+		tokens:   meaningful,
 		position: 0,
-		astRoot:  ASTNode{},
+		astRoot:  newProgram(),
 	}
 }
 
@@ -144,3 +156,52 @@ func (s State) currentToken() lexer.Token {
 func (s State) ast() ASTNode {
 	return s.astRoot
 }
+
+// previousToken retrieves the token located just before the current position,
+// wrapped in a Maybe monad. It yields monad.None when the parser stands on the
+// very first token. Parsers of infix constructs such as "|" and ":=" rely on it
+// to make sure their left operand really is the token preceding them.
+func (s State) previousToken() monad.Maybe[lexer.Token] {
+	if s.position == 0 || s.position > len(s.tokens) {
+		return monad.None[lexer.Token]()
+	}
+	return monad.Some(s.tokens[s.position-1])
+}
+
+// peekToken retrieves the token following the current one, wrapped in a Maybe
+// monad, without advancing the parser. It yields monad.None at the end of the
+// token stream. This single token of lookahead is all the grammar of λ.c
+// requires to tell definitions and imports apart from expressions.
+func (s State) peekToken() monad.Maybe[lexer.Token] {
+	if s.position+1 >= len(s.tokens) {
+		return monad.None[lexer.Token]()
+	}
+	return monad.Some(s.tokens[s.position+1])
+}
+
+// enterGroup returns a new State recording that the parser entered a
+// parenthesized group. Inside a group, line breaks are not significant, which
+// lets long expressions span several lines.
+func (s State) enterGroup() State {
+	s.nesting++
+	return s
+}
+
+// leaveGroup returns a new State recording that the parser left a
+// parenthesized group.
+func (s State) leaveGroup() State {
+	s.nesting--
+	return s
+}
+
+// skipLineBreaks advances past EOL tokens when the parser is inside a
+// parenthesized group, and leaves the State untouched otherwise.
+func (s State) skipLineBreaks() State {
+	if s.nesting == 0 {
+		return s
+	}
+	for !s.done() && s.currentToken().Type() == lexer.EOL {
+		s = s.advance()
+	}
+	return s
+}
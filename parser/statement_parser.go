@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+// expressionStatementParser is the last link of the statement chain. A
+// statement that is neither an import nor a definition is a bare expression,
+// typically the entry point of a program (e.g. `fact 5`). The expression is
+// parsed by `expressionParser` and appended to the AST as is.
+//
+// Parameters:
+//   - State: The current state of the parser.
+//
+// Returns:
+//   - A Result monad that either encapsulates the AST or an error.
+//   - An updated parser State, positioned after the statement.
+func expressionStatementParser(state State) (monad.Result[ASTNode, error], State) {
+	expr, next := expressionParser(state)
+	if expr.Failure() {
+		return expr, next
+	}
+
+	return statementEndParser(next.withAST(next.ast().appendChild(expr.Value())))
+}
+
+// statementEndParser makes sure that a statement is properly terminated by a
+// line break or by the end of the input, then hands control back to
+// `eofParser` to parse the next statement. Anything else is reported as an
+// error positioned on the offending token.
+//
+// Parameters:
+//   - State: The current state of the parser, right after a statement.
+//
+// Returns:
+//   - A Result monad that either encapsulates the AST or an error.
+//   - An updated parser State.
+func statementEndParser(state State) (monad.Result[ASTNode, error], State) {
+	if state.done() {
+		return monad.Fail[ASTNode, error](
+			fmt.Errorf("unexpected end of input"),
+		), state
+	}
+
+	if t := state.currentToken(); t.Type() != lexer.EOL && t.Type() != lexer.EOF {
+		return monad.Fail[ASTNode, error](
+			diagnostic.Errorf(t.Position(), "unexpected %s at end of statement", describeToken(t)),
+		), state
+	}
+
+	return eofParser(state)
+}
+
+// describeToken renders a token for inclusion in error messages: identifiers
+// and strings are shown with their literal, other tokens with their type.
+func describeToken(t lexer.Token) string {
+	switch t.Type() {
+	case lexer.IDENT:
+		return fmt.Sprintf("identifier %q", t.Literal())
+	case lexer.STRING:
+		return fmt.Sprintf("string %q", t.Literal())
+	case lexer.ILLEGAL:
+		return "illegal token"
+	case lexer.EOL:
+		return "end of line"
+	case lexer.EOF:
+		return "end of input"
+	default:
+		return fmt.Sprintf("%q", t.Type().String())
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/denisdubochevalier/monad"
 
+	"github.com/denisdubochevalier/lambdac/diagnostic"
 	"github.com/denisdubochevalier/lambdac/lexer"
 )
 
@@ -33,6 +34,8 @@ import (
 //     AST.
 //     b. Replace the last child node in the AST with the newly created composite
 //     node.
+//   - Hands over to `statementEndParser`, as the string closes the import
+//     statement.
 //
 // Monadological Note: The employment of monadic operations maintains functional
 // purity, mitigating error handling complexity and endowing the codebase with
@@ -46,14 +49,17 @@ func stringParser(state State) (monad.Result[ASTNode, error], State) {
 
 	if state.currentToken().Type() != lexer.STRING {
 		return monad.Fail[ASTNode, error](
-			fmt.Errorf("unexpected token type: %s", state.currentToken().Type()),
+			diagnostic.Errorf(
+				state.currentToken().Position(),
+				"unexpected token type: %s", state.currentToken().Type(),
+			),
 		), state
 	}
 
 	if result := state.ast().lastChild(); result.Nothing() ||
 		(result.Just() && result.Value().NodeType() != lexer.MODULE) {
 		return monad.Fail[ASTNode, error](
-			fmt.Errorf("string token not after a module operator"),
+			diagnostic.Errorf(state.currentToken().Position(), "string token not after a module operator"),
 		), state
 	}
 
@@ -66,7 +72,7 @@ func stringParser(state State) (monad.Result[ASTNode, error], State) {
 			return state.ast().replaceLastChild(node)
 		},
 	); result.Just() {
-		return eofParser(state.withAST(result.Value()).advance())
+		return statementEndParser(state.withAST(result.Value()).advance())
 	}
 
 	return monad.Fail[ASTNode, error](
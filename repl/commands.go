@@ -0,0 +1,163 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/types"
+)
+
+// command is a REPL command, invoked as :name followed by its argument.
+type command struct {
+	name string
+	args string
+	help string
+	run  func(r *REPL, ctx context.Context, arg string) bool
+}
+
+// commands lists the REPL commands, in the order :help presents them. The
+// list is populated by init, as :help refers to it.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"help", "", "show this help", (*REPL).help},
+		{"quit", "", "leave the REPL", func(*REPL, context.Context, string) bool { return false }},
+		{"env", "", "list the definitions", (*REPL).listEnv},
+		{"type", "<expr>", "show the principal simple type of an expression", (*REPL).typeOf},
+		{"trace", "<expr>", "evaluate an expression, showing every reduction step", (*REPL).trace},
+		{"load", "<file>", "load the definitions of a file", (*REPL).loadFile},
+		{"reload", "", "load the files loaded so far again, dropping what they no longer define", (*REPL).reload},
+		{"strategy", "[name]", "show or set the evaluation strategy", (*REPL).setStrategy},
+		{"steps", "[n]", "show or set the step limit, 0 for none", (*REPL).setSteps},
+	}
+}
+
+// command runs the named command, which may be abbreviated to any prefix, the
+// first matching command in the list winning, and reports whether the session
+// goes on.
+func (r *REPL) command(ctx context.Context, name, arg string) bool {
+	for _, c := range commands {
+		if name != "" && strings.HasPrefix(c.name, name) {
+			return c.run(r, ctx, arg)
+		}
+	}
+	r.fail(fmt.Errorf("unknown command :%s, type :help for help", name))
+	return true
+}
+
+func (r *REPL) help(context.Context, string) bool {
+	fmt.Fprintln(r.out, "Enter definitions (name := expr) or expressions to evaluate. Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(r.out, "  :%-18s %s\n", strings.TrimSpace(c.name+" "+c.args), c.help)
+	}
+	return true
+}
+
+func (r *REPL) listEnv(context.Context, string) bool {
+	for _, name := range r.env.Names() {
+		fmt.Fprintf(r.out, "%s := %s\n", name, r.env.Lookup(name).Value())
+	}
+	return true
+}
+
+func (r *REPL) typeOf(_ context.Context, arg string) bool {
+	t, ok := r.expression(arg)
+	if !ok {
+		return true
+	}
+	closed := r.env.Close(t)
+	if closed.Failure() {
+		r.fail(closed.Error())
+		return true
+	}
+	typ := types.Infer(closed.Value())
+	if typ.Failure() {
+		r.fail(typ.Error())
+		return true
+	}
+	fmt.Fprintf(r.out, "%s : %s\n", strings.TrimSpace(arg), typ.Value())
+	return true
+}
+
+func (r *REPL) trace(ctx context.Context, arg string) bool {
+	t, ok := r.expression(arg)
+	if !ok {
+		return true
+	}
+	strategy := r.strategy
+	if strings.HasPrefix(strategy, "krivine") {
+		// Krivine machines cannot trace, and agree with normal order
+		fmt.Fprintf(r.out, "note: the %s backend cannot be traced, tracing normal order instead\n", strategy)
+		strategy = "normal"
+	}
+	r.evaluate(ctx, t, strategy, eval.NewTextTracer(r.out))
+	return true
+}
+
+func (r *REPL) loadFile(ctx context.Context, arg string) bool {
+	if arg == "" {
+		r.fail(fmt.Errorf(":load expects a file"))
+		return true
+	}
+	r.load(ctx, arg)
+	return true
+}
+
+func (r *REPL) reload(ctx context.Context, _ string) bool {
+	loaded := false
+	for i, in := range r.inputs {
+		if in.path == "" {
+			continue
+		}
+		loaded = true
+		content, err := os.ReadFile(in.path)
+		if err != nil {
+			r.fail(err)
+			continue
+		}
+		r.inputs[i].source = string(content)
+	}
+	if !loaded {
+		r.fail(fmt.Errorf("no file loaded yet"))
+		return true
+	}
+	r.rebuild(ctx, true)
+	return true
+}
+
+func (r *REPL) setStrategy(ctx context.Context, arg string) bool {
+	if arg == "" {
+		fmt.Fprintln(r.out, r.strategy)
+		return true
+	}
+	if e := eval.NewEvaluator(arg, r.maxSteps, nil); e.Failure() {
+		r.fail(e.Error())
+		return true
+	}
+	combinator := r.combinator()
+	r.strategy = arg
+	if r.combinator() != combinator {
+		// Recursive definitions need another fixpoint combinator
+		r.rebuild(ctx, false)
+	}
+	return true
+}
+
+func (r *REPL) setSteps(_ context.Context, arg string) bool {
+	if arg == "" {
+		fmt.Fprintln(r.out, r.maxSteps)
+		return true
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		r.fail(fmt.Errorf(":steps expects a number, not %q", arg))
+		return true
+	}
+	r.maxSteps = n
+	return true
+}
@@ -0,0 +1,282 @@
+// Package repl implements the interactive read-eval-print loop of λ.c.
+//
+// Each line read is either a command, starting with a colon, or λ.c source
+// text. Definitions extend the environment of the session, and expressions are
+// evaluated in it, their normal form being printed back. Recursive definitions
+// are made non-recursive with a fixpoint combinator on the fly, so that
+// recursion works the way newcomers expect it to. Mutually recursive
+// definitions are only recognized as such within a single input, and are best
+// loaded from a file. Lines with unbalanced parentheses are continued on the
+// next line.
+package repl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/fixpoint"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/resolver"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+const (
+	// Prompt is printed when the REPL waits for a new input.
+	Prompt = "λ> "
+	// ContinuationPrompt is printed when the REPL waits for the rest of an
+	// input with unbalanced parentheses.
+	ContinuationPrompt = ".. "
+)
+
+// REPL is an interactive session: the environment of the definitions entered
+// so far, and the settings of the evaluator.
+type REPL struct {
+	out      io.Writer
+	env      eval.Environment
+	strategy string
+	maxSteps int
+	// inputs are the source texts defining the environment, in order, so
+	// that it can be rebuilt
+	inputs []input
+	// interrupt returns the context of an input run by Run, done on an
+	// interrupt signal until stopped
+	interrupt func(context.Context) (context.Context, context.CancelFunc)
+}
+
+// input is a source text executed by the session, and the path of the file it
+// was read from, if any.
+type input struct {
+	path   string
+	source string
+}
+
+// New returns a REPL writing to out, with an empty environment, evaluating to
+// normal form in normal order.
+func New(out io.Writer) *REPL {
+	return &REPL{
+		out:      out,
+		env:      eval.NewEnvironment(),
+		strategy: "normal",
+		maxSteps: eval.DefaultMaxSteps,
+		interrupt: func(ctx context.Context) (context.Context, context.CancelFunc) {
+			return signal.NotifyContext(ctx, os.Interrupt)
+		},
+	}
+}
+
+// Run reads inputs from in and executes them until the end of in or the :quit
+// command. Evaluations are interrupted when ctx is done, and an interrupt
+// signal received during an input interrupts its evaluations only: the
+// signal is caught afresh for every input, and not at all while waiting for
+// one, so that it ends the session from the prompt.
+func (r *REPL) Run(ctx context.Context, in io.Reader) error {
+	fmt.Fprintln(r.out, "λ.c, type :help for help")
+	scanner := bufio.NewScanner(in)
+	for {
+		input, ok := r.read(scanner)
+		if !ok {
+			fmt.Fprintln(r.out)
+			return scanner.Err()
+		}
+		inputCtx, stop := r.interrupt(ctx)
+		more := r.Execute(inputCtx, input)
+		stop()
+		if !more {
+			return nil
+		}
+	}
+}
+
+// read reads an input, continuing it on the following lines as long as its
+// parentheses are unbalanced.
+func (r *REPL) read(scanner *bufio.Scanner) (string, bool) {
+	fmt.Fprint(r.out, Prompt)
+	if !scanner.Scan() {
+		return "", false
+	}
+	input := scanner.Text()
+	for depth(input) > 0 {
+		fmt.Fprint(r.out, ContinuationPrompt)
+		if !scanner.Scan() {
+			break
+		}
+		input += "\n" + scanner.Text()
+	}
+	return input, true
+}
+
+// depth returns the number of parentheses left open at the end of input,
+// ignoring string literals.
+func depth(input string) int {
+	depth, quoted, escaped := 0, false, false
+	for _, r := range input {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		}
+	}
+	return depth
+}
+
+// Execute executes a single input, command or source text, and reports
+// whether the session goes on.
+func (r *REPL) Execute(ctx context.Context, input string) bool {
+	trimmed := strings.TrimSpace(input)
+	switch {
+	case trimmed == "":
+		return true
+	case strings.HasPrefix(trimmed, ":"):
+		name, arg, _ := strings.Cut(trimmed[1:], " ")
+		return r.command(ctx, name, strings.TrimSpace(arg))
+	default:
+		r.source(ctx, input)
+		return true
+	}
+}
+
+// source executes λ.c source text entered at the prompt: its definitions
+// extend the environment and its expressions are evaluated.
+func (r *REPL) source(ctx context.Context, source string) {
+	if r.execute(ctx, source, true) {
+		r.inputs = append(r.inputs, input{source: source})
+	}
+}
+
+// execute defines the definitions of source text and, if evaluate is set,
+// evaluates its expressions. It reports whether the text was valid and had
+// definitions.
+func (r *REPL) execute(ctx context.Context, source string, evaluate bool) bool {
+	ast := parser.ParseString(source)
+	if ast.Failure() {
+		r.fail(ast.Error())
+		return false
+	}
+
+	rewritten := fixpoint.Rewrite(ast.Value(), resolver.Resolve(ast.Value()), r.combinator())
+	program := eval.Load(rewritten)
+	if program.Failure() {
+		r.fail(program.Error())
+		return false
+	}
+	for _, statement := range rewritten.Children() {
+		if evaluate && statement.NodeType() == lexer.MODULE {
+			fmt.Fprintf(r.out, "note: imports are not supported by the REPL, %q ignored\n",
+				statement.Children()[1].Token().Literal())
+		}
+	}
+
+	env := program.Value().Environment()
+	for _, name := range env.Names() {
+		r.env = r.env.Define(name, env.Lookup(name).Value())
+	}
+	if evaluate {
+		for _, expression := range program.Value().Expressions() {
+			r.evaluate(ctx, expression, r.strategy, nil)
+		}
+	}
+	return len(env.Names()) > 0
+}
+
+// rebuild rebuilds the environment from scratch by executing the inputs
+// again, evaluating the expressions of the files only if evaluate is set.
+func (r *REPL) rebuild(ctx context.Context, evaluate bool) {
+	r.env = eval.NewEnvironment()
+	for _, in := range r.inputs {
+		r.execute(ctx, in.source, evaluate && in.path != "")
+	}
+}
+
+// combinator returns the fixpoint combinator suited to the current strategy:
+// strict strategies need the eta-expanded Z combinator.
+func (r *REPL) combinator() fixpoint.Combinator {
+	if r.strategy == "value" || r.strategy == "applicative" {
+		return fixpoint.Z
+	}
+	return fixpoint.Y
+}
+
+// evaluate evaluates t in the environment with the named strategy and prints
+// the result, reporting the steps to tracer if not nil.
+func (r *REPL) evaluate(ctx context.Context, t term.Term, strategy string, tracer eval.Tracer) {
+	closed := r.env.Close(t)
+	if closed.Failure() {
+		r.fail(closed.Error())
+		return
+	}
+	evaluator := eval.NewEvaluator(strategy, r.maxSteps, tracer)
+	if evaluator.Failure() {
+		r.fail(evaluator.Error())
+		return
+	}
+	result := evaluator.Value().Evaluate(ctx, closed.Value())
+	if result.Failure() {
+		r.fail(result.Error())
+		return
+	}
+	fmt.Fprintln(r.out, result.Value())
+}
+
+// expression parses source text that must consist of a single expression.
+func (r *REPL) expression(source string) (term.Term, bool) {
+	ast := parser.ParseString(source)
+	if ast.Failure() {
+		r.fail(ast.Error())
+		return nil, false
+	}
+	statements := ast.Value().Children()
+	if len(statements) != 2 || !isExpression(statements[0]) {
+		r.fail(fmt.Errorf("expected a single expression"))
+		return nil, false
+	}
+	t := term.FromAST(statements[0])
+	if t.Failure() {
+		r.fail(t.Error())
+		return nil, false
+	}
+	return t.Value(), true
+}
+
+// isExpression reports whether a statement is a bare expression.
+func isExpression(statement parser.ASTNode) bool {
+	switch statement.NodeType() {
+	case lexer.MODULE, lexer.ASSIGN, lexer.EOF:
+		return false
+	}
+	return true
+}
+
+// load executes the content of a file, which replaces the previous content
+// of the same file among the inputs.
+func (r *REPL) load(ctx context.Context, path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		r.fail(err)
+		return false
+	}
+	r.inputs = slices.DeleteFunc(r.inputs, func(in input) bool { return in.path == path })
+	r.inputs = append(r.inputs, input{path: path, source: string(content)})
+	r.execute(ctx, string(content), true)
+	return true
+}
+
+// fail prints an error.
+func (r *REPL) fail(err error) {
+	fmt.Fprintf(r.out, "%v\n", err)
+}
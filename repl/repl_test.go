@@ -0,0 +1,179 @@
+package repl
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// session runs the REPL over the given lines and returns its output, without
+// the banner, the prompts and the line break ending the session.
+func session(t *testing.T, lines ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	err := New(&out).Run(context.Background(), strings.NewReader(strings.Join(lines, "\n")))
+	require.NoError(t, err)
+
+	output := strings.TrimPrefix(out.String(), "λ.c, type :help for help\n")
+	output = strings.ReplaceAll(output, Prompt, "")
+	output = strings.ReplaceAll(output, ContinuationPrompt, "")
+	return strings.TrimSuffix(output, "\n")
+}
+
+func TestEvaluation(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.Equal("\\x.x\ny\n\\y1.y\n", session(t,
+		`i := \x.x`,
+		`k := \x.\y.x`,
+		`i`,
+		`k (i y) z`,
+		`k y`,
+	))
+}
+
+func TestRecursion(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.Equal("\\f.\\x.x\n", session(t,
+		`zero := \f.\x.x`,
+		`pred := \n.\f.\x.n (\g.\h.h (g f)) (\_.x) (\u.u)`,
+		`down := \n.n (\_.down (pred n)) zero`,
+		`down (\f.\x.f (f x))`,
+	))
+}
+
+func TestInterrupt(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	// The second input is interrupted as soon as it starts
+	var out bytes.Buffer
+	r, inputs := New(&out), 0
+	r.interrupt = func(ctx context.Context) (context.Context, context.CancelFunc) {
+		inputs++
+		ctx, cancel := context.WithCancel(ctx)
+		if inputs == 2 {
+			cancel()
+		}
+		return ctx, cancel
+	}
+	lines := []string{`:steps 0`, `(\x.x x) (\x.x x)`, `(\x.x) y`}
+	is.NoError(r.Run(context.Background(), strings.NewReader(strings.Join(lines, "\n"))))
+	is.Contains(out.String(), "evaluation interrupted after 1024 steps: context canceled\n")
+	is.True(strings.HasSuffix(out.String(), "y\n"+Prompt+"\n"), "%q", out.String())
+}
+
+func TestContinuation(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.Equal("a\n", session(t, `((\x.x)`, `a)`))
+	is.Equal(0, depth(`"(" (\x.x)`))
+}
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		lines    []string
+		expected string
+	}{
+		{"env", []string{`i := \x.x`, `k := \x.\y.x`, `:env`}, "i := \\x.x\nk := \\x.\\y.x\n"},
+		{"type", []string{`k := \x.\y.x`, `:type k`}, "k : a -> b -> a\n"},
+		{"untypable", []string{`:t \x.x x`}, "1:3: error: x x has no simple type: a cannot be applied to a " +
+			"(this would require an infinite type, as self-applications such as x x do)\n"},
+		{"trace", []string{`:trace (\x.x) y`}, "1. 1:1 leftmost-outermost redex: [(\\x.x) y]\n   => y\ny\n"},
+		{"trace krivine", []string{`:strategy krivine`, `:trace (\x.x) y`}, "note: the krivine backend cannot be traced, " +
+			"tracing normal order instead\n1. 1:1 leftmost-outermost redex: [(\\x.x) y]\n   => y\ny\n"},
+		{"strategy", []string{`:strategy name`, `:strategy`, `\x.(\y.y) x`}, "name\n\\x.(\\y.y) x\n"},
+		{"unknown strategy", []string{`:strategy lazy`}, "unknown evaluation strategy \"lazy\", " +
+			"expected one of normal, applicative, value, name, need, head, krivine, krivine-need\n"},
+		{"steps", []string{`:steps 10`, `:steps`, `(\x.x x) (\x.x x)`}, "10\nstep limit exceeded: gave up after 10 steps\n"},
+		{"abbreviated", []string{`:s`}, "normal\n"},
+		{"unknown", []string{`:frobnicate`}, "unknown command :frobnicate, type :help for help\n"},
+		{"quit", []string{`:quit`, `x`}, ""},
+		{"not an expression", []string{`:type i := \x.x`}, "expected a single expression\n"},
+		{"parse error", []string{`\x.`}, "1:3: error: unexpected end of input, expected an expression\n"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			is.Equal(testCase.expected, session(t, testCase.lines...))
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prelude.lc")
+	is.NoError(os.WriteFile(path, []byte("i := \\x.x\nj := \\x.x\n"), 0o600))
+
+	var out bytes.Buffer
+	r := New(&out)
+	ctx := context.Background()
+	is.True(r.Execute(ctx, ":load "+path))
+	is.True(r.Execute(ctx, "k := i b"))
+	is.True(r.Execute(ctx, "i a"))
+
+	// The definitions removed from the file are dropped, those entered at
+	// the prompt kept
+	is.NoError(os.WriteFile(path, []byte("i := \\x.\\y.x\n"), 0o600))
+	is.True(r.Execute(ctx, ":reload"))
+	is.True(r.Execute(ctx, "i a"))
+	is.True(r.Execute(ctx, "j a"))
+	is.True(r.Execute(ctx, "k"))
+	is.True(r.Execute(ctx, ":load "+path))
+	is.True(r.Execute(ctx, ":load "+filepath.Join(dir, "missing.lc")))
+	is.Equal([]input{{source: "k := i b"}, {path: path, source: "i := \\x.\\y.x\n"}}, r.inputs)
+
+	lines := strings.Split(out.String(), "\n")
+	is.Equal([]string{"a", `\y.a`, "j a", `\y.b`}, lines[:4])
+	is.Contains(lines[4], "no such file or directory")
+}
+
+func TestStrategyRewritesRecursion(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	// Recursion through Y diverges under call-by-value: switching strategy
+	// rewrites the definitions with Z
+	is.Equal("x\n", session(t,
+		`zero := \f.\x.x`,
+		`pred := \n.\f.\x.n (\g.\h.h (g f)) (\_.x) (\u.u)`,
+		`down := \n.n (\_.down (pred n)) zero`,
+		`:strategy value`,
+		`down (\f.\x.f (f x)) f x`,
+	))
+}
+
+func TestLoadMutualRecursion(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "parity.lc")
+	is.NoError(os.WriteFile(path, []byte(`t := \x.\_.x
+f := \_.\y.y
+zero := \n.n (\_.f) t
+pred := \n.\f.\x.n (\g.\h.h (g f)) (\_.x) (\u.u)
+even := \n.zero n t (odd (pred n))
+odd := \n.zero n f (even (pred n))
+`), 0o600))
+
+	is.Equal("\\_.\\y.y\n", session(t, ":load "+path, `even (\f.\x.f (f (f x)))`))
+}
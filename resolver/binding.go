@@ -0,0 +1,55 @@
+package resolver
+
+import "github.com/denisdubochevalier/lambdac/lexer"
+
+// Kind tells which construct introduced a Binding.
+type Kind int
+
+const (
+	Parameter  Kind = iota // Parameter is a name bound by a lambda abstraction.
+	Definition             // Definition is a name bound by a top-level `:=` statement.
+	Namespace              // Namespace is an alias bound by a `|` import statement.
+)
+
+var kinds = []string{
+	Parameter:  "parameter",
+	Definition: "definition",
+	Namespace:  "namespace",
+}
+
+// String returns a human readable name for the Kind, suitable for messages.
+func (k Kind) String() string {
+	if int(k) < 0 || int(k) >= len(kinds) {
+		return "unknown"
+	}
+	return kinds[k]
+}
+
+// Binding is the site where a name is introduced: the parameter of a lambda,
+// the left-hand side of a definition or the alias of an import. Bindings are
+// values and can be compared with ==, two bindings being equal when they come
+// from the same token.
+type Binding struct {
+	kind  Kind
+	token lexer.Token
+}
+
+// Kind returns the construct that introduced the Binding.
+func (b Binding) Kind() Kind {
+	return b.kind
+}
+
+// Name returns the bound name.
+func (b Binding) Name() string {
+	return b.token.Literal().String()
+}
+
+// Token returns the identifier token of the binding site.
+func (b Binding) Token() lexer.Token {
+	return b.token
+}
+
+// Position returns the location of the binding site in the source text.
+func (b Binding) Position() lexer.Position {
+	return b.token.Position()
+}
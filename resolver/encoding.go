@@ -0,0 +1,159 @@
+package resolver
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+// wireResolution is the serialized form of a Resolution.
+type wireResolution struct {
+	References  []wireReference
+	Uses        []wireUse
+	Definitions []wireBinding
+	Namespaces  []wireBinding
+	Qualified   []wireQualified
+	Diagnostics []wireDiagnostic
+}
+
+type wireToken struct {
+	Type     lexer.TokenType
+	Row, Col int
+	Literal  string
+}
+
+type wireBinding struct {
+	Kind  Kind
+	Token wireToken
+}
+
+type wireReference struct {
+	Row, Col int
+	Binding  wireBinding
+}
+
+type wireUse struct {
+	Binding   wireBinding
+	Positions [][2]int
+}
+
+type wireQualified struct {
+	Namespace  wireBinding
+	Name       wireToken
+	Definition []wireBinding // empty or a single binding
+}
+
+type wireDiagnostic struct {
+	Severity      diagnostic.Severity
+	Row, Col      int
+	Message, Hint string
+}
+
+// Encode serializes a resolution, so that it can be stored, for instance in a
+// build cache, and restored with Decode rather than computed again.
+func Encode(r Resolution) []byte {
+	var w wireResolution
+	for position, b := range r.references {
+		w.References = append(w.References, wireReference{Row: position.Row(), Col: position.Col(), Binding: toWireBinding(b)})
+	}
+	for b, positions := range r.uses {
+		use := wireUse{Binding: toWireBinding(b)}
+		for _, position := range positions {
+			use.Positions = append(use.Positions, [2]int{position.Row(), position.Col()})
+		}
+		w.Uses = append(w.Uses, use)
+	}
+	for _, b := range r.definitions {
+		w.Definitions = append(w.Definitions, toWireBinding(b))
+	}
+	for _, b := range r.namespaces {
+		w.Namespaces = append(w.Namespaces, toWireBinding(b))
+	}
+	for _, q := range r.qualified {
+		wq := wireQualified{Namespace: toWireBinding(q.namespace), Name: toWireToken(q.name)}
+		if q.definition.Just() {
+			wq.Definition = []wireBinding{toWireBinding(q.definition.Value())}
+		}
+		w.Qualified = append(w.Qualified, wq)
+	}
+	for _, d := range r.diagnostics {
+		w.Diagnostics = append(w.Diagnostics, wireDiagnostic{
+			Severity: d.Severity(),
+			Row:      d.Position().Row(),
+			Col:      d.Position().Col(),
+			Message:  d.Message(),
+			Hint:     d.Hint(),
+		})
+	}
+	var b bytes.Buffer
+	// Encoding into memory a type known to be encodable cannot fail
+	_ = gob.NewEncoder(&b).Encode(w)
+	return b.Bytes()
+}
+
+// Decode restores a resolution serialized by Encode. It fails if the data is
+// not the output of Encode, typically because it has been corrupted.
+func Decode(data []byte) monad.Result[Resolution, error] {
+	var w wireResolution
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&w); err != nil {
+		return monad.Fail[Resolution, error](err)
+	}
+	r := Resolution{
+		references: map[lexer.Position]Binding{},
+		uses:       map[Binding][]lexer.Position{},
+	}
+	for _, ref := range w.References {
+		r.references[lexer.NewPosition(ref.Row, ref.Col)] = fromWireBinding(ref.Binding)
+	}
+	for _, use := range w.Uses {
+		b := fromWireBinding(use.Binding)
+		for _, position := range use.Positions {
+			r.uses[b] = append(r.uses[b], lexer.NewPosition(position[0], position[1]))
+		}
+	}
+	for _, b := range w.Definitions {
+		r.definitions = append(r.definitions, fromWireBinding(b))
+	}
+	for _, b := range w.Namespaces {
+		r.namespaces = append(r.namespaces, fromWireBinding(b))
+	}
+	for _, wq := range w.Qualified {
+		q := QualifiedReference{
+			namespace:  fromWireBinding(wq.Namespace),
+			name:       fromWireToken(wq.Name),
+			definition: monad.None[Binding](),
+		}
+		if len(wq.Definition) > 0 {
+			q.definition = monad.Some(fromWireBinding(wq.Definition[0]))
+		}
+		r.qualified = append(r.qualified, q)
+	}
+	for _, d := range w.Diagnostics {
+		decoded := diagnostic.New(d.Severity, lexer.NewPosition(d.Row, d.Col), "%s", d.Message)
+		if d.Hint != "" {
+			decoded = decoded.WithHint("%s", d.Hint)
+		}
+		r.diagnostics = append(r.diagnostics, decoded)
+	}
+	return monad.Succeed[Resolution, error](r)
+}
+
+func toWireToken(t lexer.Token) wireToken {
+	return wireToken{Type: t.Type(), Row: t.Position().Row(), Col: t.Position().Col(), Literal: t.Literal().String()}
+}
+
+func fromWireToken(w wireToken) lexer.Token {
+	return lexer.NewToken(w.Type, lexer.NewPosition(w.Row, w.Col), lexer.Literal(w.Literal))
+}
+
+func toWireBinding(b Binding) wireBinding {
+	return wireBinding{Kind: b.kind, Token: toWireToken(b.token)}
+}
+
+func fromWireBinding(w wireBinding) Binding {
+	return Binding{kind: w.Kind, token: fromWireToken(w.Token)}
+}
@@ -0,0 +1,433 @@
+// Package resolver implements the scope resolution pass of λ.c.
+//
+// Overview:
+//
+// The resolver walks the AST produced by the parser and links every variable
+// occurrence to the site that binds it. Three constructs introduce names:
+//
+//   - Lambda abstractions bind their parameter within their body. An inner
+//     binding shadows outer ones.
+//   - Top-level definitions (`name := expression`) bind their name in the whole
+//     program, regardless of the order of the statements.
+//   - Imports (`alias | "path"`) bind a namespace alias, which can only be used
+//     on the left of the namespace dereference operator (`alias->name`).
+//
+// Lambda parameters take precedence over top-level definitions, which in turn
+// take precedence over nothing: an identifier matching none of them is
+// unbound.
+//
+// Diagnostics:
+//
+// Problems are not reported through a failing monad but accumulated as
+// diagnostic.Diagnostic values, so that a single pass reports every unbound
+// identifier at once. The resolver reports:
+//
+//   - unbound identifiers, with a "did you mean" hint when a visible name is
+//     close enough to be a plausible typo;
+//   - duplicate top-level definitions and imports, pointing to the first one;
+//   - namespaces used as values, and dereferences of unknown namespaces;
+//   - dereferences of names the imported module does not define, or keeps
+//     private, once ResolveQualified is given the imported modules.
+//
+// Visibility:
+//
+// Top-level names starting with an underscore, such as `_helper`, are private
+// to their module: they are visible in the whole module, but cannot be
+// dereferenced with `alias->_helper` from another one.
+//
+// Usage:
+//
+//	program := parser.ParseString(source)
+//	if program.Failure() {
+//	  // handle the syntax error
+//	}
+//	resolution := resolver.Resolve(program.Value())
+//	for _, d := range resolution.Diagnostics() {
+//	  fmt.Println(d)
+//	}
+package resolver
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+)
+
+// QualifiedReference is an occurrence of `alias->name`. The resolver checks
+// that the alias refers to an import, but the dereferenced name lives in
+// another module: it is only checked by ResolveQualified, once the module
+// loader has resolved the imported module.
+type QualifiedReference struct {
+	namespace  Binding
+	name       lexer.Token
+	definition monad.Maybe[Binding]
+}
+
+// Namespace returns the import binding the alias refers to.
+func (q QualifiedReference) Namespace() Binding {
+	return q.namespace
+}
+
+// Name returns the token of the dereferenced identifier.
+func (q QualifiedReference) Name() lexer.Token {
+	return q.name
+}
+
+// Definition returns the definition of the imported module the reference
+// designates, once resolved by ResolveQualified.
+func (q QualifiedReference) Definition() monad.Maybe[Binding] {
+	return q.definition
+}
+
+// Resolution is the outcome of the scope resolution pass. It maps variable
+// occurrences, designated by the position of their token, to their bindings,
+// and lists the top-level bindings and the diagnostics found on the way.
+type Resolution struct {
+	references  map[lexer.Position]Binding
+	uses        map[Binding][]lexer.Position
+	definitions []Binding
+	namespaces  []Binding
+	qualified   []QualifiedReference
+	diagnostics []diagnostic.Diagnostic
+}
+
+// Lookup returns the Binding the identifier located at the given position
+// refers to. It yields monad.None for positions that are not resolved variable
+// occurrences. Binding sites resolve to themselves, which makes Lookup usable
+// for go-to-definition regardless of where the cursor stands.
+func (r Resolution) Lookup(position lexer.Position) monad.Maybe[Binding] {
+	if b, ok := r.references[position]; ok {
+		return monad.Some(b)
+	}
+	return monad.None[Binding]()
+}
+
+// References lists the positions of the occurrences referring to the given
+// Binding, in source order. The binding site itself is not included.
+func (r Resolution) References(b Binding) []lexer.Position {
+	positions := append([]lexer.Position{}, r.uses[b]...)
+	sortPositions(positions)
+	return positions
+}
+
+// DefinitionsUsedBy lists the top-level definitions referenced from within the
+// given node, typically the right-hand side of a definition or a bare
+// expression statement. Each definition appears once, in order of first
+// occurrence.
+func (r Resolution) DefinitionsUsedBy(node parser.ASTNode) []Binding {
+	used := []Binding{}
+	seen := map[Binding]bool{}
+	var walk func(parser.ASTNode)
+	walk = func(node parser.ASTNode) {
+		if node.NodeType() == lexer.IDENT {
+			if b := r.Lookup(node.Token().Position()); b.Just() &&
+				b.Value().Kind() == Definition && b.Value().Position() != node.Token().Position() &&
+				!seen[b.Value()] {
+				seen[b.Value()] = true
+				used = append(used, b.Value())
+			}
+		}
+		for _, child := range node.Children() {
+			walk(child)
+		}
+	}
+	walk(node)
+	return used
+}
+
+// Definitions returns the top-level definitions of the program, in source
+// order. Duplicates are not included.
+func (r Resolution) Definitions() []Binding {
+	return append([]Binding{}, r.definitions...)
+}
+
+// Definition returns the top-level definition bound to name, if any.
+func (r Resolution) Definition(name string) monad.Maybe[Binding] {
+	return findBinding(r.definitions, name)
+}
+
+// Namespaces returns the import aliases of the program, in source order.
+// Duplicates are not included.
+func (r Resolution) Namespaces() []Binding {
+	return append([]Binding{}, r.namespaces...)
+}
+
+// Qualified returns the `alias->name` occurrences whose alias resolved to an
+// import, in source order.
+func (r Resolution) Qualified() []QualifiedReference {
+	return append([]QualifiedReference{}, r.qualified...)
+}
+
+// Diagnostics returns the problems found during resolution, sorted by
+// position.
+func (r Resolution) Diagnostics() []diagnostic.Diagnostic {
+	return append([]diagnostic.Diagnostic{}, r.diagnostics...)
+}
+
+// Resolve runs the scope resolution pass over a program, as returned by
+// parser.Parse. It never fails: problems are reported in the Diagnostics of
+// the returned Resolution.
+func Resolve(program parser.ASTNode) Resolution {
+	r := Resolution{
+		references: map[lexer.Position]Binding{},
+		uses:       map[Binding][]lexer.Position{},
+	}
+	r = r.collectTopLevel(program)
+
+	for _, statement := range program.Children() {
+		switch statement.NodeType() {
+		case lexer.ASSIGN:
+			r = r.resolveExpression(statement.Children()[1], nil)
+		case lexer.MODULE, lexer.EOF:
+		default:
+			r = r.resolveExpression(statement, nil)
+		}
+	}
+
+	sort.SliceStable(r.diagnostics, func(i, j int) bool {
+		return positionLess(r.diagnostics[i].Position(), r.diagnostics[j].Position())
+	})
+	return r
+}
+
+// Declare returns the resolution of a module that only consists of the given
+// definitions, such as the modules the host application provides, which are
+// not written in λ.c. Each name is positioned as if it were defined on a
+// line of its own, in order.
+func Declare(names ...string) Resolution {
+	r := Resolution{
+		references: map[lexer.Position]Binding{},
+		uses:       map[Binding][]lexer.Position{},
+	}
+	for i, name := range names {
+		token := lexer.NewToken(lexer.IDENT, lexer.NewPosition(i+1, 0), lexer.Literal(name))
+		r.definitions = append(r.definitions, Binding{kind: Definition, token: token})
+		r.references[token.Position()] = r.definitions[i]
+	}
+	return r
+}
+
+// collectTopLevel gathers the definitions and imports of the program before
+// any expression is resolved, since top-level names are visible everywhere.
+func (r Resolution) collectTopLevel(program parser.ASTNode) Resolution {
+	for _, statement := range program.Children() {
+		var b Binding
+		switch statement.NodeType() {
+		case lexer.ASSIGN:
+			b = Binding{kind: Definition, token: statement.Children()[0].Token()}
+		case lexer.MODULE:
+			b = Binding{kind: Namespace, token: statement.Children()[0].Token()}
+		default:
+			continue
+		}
+
+		previous := findBinding(r.definitions, b.Name())
+		if previous.Nothing() {
+			previous = findBinding(r.namespaces, b.Name())
+		}
+		if previous.Just() {
+			r.diagnostics = append(r.diagnostics, diagnostic.Errorf(
+				b.Position(), "%q redeclared", b.Name(),
+			).WithHint(
+				"previous %s at %d:%d", previous.Value().Kind(),
+				previous.Value().Position().Row(), previous.Value().Position().Col(),
+			))
+			continue
+		}
+
+		r.references[b.Position()] = b
+		if b.kind == Definition {
+			r.definitions = append(r.definitions, b)
+		} else {
+			r.namespaces = append(r.namespaces, b)
+		}
+	}
+	return r
+}
+
+// resolveExpression walks an expression with the lambda parameters currently
+// in scope, recording references and diagnostics.
+func (r Resolution) resolveExpression(node parser.ASTNode, s *scope) Resolution {
+	children := node.Children()
+	switch node.NodeType() {
+	case lexer.IDENT:
+		return r.resolveIdent(node.Token(), s)
+	case lexer.LAMBDA:
+		param := Binding{kind: Parameter, token: children[0].Token()}
+		r.references[param.Position()] = param
+		return r.resolveExpression(children[1], s.bind(param))
+	case lexer.NSDEREF:
+		return r.resolveQualified(children[0].Token(), children[1].Token())
+	default:
+		for _, child := range children {
+			r = r.resolveExpression(child, s)
+		}
+		return r
+	}
+}
+
+// resolveIdent links a plain identifier to the innermost parameter or to the
+// top-level definition of the same name.
+func (r Resolution) resolveIdent(token lexer.Token, s *scope) Resolution {
+	name := token.Literal().String()
+	b := s.lookup(name)
+	if b.Nothing() {
+		b = findBinding(r.definitions, name)
+	}
+
+	if b.Just() {
+		return r.link(token, b.Value())
+	}
+
+	if ns := findBinding(r.namespaces, name); ns.Just() {
+		r = r.link(token, ns.Value())
+		r.diagnostics = append(r.diagnostics, diagnostic.Errorf(
+			token.Position(), "namespace %q used as a value", name,
+		).WithHint("dereference it with %s->name", name))
+		return r
+	}
+
+	d := diagnostic.Errorf(token.Position(), "unbound identifier %q", name)
+	if candidate, ok := suggest(name, append(s.names(), bindingNames(r.definitions)...)); ok {
+		d = d.WithHint("did you mean %q?", candidate)
+	}
+	r.diagnostics = append(r.diagnostics, d)
+	return r
+}
+
+// resolveQualified checks that the left operand of `alias->name` is an
+// imported namespace.
+func (r Resolution) resolveQualified(alias, name lexer.Token) Resolution {
+	ns := findBinding(r.namespaces, alias.Literal().String())
+	if ns.Nothing() {
+		d := diagnostic.Errorf(alias.Position(), "unknown namespace %q", alias.Literal())
+		if candidate, ok := suggest(alias.Literal().String(), bindingNames(r.namespaces)); ok {
+			d = d.WithHint("did you mean %q?", candidate)
+		}
+		r.diagnostics = append(r.diagnostics, d)
+		return r
+	}
+
+	r = r.link(alias, ns.Value())
+	r.qualified = append(r.qualified, QualifiedReference{
+		namespace: ns.Value(), name: name, definition: monad.None[Binding](),
+	})
+	return r
+}
+
+// Import is what the resolver needs to know about an imported module to
+// resolve the `alias->name` occurrences designating it: its resolution, and
+// the name of its file for messages.
+type Import struct {
+	file       string
+	resolution Resolution
+}
+
+// NewImport describes an imported module.
+func NewImport(file string, resolution Resolution) Import {
+	return Import{file: file, resolution: resolution}
+}
+
+// IsPrivate tells whether a top-level name is private to its module: names
+// starting with an underscore cannot be dereferenced from other modules.
+func IsPrivate(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
+
+// ResolveQualified resolves the `alias->name` occurrences of the program
+// against the modules it imports, keyed by alias. It reports the names the
+// imported modules do not define, and those that are private to them.
+// Aliases missing from imports are left unresolved, without any diagnostic:
+// the module loader reports the imports it fails to load.
+func (r Resolution) ResolveQualified(imports map[string]Import) Resolution {
+	qualified := make([]QualifiedReference, 0, len(r.qualified))
+	diagnostics := append([]diagnostic.Diagnostic{}, r.diagnostics...)
+	for _, q := range r.qualified {
+		imported, ok := imports[q.namespace.Name()]
+		if !ok {
+			qualified = append(qualified, q)
+			continue
+		}
+		name := q.name.Literal().String()
+		definition := imported.resolution.Definition(name)
+		switch {
+		case definition.Nothing():
+			d := diagnostic.Errorf(q.name.Position(), "namespace %q has no definition %q", q.namespace.Name(), name)
+			if candidate, ok := suggest(name, publicNames(imported.resolution.definitions)); ok {
+				d = d.WithHint("did you mean %q?", candidate)
+			}
+			diagnostics = append(diagnostics, d)
+		case IsPrivate(name):
+			position := definition.Value().Position()
+			diagnostics = append(diagnostics, diagnostic.Errorf(
+				q.name.Position(), "%q is private to namespace %q", name, q.namespace.Name(),
+			).WithHint(
+				"defined at %s:%d:%d, names starting with _ are only visible in their own module",
+				imported.file, position.Row(), position.Col(),
+			))
+		default:
+			q.definition = definition
+		}
+		qualified = append(qualified, q)
+	}
+
+	r.qualified = qualified
+	r.diagnostics = diagnostics
+	diagnostic.Sort(r.diagnostics)
+	return r
+}
+
+// publicNames extracts the names of the given bindings that are not private.
+func publicNames(bindings []Binding) []string {
+	names := make([]string, 0, len(bindings))
+	for _, name := range bindingNames(bindings) {
+		if !IsPrivate(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// link records that the identifier token refers to the given Binding.
+func (r Resolution) link(token lexer.Token, b Binding) Resolution {
+	r.references[token.Position()] = b
+	r.uses[b] = append(r.uses[b], token.Position())
+	return r
+}
+
+// findBinding returns the first binding of the given name.
+func findBinding(bindings []Binding, name string) monad.Maybe[Binding] {
+	for _, b := range bindings {
+		if b.Name() == name {
+			return monad.Some(b)
+		}
+	}
+	return monad.None[Binding]()
+}
+
+// bindingNames extracts the names of the given bindings.
+func bindingNames(bindings []Binding) []string {
+	names := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		names = append(names, b.Name())
+	}
+	return names
+}
+
+// positionLess orders positions as they appear in the source text.
+func positionLess(a, b lexer.Position) bool {
+	if a.Row() != b.Row() {
+		return a.Row() < b.Row()
+	}
+	return a.Col() < b.Col()
+}
+
+// sortPositions sorts positions in source order.
+func sortPositions(positions []lexer.Position) {
+	sort.Slice(positions, func(i, j int) bool { return positionLess(positions[i], positions[j]) })
+}
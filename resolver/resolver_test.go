@@ -0,0 +1,226 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+)
+
+func resolve(t *testing.T, source string) Resolution {
+	t.Helper()
+	program := parser.ParseString(source)
+	require.True(t, program.Success(), "%v", program.Error())
+	return Resolve(program.Value())
+}
+
+func TestResolveLinksOccurrences(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	r := resolve(t, "k := \\x.\\y.x\nio | \"std/io\"\nmain := k (\\x.x) io->print")
+	is.Empty(r.Diagnostics())
+
+	// The x in the body of k refers to the outer parameter
+	b := r.Lookup(lexer.NewPosition(1, 11))
+	is.True(b.Just())
+	is.Equal(Parameter, b.Value().Kind())
+	is.Equal(lexer.NewPosition(1, 6), b.Value().Position())
+
+	// k in main refers to the top-level definition
+	b = r.Lookup(lexer.NewPosition(3, 8))
+	is.True(b.Just())
+	is.Equal(Definition, b.Value().Kind())
+	is.Equal("k", b.Value().Name())
+	is.Equal([]lexer.Position{lexer.NewPosition(3, 8)}, r.References(b.Value()))
+
+	// Binding sites resolve to themselves
+	is.Equal(b, r.Lookup(lexer.NewPosition(1, 0)))
+
+	// The namespace alias is linked, the dereferenced name is recorded
+	is.Len(r.Qualified(), 1)
+	is.Equal("io", r.Qualified()[0].Namespace().Name())
+	is.Equal("print", r.Qualified()[0].Name().Literal().String())
+	is.Equal(Namespace, r.Lookup(lexer.NewPosition(3, 17)).Value().Kind())
+}
+
+func TestResolveShadowing(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	r := resolve(t, "x := \\a.a\ny := \\x.\\x.x")
+	is.Empty(r.Diagnostics())
+
+	b := r.Lookup(lexer.NewPosition(2, 11))
+	is.True(b.Just())
+	is.Equal(Parameter, b.Value().Kind())
+	is.Equal(lexer.NewPosition(2, 9), b.Value().Position())
+}
+
+func TestResolveForwardReference(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	r := resolve(t, "main := i i\ni := \\x.x")
+	is.Empty(r.Diagnostics())
+	is.Equal([]string{"main", "i"}, bindingNames(r.Definitions()))
+	is.True(r.Definition("i").Just())
+	is.True(r.Definition("j").Nothing())
+}
+
+func TestResolveDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			"unbound with suggestion",
+			"identity := \\x.x\nmain := identiy y",
+			[]string{
+				`2:8: error: unbound identifier "identiy" (did you mean "identity"?)`,
+				`2:16: error: unbound identifier "y"`,
+			},
+		},
+		{
+			"suggestion favors parameters",
+			"fobra := \\x.x\nbar := \\fobar.fobr",
+			[]string{`2:14: error: unbound identifier "fobr" (did you mean "fobar"?)`},
+		},
+		{
+			"parameter out of scope",
+			"f := (\\x.x) x",
+			[]string{`1:12: error: unbound identifier "x"`},
+		},
+		{
+			"duplicate definition",
+			"i := \\x.x\nk := \\x.\\y.x\ni := \\y.y",
+			[]string{`3:0: error: "i" redeclared (previous definition at 1:0)`},
+		},
+		{
+			"definition clashing with import",
+			"io | \"std/io\"\nio := \\x.x",
+			[]string{`2:0: error: "io" redeclared (previous namespace at 1:0)`},
+		},
+		{
+			"namespace used as value",
+			"io | \"std/io\"\nmain := io",
+			[]string{`2:8: error: namespace "io" used as a value (dereference it with io->name)`},
+		},
+		{
+			"unknown namespace",
+			"std | \"std\"\nmain := sdt->k",
+			[]string{`2:8: error: unknown namespace "sdt" (did you mean "std"?)`},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			messages := []string{}
+			for _, d := range resolve(t, testCase.input).Diagnostics() {
+				messages = append(messages, d.Error())
+			}
+			is.Equal(testCase.expected, messages)
+		})
+	}
+}
+
+func TestResolveQualified(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	list := resolve(t, "nil := \\c.\\n.n\ncons := \\h.\\t.\\c.\\n.c h (t c n)\n_nul := nil")
+	r := resolve(t, "l | \"std/list\"\nio | \"std/io\"\nmain := l->cons a (l->nill io->read) l->_nul")
+	r = r.ResolveQualified(map[string]Import{"l": NewImport("list.lc", list)})
+
+	messages := []string{}
+	for _, d := range r.Diagnostics() {
+		messages = append(messages, d.Error())
+	}
+	is.Equal([]string{
+		`3:16: error: unbound identifier "a"`,
+		`3:22: error: namespace "l" has no definition "nill" (did you mean "nil"?)`,
+		`3:40: error: "_nul" is private to namespace "l" ` +
+			`(defined at list.lc:3:0, names starting with _ are only visible in their own module)`,
+	}, messages)
+
+	qualified := r.Qualified()
+	is.Len(qualified, 4)
+	is.Equal(list.Definition("cons"), qualified[0].Definition())
+	is.True(qualified[1].Definition().Nothing())
+	// io was not given, and is left unresolved without complaint
+	is.True(qualified[2].Definition().Nothing())
+	is.True(qualified[3].Definition().Nothing())
+}
+
+func TestSuggest(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	s, ok := suggest("succ", []string{"pred", "sub", "suc"})
+	is.True(ok)
+	is.Equal("suc", s)
+
+	_, ok = suggest("x", []string{"abc"})
+	is.False(ok)
+
+	_, ok = suggest("xs", []string{"ys"})
+	is.False(ok)
+
+	is.Equal(3, editDistance("kitten", "sitting"))
+	is.Equal(1, editDistance("λx", "λy"))
+	is.Equal(1, editDistance("sdt", "std"))
+}
+
+func TestDefinitionsUsedBy(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	program := parser.ParseString("i := \\x.x\nk := \\x.\\y.x\ns := k i (k k) \\i.i")
+	is.True(program.Success())
+	r := Resolve(program.Value())
+
+	s := program.Value().Children()[2]
+	is.Equal([]string{"k", "i"}, bindingNames(r.DefinitionsUsedBy(s)))
+	is.Empty(r.DefinitionsUsedBy(program.Value().Children()[0]))
+}
+
+func TestDeclare(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	os := Declare("getenv", "args")
+	is.Len(os.Definitions(), 2)
+	is.Empty(os.Diagnostics())
+
+	r := resolve(t, "os | \"host/os\"\nmain := os->getenv (os->argv)")
+	r = r.ResolveQualified(map[string]Import{"os": NewImport("<host>/host/os", os)})
+	is.Len(r.Diagnostics(), 1)
+	is.Equal(`2:24: error: namespace "os" has no definition "argv" (did you mean "args"?)`, r.Diagnostics()[0].Error())
+	is.Equal(os.Definition("getenv"), r.Qualified()[0].Definition())
+	is.Equal(2, os.Definition("args").Value().Position().Row())
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	imported := resolve(t, "pair := \\a.\\b.\\f.f a b\n_first := \\p.p (\\a.\\_.a)\n")
+	r := resolve(t, "p | \"std/pair\"\nmain := p->pair (p->_first x) (p->pairs y) \\z.z\n").
+		ResolveQualified(map[string]Import{"p": NewImport("std/pair.lc", imported)})
+	is.Len(r.Diagnostics(), 4)
+
+	decoded := Decode(Encode(r))
+	is.True(decoded.Success(), "%v", decoded.Error())
+	is.Equal(r, decoded.Value())
+
+	is.True(Decode([]byte("garbage")).Failure())
+}
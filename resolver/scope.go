@@ -0,0 +1,37 @@
+package resolver
+
+import "github.com/denisdubochevalier/monad"
+
+// scope is an immutable linked list of the lambda parameters visible at a
+// given point of the program, innermost first. Entering an abstraction
+// produces a new scope pointing to its parent, leaving the parent untouched,
+// which mirrors the lexical structure of the program.
+type scope struct {
+	binding Binding
+	parent  *scope
+}
+
+// bind returns a new scope in which the given Binding shadows any previous
+// binding of the same name.
+func (s *scope) bind(b Binding) *scope {
+	return &scope{binding: b, parent: s}
+}
+
+// lookup searches the scope chain for the innermost binding of name.
+func (s *scope) lookup(name string) monad.Maybe[Binding] {
+	for current := s; current != nil; current = current.parent {
+		if current.binding.Name() == name {
+			return monad.Some(current.binding)
+		}
+	}
+	return monad.None[Binding]()
+}
+
+// names lists the names bound in the scope chain, innermost first.
+func (s *scope) names() []string {
+	names := []string{}
+	for current := s; current != nil; current = current.parent {
+		names = append(names, current.binding.Name())
+	}
+	return names
+}
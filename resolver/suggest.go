@@ -0,0 +1,58 @@
+package resolver
+
+import "unicode/utf8"
+
+// suggest picks, among the candidates, the name closest to the given one in
+// terms of edit distance. A candidate is only proposed when it is close
+// enough to plausibly be a typo: one edit for names of three to five runes,
+// and about a third of the name length for longer ones. Names shorter than
+// three runes never get suggestions, as any other short name would be a
+// match. Ties are broken by the order of the candidates, which lets callers
+// favor the innermost bindings.
+func suggest(name string, candidates []string) (string, bool) {
+	length := utf8.RuneCountInString(name)
+	if length < 3 {
+		return "", false
+	}
+	threshold := max(1, length/3)
+
+	best, bestDistance := "", threshold+1
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		if d := editDistance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best, best != ""
+}
+
+// editDistance computes the optimal string alignment distance between two
+// strings: the number of rune insertions, deletions, substitutions and
+// transpositions of adjacent runes needed to turn one into the other.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
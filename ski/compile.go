@@ -0,0 +1,98 @@
+package ski
+
+import (
+	"fmt"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Basis selects the combinators Compile produces.
+type Basis int
+
+const (
+	SKI    Basis = iota // SKI produces S, K and I only.
+	Turner              // Turner adds B, C, S', B* and C', for smaller terms.
+)
+
+// Compile translates t to a combinator term of the given basis, by bracket
+// abstraction. The free variables of t are kept as variables.
+func Compile(t term.Term, basis Basis) Expr {
+	switch t := t.(type) {
+	case term.Var:
+		return NewVar(t.Name())
+	case term.App:
+		return NewApp(Compile(t.Fun(), basis), Compile(t.Arg(), basis))
+	case term.Abs:
+		return abstract(t.Param(), Compile(t.Body(), basis), basis)
+	}
+	panic(fmt.Sprintf("ski: unexpected term %T", t))
+}
+
+// abstract returns [x]e, a term without x which, applied to x, equals e.
+func abstract(x string, e Expr, basis Basis) Expr {
+	if v, ok := e.(Var); ok && v.name == x {
+		return I
+	}
+	if !occurs(x, e) {
+		return NewApp(K, e)
+	}
+	app := e.(App)
+	return combine(abstract(x, app.fun, basis), abstract(x, app.arg, basis), basis)
+}
+
+// combine returns S p q, simplified by the rules of the basis.
+func combine(p, q Expr, basis Basis) Expr {
+	kp, pIsK := unapply(p, K, 1)
+	kq, qIsK := unapply(q, K, 1)
+	switch {
+	case pIsK && qIsK:
+		return NewApp(K, NewApp(kp[0], kq[0]))
+	case pIsK && q == I:
+		return kp[0]
+	case basis == SKI:
+		return apply(S, p, q)
+	}
+
+	if pIsK {
+		if bq, ok := unapply(q, B, 2); ok {
+			return apply(BStar, kp[0], bq[0], bq[1])
+		}
+		return apply(B, kp[0], q)
+	}
+	bp, pIsB := unapply(p, B, 2)
+	switch {
+	case pIsB && qIsK:
+		return apply(CPrime, bp[0], bp[1], kq[0])
+	case qIsK:
+		return apply(C, p, kq[0])
+	case pIsB:
+		return apply(SPrime, bp[0], bp[1], q)
+	}
+	return apply(S, p, q)
+}
+
+// unapply returns the arguments of e if it is the combinator c applied to n
+// arguments.
+func unapply(e Expr, c Combinator, n int) ([]Expr, bool) {
+	args := make([]Expr, n)
+	for i := n - 1; i >= 0; i-- {
+		app, ok := e.(App)
+		if !ok {
+			return nil, false
+		}
+		args[i] = app.arg
+		e = app.fun
+	}
+	return args, e == c
+}
+
+// occurs tells whether the variable x occurs in e.
+func occurs(x string, e Expr) bool {
+	switch e := e.(type) {
+	case Var:
+		return e.name == x
+	case App:
+		return occurs(x, e.fun) || occurs(x, e.arg)
+	}
+	return false
+}
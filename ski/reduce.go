@@ -0,0 +1,190 @@
+package ski
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+)
+
+// checkInterval is the number of steps between two checks of the context.
+const checkInterval = 1024
+
+// Reducer evaluates combinator terms to normal form by graph reduction. It
+// follows the immutable builder pattern: configuration methods return
+// updated copies.
+type Reducer struct {
+	maxSteps int
+}
+
+// NewReducer returns a Reducer limited to eval.DefaultMaxSteps steps.
+func NewReducer() Reducer {
+	return Reducer{maxSteps: eval.DefaultMaxSteps}
+}
+
+// WithMaxSteps returns a copy of the reducer limited to n steps, or
+// unlimited if n is zero or less.
+func (r Reducer) WithMaxSteps(n int) Reducer {
+	r.maxSteps = n
+	return r
+}
+
+// Reduce reduces e to normal form. It fails with an error wrapping
+// eval.ErrStepLimit when the step limit is exceeded, or the context error
+// when ctx is done.
+func (r Reducer) Reduce(ctx context.Context, e Expr) monad.Result[Expr, error] {
+	g := &graph{ctx: ctx, maxSteps: r.maxSteps}
+	n := build(e)
+	if err := g.normalize(n); err != nil {
+		return monad.Fail[Expr, error](err)
+	}
+	return monad.Succeed[Expr, error](readBack(n))
+}
+
+// node is a vertex of the graph: a combinator, a variable, an application,
+// or an indirection to the node a redex reduced to, which the nodes pointing
+// to the redex share.
+type node struct {
+	kind       kind
+	combinator Combinator
+	name       string
+	fun, arg   *node
+}
+
+type kind int
+
+const (
+	combinatorNode kind = iota
+	varNode
+	appNode
+	indirectionNode // fun is the target
+)
+
+// build turns e into a graph.
+func build(e Expr) *node {
+	switch e := e.(type) {
+	case Combinator:
+		return &node{kind: combinatorNode, combinator: e}
+	case Var:
+		return &node{kind: varNode, name: e.name}
+	case App:
+		return app(build(e.fun), build(e.arg))
+	}
+	panic(fmt.Sprintf("ski: unexpected term %T", e))
+}
+
+func app(fun, arg *node) *node {
+	return &node{kind: appNode, fun: fun, arg: arg}
+}
+
+// follow skips the indirections leading from n.
+func follow(n *node) *node {
+	for n.kind == indirectionNode {
+		n = n.fun
+	}
+	return n
+}
+
+// readBack turns the graph of n back into a term, unsharing it.
+func readBack(n *node) Expr {
+	n = follow(n)
+	switch n.kind {
+	case combinatorNode:
+		return n.combinator
+	case varNode:
+		return NewVar(n.name)
+	}
+	return NewApp(readBack(n.fun), readBack(n.arg))
+}
+
+// graph holds the state of a reduction.
+type graph struct {
+	ctx      context.Context
+	maxSteps int
+	steps    int
+}
+
+// step counts a reduction step, and fails when a limit is exceeded.
+func (g *graph) step() error {
+	g.steps++
+	if g.maxSteps > 0 && g.steps > g.maxSteps {
+		return fmt.Errorf("%w: gave up after %d steps", eval.ErrStepLimit, g.maxSteps)
+	}
+	if g.steps%checkInterval == 0 {
+		if err := g.ctx.Err(); err != nil {
+			return fmt.Errorf("reduction interrupted after %d steps: %w", g.steps, err)
+		}
+	}
+	return nil
+}
+
+// normalize reduces n to weak head normal form, then the arguments of its
+// head, leftmost first.
+func (g *graph) normalize(n *node) error {
+	spine, err := g.whnf(n)
+	if err != nil {
+		return err
+	}
+	for i := len(spine) - 1; i >= 0; i-- {
+		if err := g.normalize(spine[i].arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// whnf reduces n until its head is a variable, or a combinator applied to
+// fewer arguments than it takes. It returns the applications of the spine
+// of the result, from the root down.
+func (g *graph) whnf(n *node) ([]*node, error) {
+	var spine []*node
+	current := follow(n)
+	for {
+		for current.kind == appNode {
+			spine = append(spine, current)
+			current = follow(current.fun)
+		}
+		if current.kind != combinatorNode || len(spine) < current.combinator.Arity() {
+			return spine, nil
+		}
+
+		arity := current.combinator.Arity()
+		args := make([]*node, arity)
+		for i := range args {
+			args[i] = spine[len(spine)-1-i].arg
+		}
+		redex := spine[len(spine)-arity]
+		contract(redex, current.combinator, args)
+		if err := g.step(); err != nil {
+			return nil, err
+		}
+		spine = spine[:len(spine)-arity]
+		current = follow(redex)
+	}
+}
+
+// contract overwrites the redex, the application of c to args, with its
+// contractum. Arguments used twice are shared.
+func contract(redex *node, c Combinator, args []*node) {
+	var fun, arg *node
+	switch c {
+	case I, K:
+		*redex = node{kind: indirectionNode, fun: args[0]}
+		return
+	case S:
+		fun, arg = app(args[0], args[2]), app(args[1], args[2])
+	case B:
+		fun, arg = args[0], app(args[1], args[2])
+	case C:
+		fun, arg = app(args[0], args[2]), args[1]
+	case SPrime:
+		fun, arg = app(args[0], app(args[1], args[3])), app(args[2], args[3])
+	case BStar:
+		fun, arg = args[0], app(args[1], app(args[2], args[3]))
+	case CPrime:
+		fun, arg = app(args[0], app(args[1], args[3])), args[2]
+	}
+	*redex = node{kind: appNode, fun: fun, arg: arg}
+}
@@ -0,0 +1,276 @@
+// Package ski compiles lambda terms to combinators, and evaluates the result
+// by graph reduction.
+//
+// Overview:
+//
+// Combinatory logic does without variables: every closed lambda term is
+// equal to an application of a few combinators. Compile translates terms by
+// bracket abstraction, removing their abstractions one at a time, innermost
+// first. The abstraction of x over a term, written [x]M, follows the rules:
+//
+//	[x] x     = I
+//	[x] M     = K M          if x is not free in M
+//	[x] (M N) = S ([x]M) ([x]N)
+//
+// With the basis SKI, only the combinators S, K and I are produced, and the
+// rule S (K M) I = M, eta-reduction, keeps the result from growing needlessly.
+// The basis Turner adds the combinators B, C, S', B* and C' of Turner's
+// optimized algorithm, which keep the size of the result close to the one of
+// the term:
+//
+//	S (K p) (K q)   = K (p q)
+//	S (K p) I       = p
+//	S (K p) (B q r) = B* p q r
+//	S (K p) q       = B p q
+//	S (B p q) (K r) = C' p q r
+//	S p (K q)       = C p q
+//	S (B p q) r     = S' p q r
+//
+// Free variables of the term, such as the primitives of host modules, are
+// left as they are.
+//
+// Graph reduction:
+//
+// A Reducer contracts the redexes of combinators, leftmost-outermost first,
+// on a graph in which arguments are shared rather than copied: the argument
+// S duplicates is reduced at most once. It stops at normal form, with every
+// combinator applied to fewer arguments than it takes. Measure sizes terms,
+// to compare the bases and the terms they are compiled from.
+package ski
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Expr is a combinator term: a Combinator, a Var or an App. The interface is
+// sealed; consumers are expected to inspect terms with type switches.
+type Expr interface {
+	// String renders the term with as few parentheses as possible, such as
+	// S (K K) I.
+	String() string
+
+	isExpr()
+}
+
+// Combinator is one of the combinators compiled terms are made of.
+type Combinator int
+
+const (
+	S      Combinator = iota // S f g x = f x (g x)
+	K                        // K x y = x
+	I                        // I x = x
+	B                        // B f g x = f (g x)
+	C                        // C f g x = f x g
+	SPrime                   // S' c f g x = c (f x) (g x)
+	BStar                    // B* c f g x = c (f (g x))
+	CPrime                   // C' c f g x = c (f x) g
+)
+
+// combinators holds the names and arities of the combinators.
+var combinators = [...]struct {
+	name  string
+	arity int
+}{
+	S:      {"S", 3},
+	K:      {"K", 2},
+	I:      {"I", 1},
+	B:      {"B", 3},
+	C:      {"C", 3},
+	SPrime: {"S'", 4},
+	BStar:  {"B*", 4},
+	CPrime: {"C'", 4},
+}
+
+// String returns the name of the combinator, such as S or B*.
+func (c Combinator) String() string {
+	return combinators[c].name
+}
+
+// Arity returns the number of arguments the combinator takes.
+func (c Combinator) Arity() int {
+	return combinators[c].arity
+}
+
+func (Combinator) isExpr() {}
+
+// Var is a free variable.
+type Var struct {
+	name string
+}
+
+// NewVar builds a free variable.
+func NewVar(name string) Var {
+	return Var{name: name}
+}
+
+// Name returns the name of the variable.
+func (v Var) Name() string {
+	return v.name
+}
+
+// String returns the name of the variable.
+func (v Var) String() string {
+	return v.name
+}
+
+func (Var) isExpr() {}
+
+// App is an application.
+type App struct {
+	fun Expr
+	arg Expr
+}
+
+// NewApp builds the application of fun to arg.
+func NewApp(fun, arg Expr) App {
+	return App{fun: fun, arg: arg}
+}
+
+// Fun returns the function of the application.
+func (a App) Fun() Expr {
+	return a.fun
+}
+
+// Arg returns the argument of the application.
+func (a App) Arg() Expr {
+	return a.arg
+}
+
+// String renders the application, parenthesizing arguments that are
+// applications themselves.
+func (a App) String() string {
+	var sb strings.Builder
+	write(&sb, a)
+	return sb.String()
+}
+
+func write(sb *strings.Builder, e Expr) {
+	app, ok := e.(App)
+	if !ok {
+		sb.WriteString(e.String())
+		return
+	}
+	write(sb, app.fun)
+	sb.WriteByte(' ')
+	if _, ok := app.arg.(App); ok {
+		sb.WriteByte('(')
+		write(sb, app.arg)
+		sb.WriteByte(')')
+		return
+	}
+	write(sb, app.arg)
+}
+
+func (App) isExpr() {}
+
+// apply applies f to the arguments, in order.
+func apply(f Expr, args ...Expr) Expr {
+	for _, arg := range args {
+		f = NewApp(f, arg)
+	}
+	return f
+}
+
+// ToTerm translates e back to a lambda term, replacing the combinators with
+// their definitions. The result is equal to the term e was compiled from, up
+// to beta and eta conversion: compiling \x.f x gives f.
+func ToTerm(e Expr) term.Term {
+	switch e := e.(type) {
+	case Combinator:
+		return definitions[e]
+	case Var:
+		return term.NewVar(e.name)
+	case App:
+		return term.NewApp(ToTerm(e.fun), ToTerm(e.arg))
+	}
+	panic(fmt.Sprintf("ski: unexpected term %T", e))
+}
+
+// definitions holds the definitions of the combinators as lambda terms. Their
+// parameters are renamed if need be when they are substituted, so that they
+// never capture the free variables of the arguments.
+var definitions = func() map[Combinator]term.Term {
+	c, f, g, x := term.NewVar("c"), term.NewVar("f"), term.NewVar("g"), term.NewVar("x")
+	app := func(fun term.Term, args ...term.Term) term.Term {
+		for _, arg := range args {
+			fun = term.NewApp(fun, arg)
+		}
+		return fun
+	}
+	lambda := func(params string, body term.Term) term.Term {
+		names := strings.Fields(params)
+		for i := len(names) - 1; i >= 0; i-- {
+			body = term.NewAbs(names[i], body)
+		}
+		return body
+	}
+	return map[Combinator]term.Term{
+		S:      lambda("f g x", app(f, x, app(g, x))),
+		K:      lambda("x y", x),
+		I:      lambda("x", x),
+		B:      lambda("f g x", app(f, app(g, x))),
+		C:      lambda("f g x", app(f, x, g)),
+		SPrime: lambda("c f g x", app(c, app(f, x), app(g, x))),
+		BStar:  lambda("c f g x", app(c, app(f, app(g, x)))),
+		CPrime: lambda("c f g x", app(c, app(f, x), g)),
+	}
+}()
+
+// Metrics describes the size of a combinator term.
+type Metrics struct {
+	// Size is the number of leaves of the term: its combinators and
+	// variables.
+	Size int
+	// Combinators counts the occurrences of each combinator.
+	Combinators map[Combinator]int
+}
+
+// Measure returns the metrics of e.
+func Measure(e Expr) Metrics {
+	m := Metrics{Combinators: map[Combinator]int{}}
+	var walk func(Expr)
+	walk = func(e Expr) {
+		switch e := e.(type) {
+		case Combinator:
+			m.Size++
+			m.Combinators[e]++
+		case Var:
+			m.Size++
+		case App:
+			walk(e.fun)
+			walk(e.arg)
+		}
+	}
+	walk(e)
+	return m
+}
+
+// String renders the metrics, such as "size 5: S 1, K 3, I 1", listing the
+// combinators that occur.
+func (m Metrics) String() string {
+	counts := make([]string, 0, len(m.Combinators))
+	for c := range combinators {
+		if n := m.Combinators[Combinator(c)]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%s %d", Combinator(c), n))
+		}
+	}
+	if len(counts) == 0 {
+		return fmt.Sprintf("size %d", m.Size)
+	}
+	return fmt.Sprintf("size %d: %s", m.Size, strings.Join(counts, ", "))
+}
+
+// TermSize returns the size of a lambda term, comparable with the size of
+// combinator terms: its number of variable occurrences and abstractions.
+func TermSize(t term.Term) int {
+	switch t := t.(type) {
+	case term.Abs:
+		return 1 + TermSize(t.Body())
+	case term.App:
+		return TermSize(t.Fun()) + TermSize(t.Arg())
+	}
+	return 1
+}
@@ -0,0 +1,236 @@
+package ski
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/term"
+	"github.com/denisdubochevalier/lambdac/term/termtest"
+)
+
+// parse parses a single expression.
+func parse(t *testing.T, source string) term.Term {
+	t.Helper()
+	program := parser.ParseString(source)
+	require.True(t, program.Success(), "%v", program.Error())
+	result := term.FromAST(program.Value().Children()[0])
+	require.True(t, result.Success(), "%v", result.Error())
+	return result.Value()
+}
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		source string
+		ski    string
+		turner string
+	}{
+		{"identity", `\x.x`, "I", "I"},
+		{"constant", `\x.\y.x`, "K", "K"},
+		{"false", `\x.\y.y`, "K I", "K I"},
+		{"free", `\x.y`, "K y", "K y"},
+		{"eta", `\x.f x`, "f", "f"},
+		{"self application", `\x.x x`, "S I I", "S I I"},
+		{"composition", `\f.\g.\x.f (g x)`, "S (K S) K", "B"},
+		{"swap", `\f.\x.\y.f y x`, "S (S (K S) (S (K K) S)) (K K)", "C"},
+		{"numeral", `\f.\x.f (f x)`, "S (S (K S) K) I", "S B I"},
+		{"open", `\x.g (h x) x`, "S (S (K g) h) I", "S' g h I"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			source := parse(t, testCase.source)
+			is.Equal(testCase.ski, Compile(source, SKI).String())
+			is.Equal(testCase.turner, Compile(source, Turner).String())
+		})
+	}
+}
+
+func TestReduce(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		expr     Expr
+		expected string
+	}{
+		{"I", apply(I, NewVar("x")), "x"},
+		{"K", apply(K, NewVar("x"), NewVar("y")), "x"},
+		{"S", apply(S, NewVar("f"), NewVar("g"), NewVar("x")), "f x (g x)"},
+		{"B", apply(B, NewVar("f"), NewVar("g"), NewVar("x")), "f (g x)"},
+		{"C", apply(C, NewVar("f"), NewVar("g"), NewVar("x")), "f x g"},
+		{"S'", apply(SPrime, NewVar("c"), NewVar("f"), NewVar("g"), NewVar("x")), "c (f x) (g x)"},
+		{"B*", apply(BStar, NewVar("c"), NewVar("f"), NewVar("g"), NewVar("x")), "c (f (g x))"},
+		{"C'", apply(CPrime, NewVar("c"), NewVar("f"), NewVar("g"), NewVar("x")), "c (f x) g"},
+		{"partial", apply(S, apply(K, I, NewVar("y"))), "S I"},
+		{"arguments", apply(NewVar("f"), apply(I, NewVar("x")), apply(K, NewVar("y"))), "f x (K y)"},
+		{"lazy", apply(K, NewVar("x"), apply(S, I, I, apply(S, I, I))), "x"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			result := NewReducer().Reduce(context.Background(), testCase.expr)
+			is.True(result.Success(), "%v", result.Error())
+			is.Equal(testCase.expected, result.Value().String())
+		})
+	}
+}
+
+func TestReduceLimits(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	omega := apply(S, I, I, apply(S, I, I))
+	result := NewReducer().WithMaxSteps(100).Reduce(context.Background(), omega)
+	is.True(errors.Is(result.Error(), eval.ErrStepLimit))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result = NewReducer().WithMaxSteps(0).Reduce(ctx, omega)
+	is.True(errors.Is(result.Error(), context.Canceled))
+}
+
+func TestArithmetic(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	// 2^3 * 3, applied to the free variables f and x
+	source := parse(t, `(\m.\n.\f.m (n f)) ((\b.\e.e b) (\f.\x.f (f x)) (\f.\x.f (f (f x)))) (\f.\x.f (f (f x))) f x`)
+	expected := eval.NewNormalOrder().Evaluate(context.Background(), source)
+	is.True(expected.Success(), "%v", expected.Error())
+
+	for _, basis := range []Basis{SKI, Turner} {
+		result := NewReducer().Reduce(context.Background(), Compile(source, basis))
+		is.True(result.Success(), "%v", result.Error())
+		is.True(term.AlphaEquivalent(expected.Value(), ToTerm(result.Value())), "%s", result.Value())
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	composition := parse(t, `\f.\g.\x.f (g x)`)
+	is.Equal(6, TermSize(composition))
+	is.Equal("size 4: S 2, K 2", Measure(Compile(composition, SKI)).String())
+	is.Equal("size 1: B 1", Measure(Compile(composition, Turner)).String())
+	is.Equal("size 2", Measure(apply(NewVar("f"), NewVar("x"))).String())
+	is.Equal(Metrics{Size: 3, Combinators: map[Combinator]int{S: 1, I: 2}}, Measure(Compile(parse(t, `\x.x x`), SKI)))
+}
+
+func TestCompileProperties(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 500
+	properties := gopter.NewProperties(parameters)
+
+	normal := eval.NewNormalOrder().WithMaxSteps(1000)
+	reducer := NewReducer().WithMaxSteps(10000)
+	// Compiling eta-reduces, so terms are compared in beta-eta normal form
+	normalize := func(t term.Term) (term.Term, bool) {
+		result := normal.Evaluate(context.Background(), t)
+		if result.Failure() {
+			return nil, false
+		}
+		return etaReduce(result.Value()), true
+	}
+
+	properties.Property("compiled terms are closed combinator terms equal to the term", prop.ForAll(
+		func(t term.Term) bool {
+			expected, ok := normalize(t)
+			if !ok {
+				return true
+			}
+			for _, basis := range []Basis{SKI, Turner} {
+				compiled := Compile(t, basis)
+				if occursAny(compiled) {
+					return false
+				}
+				actual, ok := normalize(ToTerm(compiled))
+				if !ok || !term.AlphaEquivalent(expected, actual) {
+					return false
+				}
+			}
+			return true
+		},
+		termtest.Closed(5),
+	))
+
+	// Graph reduction agrees with normal order whenever it terminates: weak
+	// reduction may loop on the argument of a partial application that
+	// normal order erases under an abstraction
+	properties.Property("graph reduction agrees with normal order", prop.ForAll(
+		func(t term.Term) bool {
+			expected, ok := normalize(t)
+			if !ok {
+				return true
+			}
+			for _, basis := range []Basis{SKI, Turner} {
+				result := reducer.Reduce(context.Background(), Compile(t, basis))
+				if errors.Is(result.Error(), eval.ErrStepLimit) {
+					continue
+				}
+				actual, ok := normalize(ToTerm(result.Value()))
+				if result.Failure() || !ok || !term.AlphaEquivalent(expected, actual) {
+					return false
+				}
+			}
+			return true
+		},
+		termtest.Closed(5),
+	))
+
+	properties.Property("Turner terms are no larger than SKI terms", prop.ForAll(
+		func(t term.Term) bool {
+			return Measure(Compile(t, Turner)).Size <= Measure(Compile(t, SKI)).Size
+		},
+		termtest.Term(6),
+	))
+
+	properties.TestingRun(t)
+}
+
+// etaReduce contracts the eta redexes of t, \x.f x becoming f when x is not
+// free in f.
+func etaReduce(t term.Term) term.Term {
+	switch t := t.(type) {
+	case term.App:
+		return term.NewApp(etaReduce(t.Fun()), etaReduce(t.Arg()))
+	case term.Abs:
+		body := etaReduce(t.Body())
+		if app, ok := body.(term.App); ok {
+			if v, ok := app.Arg().(term.Var); ok && v.Name() == t.Param() && !term.FreeVars(app.Fun())[t.Param()] {
+				return app.Fun()
+			}
+		}
+		return term.NewAbs(t.Param(), body)
+	}
+	return t
+}
+
+// occursAny tells whether e has variables.
+func occursAny(e Expr) bool {
+	switch e := e.(type) {
+	case Var:
+		return true
+	case App:
+		return occursAny(e.fun) || occursAny(e.arg)
+	}
+	return false
+}
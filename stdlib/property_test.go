@@ -0,0 +1,304 @@
+package stdlib_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+
+	"github.com/denisdubochevalier/monad"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// nat evaluates an expression of std/nat whose %d verbs are replaced by the
+// numerals of the given numbers, and decodes the resulting numeral.
+func nat(expression string, args ...int) (int, bool) {
+	numerals := make([]any, 0, len(args))
+	for _, arg := range args {
+		numerals = append(numerals, "("+eval.Numeral(arg).String()+")")
+	}
+	result := normalForm(fmt.Sprintf(expression, numerals...))
+	if result.Failure() {
+		return 0, false
+	}
+	n := eval.ReadNumeral(result.Value())
+	return n.Value(), n.Just()
+}
+
+// is tells whether an expression of std/nat evaluates to the given numeral.
+func is(expected int, expression string, args ...int) bool {
+	actual, ok := nat(expression, args...)
+	return ok && actual == expected
+}
+
+// holds tells whether a predicate of std/nat, whose verbs are replaced as in
+// nat, evaluates to the given Church boolean.
+func holds(expected bool, expression string, args ...int) bool {
+	n, ok := nat("("+expression+") n->one n->zero", args...)
+	return ok && n <= 1 && (n == 1) == expected
+}
+
+func TestNatProperties(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 50
+	properties := gopter.NewProperties(parameters)
+	small, tiny := gen.IntRange(0, 12), gen.IntRange(0, 4)
+
+	properties.Property("add is addition, and commutative", prop.ForAll(
+		func(m, n int) bool {
+			return is(m+n, "n->add %s %s", m, n) && is(m+n, "n->add %s %s", n, m)
+		},
+		small, small,
+	))
+
+	properties.Property("add is associative", prop.ForAll(
+		func(a, b, c int) bool {
+			sum, ok := nat("n->add (n->add %s %s) %s", a, b, c)
+			return ok && is(sum, "n->add %s (n->add %s %s)", a, b, c)
+		},
+		small, small, small,
+	))
+
+	properties.Property("mul is multiplication, and commutative", prop.ForAll(
+		func(m, n int) bool {
+			return is(m*n, "n->mul %s %s", m, n) && is(m*n, "n->mul %s %s", n, m)
+		},
+		small, small,
+	))
+
+	properties.Property("mul distributes over add", prop.ForAll(
+		func(a, b, c int) bool {
+			return is(a*(b+c), "n->add (n->mul %s %s) (n->mul %s %s)", a, b, a, c)
+		},
+		small, small, small,
+	))
+
+	properties.Property("pow is exponentiation", prop.ForAll(
+		func(m, n int) bool {
+			expected := 1
+			for i := 0; i < n; i++ {
+				expected *= m
+			}
+			return is(expected, "n->pow %s %s", m, n)
+		},
+		tiny, tiny,
+	))
+
+	properties.Property("pred undoes succ, and sub undoes add", prop.ForAll(
+		func(m, n int) bool {
+			return is(m, "n->pred (n->succ %s)", m) && is(m, "n->sub (n->add %s %s) %s", m, n, n)
+		},
+		small, small,
+	))
+
+	properties.Property("sub is truncated subtraction", prop.ForAll(
+		func(m, n int) bool {
+			return is(max(m-n, 0), "n->sub %s %s", m, n)
+		},
+		small, small,
+	))
+
+	properties.Property("comparisons agree with Go", prop.ForAll(
+		func(m, n int) bool {
+			return holds(m <= n, "n->leq %s %s", m, n) &&
+				holds(m < n, "n->lt %s %s", m, n) &&
+				holds(m >= n, "n->geq %s %s", m, n) &&
+				holds(m > n, "n->gt %s %s", m, n) &&
+				holds(m == n, "n->eq %s %s", m, n) &&
+				is(min(m, n), "n->min %s %s", m, n) &&
+				is(max(m, n), "n->max %s %s", m, n)
+		},
+		small, small,
+	))
+
+	properties.Property("div and mod are the quotient and remainder", prop.ForAll(
+		func(m, n int) bool {
+			return is(m/n, "n->div %s %s", m, n) && is(m%n, "n->mod %s %s", m, n)
+		},
+		gen.IntRange(0, 15), gen.IntRange(1, 5),
+	))
+
+	properties.Property("factorial is the product of the first numbers", prop.ForAll(
+		func(n int) bool {
+			expected := 1
+			for i := 2; i <= n; i++ {
+				expected *= i
+			}
+			return is(expected, "n->factorial %s", n)
+		},
+		gen.IntRange(0, 5),
+	))
+
+	properties.TestingRun(t)
+}
+
+// numbers imports the modules of the number tests, which would slow down the
+// other tests if they were part of the prelude.
+const numbers = `p | "std/pair"
+nat | "std/nat"
+int | "std/int"
+rat | "std/rat"
+real | "std/real"
+complex | "std/complex"
+`
+
+// number evaluates an expression whose %s verbs are replaced by the given
+// terms, and decodes the result with read.
+func number[T any](read func(term.Term) monad.Maybe[T], expression string, args ...term.Term) monad.Maybe[T] {
+	terms := make([]any, 0, len(args))
+	for _, arg := range args {
+		terms = append(terms, "("+arg.String()+")")
+	}
+	result := normalFormWith(numbers, fmt.Sprintf(expression, terms...))
+	if result.Failure() {
+		return monad.None[T]()
+	}
+	return read(result.Value())
+}
+
+// decimal returns the std/real real written in decimal.
+func decimal(t *testing.T, s string) term.Term {
+	t.Helper()
+	x := eval.Real(s)
+	require.True(t, x.Success(), "%v", x.Error())
+	return x.Value()
+}
+
+func TestNumbers(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		read     func(term.Term) monad.Maybe[string]
+		expected string
+		expr     string
+		args     []string
+	}{
+		{"real add", eval.ReadReal, "1.25", "real->add %s %s", []string{"1.5", "-0.25"}},
+		{"real sub", eval.ReadReal, "-0.25", "real->sub %s %s", []string{"0.5", "0.75"}},
+		{"real mul", eval.ReadReal, "3.375", "real->mul %s %s", []string{"1.5", "2.25"}},
+		{"real div", eval.ReadReal, "0.33", "real->div %s %s (\\f.\\x.f (f x))", []string{"1", "3"}},
+		{"real div negative", eval.ReadReal, "-2.5", "real->div %s %s nat->one", []string{"-5", "2"}},
+		{"real rescale", eval.ReadReal, "-0.2", "real->rescale %s nat->one", []string{"-0.25"}},
+		{"real widen", eval.ReadReal, "2.50", "real->rescale %s (\\f.\\x.f (f x))", []string{"2.5"}},
+		{"real fromRat", eval.ReadReal, "0.5", "real->fromRat (real->toRat %s) nat->one", []string{"0.5"}},
+		{"complex i squared", eval.ReadComplex, "-1+0i", "complex->mul complex->i complex->i", nil},
+		{"complex add", eval.ReadComplex, "1.5-1i", "complex->add (complex->make %s %s) (complex->make %s %s)", []string{"1", "1", "0.5", "-2"}},
+		{"complex mul", eval.ReadComplex, "5+5i", "complex->mul (complex->make %s %s) (complex->make %s %s)", []string{"1", "2", "3", "-1"}},
+		{"complex conj", eval.ReadComplex, "1-2i", "complex->conj (complex->make %s %s)", []string{"1", "2"}},
+		{"complex norm", eval.ReadReal, "25", "complex->norm (complex->make %s %s)", []string{"3", "-4"}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			args := make([]term.Term, 0, len(testCase.args))
+			for _, arg := range testCase.args {
+				args = append(args, decimal(t, arg))
+			}
+			actual := number(testCase.read, testCase.expr, args...)
+			require.True(t, actual.Just(), "%s does not evaluate to a number", testCase.expr)
+			require.Equal(t, testCase.expected, actual.Value())
+		})
+	}
+}
+
+func TestNumberProperties(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 25
+	properties := gopter.NewProperties(parameters)
+	small := gen.IntRange(-6, 6)
+	nonzero := gen.IntRange(-4, 4).SuchThat(func(n int) bool { return n != 0 })
+	numerator, denominator := gen.IntRange(-3, 3), gen.IntRange(1, 3)
+
+	integer := func(expected int, expression string, args ...int) bool {
+		terms := make([]term.Term, 0, len(args))
+		for _, arg := range args {
+			terms = append(terms, eval.Integer(arg))
+		}
+		actual := number(eval.ReadInteger, expression, terms...)
+		return actual.Just() && actual.Value() == expected
+	}
+
+	properties.Property("int add, sub and mul agree with Go", prop.ForAll(
+		func(i, j int) bool {
+			return integer(i+j, "int->add %s %s", i, j) &&
+				integer(i-j, "int->sub %s %s", i, j) &&
+				integer(i*j, "int->mul %s %s", i, j) &&
+				integer(-i, "int->neg %s", i)
+		},
+		small, small,
+	))
+
+	properties.Property("int div and mod truncate like Go", prop.ForAll(
+		func(i, j int) bool {
+			return integer(i/j, "int->div %s %s", i, j) && integer(i%j, "int->mod %s %s", i, j)
+		},
+		gen.IntRange(-9, 9), nonzero,
+	))
+
+	properties.Property("int comparisons agree with Go", prop.ForAll(
+		func(i, j int) bool {
+			return integer(b2i(i == j), "int->eq %s %s int->one int->zero", i, j) &&
+				integer(b2i(i < j), "int->lt %s %s int->one int->zero", i, j) &&
+				integer(b2i(i <= j), "int->leq %s %s int->one int->zero", i, j)
+		},
+		small, small,
+	))
+
+	properties.Property("normalize keeps the value and zeroes a component", prop.ForAll(
+		func(m, n int) bool {
+			normalized := fmt.Sprintf("(int->normalize (int->make (%s) (%s)))", eval.Numeral(m), eval.Numeral(n))
+			smallest := number(eval.ReadNumeral, "nat->min (p->fst "+normalized+") (p->snd "+normalized+")")
+			return integer(m-n, normalized) && smallest.Just() && smallest.Value() == 0
+		},
+		gen.IntRange(0, 8), gen.IntRange(0, 8),
+	))
+
+	rational := func(expected *big.Rat, expression string, args ...*big.Rat) bool {
+		terms := make([]term.Term, 0, len(args))
+		for _, arg := range args {
+			terms = append(terms, eval.Rational(arg))
+		}
+		actual := number(eval.ReadRational, expression, terms...)
+		return actual.Just() && actual.Value().Cmp(expected) == 0
+	}
+
+	properties.Property("rat arithmetic agrees with big.Rat", prop.ForAll(
+		func(a, b, c, d int) bool {
+			q, r := big.NewRat(int64(a), int64(b)), big.NewRat(int64(c), int64(d))
+			return rational(new(big.Rat).Add(q, r), "rat->add %s %s", q, r) &&
+				rational(new(big.Rat).Sub(q, r), "rat->sub %s %s", q, r) &&
+				rational(new(big.Rat).Mul(q, r), "rat->mul %s %s", q, r) &&
+				(c == 0 || rational(new(big.Rat).Quo(q, r), "rat->div %s %s", q, r))
+		},
+		numerator, denominator, numerator, denominator,
+	))
+
+	properties.Property("reduce gives the lowest terms", prop.ForAll(
+		func(a, b int) bool {
+			reduced := normalFormWith(numbers, fmt.Sprintf("rat->reduce (rat->make (%s) (%s))", eval.Integer(a), eval.Numeral(b)))
+			return reduced.Success() && term.AlphaEquivalent(eval.Rational(big.NewRat(int64(a), int64(b))), reduced.Value())
+		},
+		gen.IntRange(-6, 6), gen.IntRange(1, 6),
+	))
+
+	properties.TestingRun(t)
+}
+
+// b2i returns 1 for true and 0 for false.
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+// Package stdlib embeds the standard library of λ.c, the modules available
+// to every program under the std/ import path:
+//
+//   - std/combinator: the SKI and BCKW combinators, and the Y and Z fixpoint
+//     combinators;
+//   - std/bool: Church booleans and logic;
+//   - std/pair: Church pairs and triples;
+//   - std/list: Church lists, with map, fold and filter;
+//   - std/scott: Scott lists, and conversions from and to Church lists;
+//   - std/nat: Church numerals and their arithmetic;
+//   - std/int: signed integers, as pairs of numerals;
+//   - std/rat: rationals, as pairs of an integer and a numeral;
+//   - std/real: decimal fixed-point reals;
+//   - std/complex: complex numbers, as pairs of reals;
+//   - std/io: input and output actions, performed by lambdac run -io (see
+//     package effect);
+//   - std/net: TCP listeners and connections, as actions of std/io.
+//
+// The library is written in λ.c and compiled into the lambdac binary, so that
+// `std | "std/list"` works out of the box. The module loader looks it up last
+// in the search path, unless LAMBDAC_STDLIB points at another copy of it.
+package stdlib
+
+import "embed"
+
+// FS holds the modules of the standard library, such as std/list.lc.
+//
+//go:embed std
+var FS embed.FS
@@ -0,0 +1,193 @@
+package stdlib_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/denisdubochevalier/monad"
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/eval"
+	"github.com/denisdubochevalier/lambdac/fixpoint"
+	"github.com/denisdubochevalier/lambdac/lint"
+	"github.com/denisdubochevalier/lambdac/module"
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// prelude imports the standard library and defines a couple of lists, on
+// which the expressions of the tests are evaluated.
+const prelude = `c | "std/combinator"
+b | "std/bool"
+p | "std/pair"
+l | "std/list"
+s | "std/scott"
+n | "std/nat"
+ad := l->cons a (l->cons d l->nil)
+sad := s->cons a (s->cons d s->nil)
+bools := l->cons b->true (l->cons b->false l->nil)
+`
+
+// normalForm returns the normal form of an expression using the standard
+// library.
+func normalForm(expression string) monad.Result[term.Term, error] {
+	return normalFormWith(prelude, expression)
+}
+
+// normalFormWith returns the normal form of an expression following the
+// imports and definitions of header.
+func normalFormWith(header, expression string) monad.Result[term.Term, error] {
+	g := module.NewLoader(module.Stdlib()).Load("test.lc", header+expression+"\n")
+	if g.Failure() {
+		return monad.Fail[term.Term, error](g.Error())
+	}
+	program := g.Value().Link()
+	if program.Failure() {
+		return monad.Fail[term.Term, error](program.Error())
+	}
+	entry := program.Value().Entry("main")
+	if entry.Failure() {
+		return entry
+	}
+	return eval.NewNormalOrder().Evaluate(context.Background(), entry.Value())
+}
+
+// evaluate returns the normal form of an expression using the standard
+// library.
+func evaluate(t *testing.T, expression string) term.Term {
+	t.Helper()
+	result := normalForm(expression)
+	require.True(t, result.Success(), "%v", result.Error())
+	return result.Value()
+}
+
+// parse returns the term of an expression.
+func parse(t *testing.T, expression string) term.Term {
+	t.Helper()
+	program := eval.Load(parser.ParseString(expression).Value())
+	require.True(t, program.Success(), "%v", program.Error())
+	return program.Value().Expressions()[0]
+}
+
+func TestStdlib(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		expression string
+		expected   string
+	}{
+		{`c->I a`, `a`},
+		{`c->K a d`, `a`},
+		{`c->S a d e`, `a e (d e)`},
+		{`c->S c->K c->K a`, `a`},
+		{`c->B a d e`, `a (d e)`},
+		{`c->C a d e`, `a e d`},
+		{`c->W a d`, `a d d`},
+		{`c->KI a d`, `d`},
+		{`c->M a`, `a a`},
+		{`c->T a d`, `d a`},
+		{`c->Y (c->K a)`, `a`},
+		{`c->Z (c->K a)`, `a`},
+		{`c->Y (\self.\x.x b->true (self b->true)) b->false`, `\t.\_.t`},
+
+		{`b->true`, `\t.\f.t`},
+		{`b->false`, `\t.\f.f`},
+		{`b->if b->true a d`, `a`},
+		{`b->if b->false a d`, `d`},
+		{`b->not b->true`, `\t.\f.f`},
+		{`b->not b->false`, `\t.\f.t`},
+		{`b->and b->true b->false`, `\t.\f.f`},
+		{`b->and b->true b->true`, `\t.\f.t`},
+		{`b->or b->false b->true`, `\t.\f.t`},
+		{`b->or b->false b->false`, `\t.\f.f`},
+		{`b->xor b->true b->true`, `\t.\f.f`},
+		{`b->xor b->false b->true`, `\t.\f.t`},
+		{`b->implies b->false b->false`, `\t.\f.t`},
+		{`b->implies b->true b->false`, `\t.\f.f`},
+		{`b->eq b->false b->false`, `\t.\f.t`},
+		{`b->eq b->true b->false`, `\t.\f.f`},
+
+		{`p->pair a d`, `\s.s a d`},
+		{`p->fst (p->pair a d)`, `a`},
+		{`p->snd (p->pair a d)`, `d`},
+		{`p->swap (p->pair a d)`, `\s.s d a`},
+		{`p->curry p->snd a d`, `d`},
+		{`p->uncurry c->K (p->pair a d)`, `a`},
+		{`p->first (p->triple a d e)`, `a`},
+		{`p->second (p->triple a d e)`, `d`},
+		{`p->third (p->triple a d e)`, `e`},
+
+		{`ad`, `\c.\n.c a (c d n)`},
+		{`l->nil`, `\c.\n.n`},
+		{`l->isnil l->nil`, `\t.\f.t`},
+		{`l->isnil ad`, `\t.\f.f`},
+		{`l->head e ad`, `a`},
+		{`l->head e l->nil`, `e`},
+		{`l->tail ad`, `\c.\n.c d n`},
+		{`l->tail l->nil`, `\c.\n.n`},
+		{`l->fold f z ad`, `f a (f d z)`},
+		{`l->map f ad`, `\c.\n.c (f a) (c (f d) n)`},
+		{`l->filter c->I bools`, `\c.\n.c (\t.\f.t) n`},
+		{`l->append ad (l->cons e l->nil)`, `\c.\n.c a (c d (c e n))`},
+		{`l->reverse ad`, `\c.\n.c d (c a n)`},
+		{`l->any c->I bools`, `\t.\f.t`},
+		{`l->all c->I bools`, `\t.\f.f`},
+		{`l->all c->I l->nil`, `\t.\f.t`},
+		{`l->reverse "ab"`, `"ba"`},
+		{`l->isnil ""`, `\t.\f.t`},
+
+		{`sad`, `\n.\k.k a (\n.\k.k d (\n.\k.n))`},
+		{`s->isnil s->nil`, `\t.\f.t`},
+		{`s->isnil sad`, `\t.\f.f`},
+		{`s->case sad e (\h.\_.h)`, `a`},
+		{`s->case s->nil e (\h.\_.h)`, `e`},
+		{`s->head e sad`, `a`},
+		{`s->head e s->nil`, `e`},
+		{`s->tail sad`, `\n.\k.k d (\n.\k.n)`},
+		{`s->fold f z sad`, `f a (f d z)`},
+		{`s->map f sad`, `\n.\k.k (f a) (\n.\k.k (f d) (\n.\k.n))`},
+		{`s->filter c->I (s->cons b->false (s->cons b->true s->nil))`, `\n.\k.k (\t.\f.t) (\n.\k.n)`},
+		{`s->append sad (s->cons e s->nil)`, `\n.\k.k a (\n.\k.k d (\n.\k.k e (\n.\k.n)))`},
+		{`s->fromChurch ad`, `\n.\k.k a (\n.\k.k d (\n.\k.n))`},
+		{`s->toChurch sad`, `\c.\n.c a (c d n)`},
+
+		{`n->zero`, `\f.\x.x`},
+		{`n->succ n->one`, `\f.\x.f (f x)`},
+		{`n->add n->one (n->succ n->one)`, `\f.\x.f (f (f x))`},
+		{`n->mul n->zero n->one`, `\f.\x.x`},
+		{`n->pow n->one n->zero`, `\f.f`},
+		{`n->pred n->zero`, `\f.\x.x`},
+		{`n->sub n->one (n->succ n->one)`, `\f.\x.x`},
+		{`n->iszero n->zero`, `\t.\f.t`},
+		{`n->iszero n->one`, `\t.\f.f`},
+		{`n->eq n->one n->one`, `\t.\f.t`},
+		{`n->lt n->one n->one`, `\t.\f.f`},
+		{`n->div n->one n->zero`, `\f.\x.x`},
+		{`n->mod n->one n->zero`, `\f.\x.f x`},
+		{`n->factorial n->zero`, `\f.\x.f x`},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.expression, func(t *testing.T) {
+			t.Parallel()
+			actual := evaluate(t, testCase.expression)
+			expected := parse(t, testCase.expected)
+			require.True(t, term.AlphaEquivalent(expected, actual), "expected %s, got %s", expected, actual)
+		})
+	}
+}
+
+func TestStdlibIsClean(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	g := module.NewLoader().LoadTree(module.Stdlib())
+	is.True(g.Success(), "%v", g.Error())
+	is.Len(g.Value().Modules(), 12)
+	for _, m := range g.Value().Modules() {
+		is.Empty(m.Resolution().Diagnostics(), m.File())
+		is.Empty(lint.New().Run(m.Program(), m.Resolution()), m.File())
+		is.Empty(fixpoint.Detect(m.Program(), m.Resolution()), m.File())
+	}
+}
@@ -0,0 +1,77 @@
+package term
+
+import (
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+)
+
+// FromAST converts an expression node, as produced by the parser, into a
+// Term. Namespace dereferences become variables bearing the qualified name
+// (`alias->name`), and string literals the Church lists of their code points
+// (see Literal). Statement nodes (imports, definitions) are not expressions
+// and are rejected.
+func FromAST(node parser.ASTNode) monad.Result[Term, error] {
+	return fromAST(node, false)
+}
+
+// FromSource converts an expression node into a Term like FromAST, but keeps
+// string literals as they were written, as variables named after them, such
+// as `"hi"`. It serves tools that print programs back, such as the formatter:
+// the resulting terms are not meant to be evaluated.
+func FromSource(node parser.ASTNode) monad.Result[Term, error] {
+	return fromAST(node, true)
+}
+
+// fromAST implements FromAST and FromSource.
+func fromAST(node parser.ASTNode, verbatim bool) monad.Result[Term, error] {
+	children := node.Children()
+	position := node.Token().Position()
+
+	switch node.NodeType() {
+	case lexer.STRING:
+		lit := node.Token().Literal().String()
+		if verbatim {
+			return monad.Succeed[Term, error](Var{name: quote(lit), position: position})
+		}
+		s, err := unquote(lit)
+		if err != nil {
+			return monad.Fail[Term, error](diagnostic.Errorf(position, "invalid escape sequence in string %s", quote(lit)))
+		}
+		return monad.Succeed[Term, error](literal(s, position))
+	case lexer.IDENT:
+		return monad.Succeed[Term, error](Var{name: node.Token().Literal().String(), position: position})
+	case lexer.NSDEREF:
+		return monad.Succeed[Term, error](Var{
+			name:     Qualify(children[0].Token().Literal().String(), children[1].Token().Literal().String()),
+			position: position,
+		})
+	case lexer.LAMBDA:
+		return fromAST(children[1], verbatim).FlatMap(func(body Term) monad.Result[Term, error] {
+			return monad.Succeed[Term, error](Abs{
+				param:    children[0].Token().Literal().String(),
+				body:     body,
+				position: position,
+			})
+		})
+	case lexer.APPLY:
+		fun := fromAST(children[0], verbatim)
+		if fun.Failure() {
+			return fun
+		}
+		return fromAST(children[1], verbatim).FlatMap(func(arg Term) monad.Result[Term, error] {
+			return monad.Succeed[Term, error](App{fun: fun.Value(), arg: arg, position: position})
+		})
+	default:
+		return monad.Fail[Term, error](
+			diagnostic.Errorf(position, "%s is not an expression", node.NodeType()),
+		)
+	}
+}
+
+// Qualify builds the name under which the variable `alias->name` is known.
+func Qualify(alias, name string) string {
+	return alias + lexer.NSDEREF.String() + name
+}
@@ -0,0 +1,44 @@
+package term
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+// Literal returns the term a string literal stands for: the Church list of
+// the Unicode code points of s, each a Church numeral, as built by std/list.
+// "hi" is \c.\n.c 104 (c 105 n), where 104 and 105 are the numerals
+// \f.\x.f (... (f x)).
+func Literal(s string) Term {
+	return literal(s, lexer.StartPosition())
+}
+
+// literal builds the term of Literal, every node of it positioned at p.
+func literal(s string, p lexer.Position) Term {
+	runes := []rune(s)
+	var list Term = Var{name: "n", position: p}
+	for i := len(runes) - 1; i >= 0; i-- {
+		var numeral Term = Var{name: "x", position: p}
+		for j := rune(0); j < runes[i]; j++ {
+			numeral = App{fun: Var{name: "f", position: p}, arg: numeral, position: p}
+		}
+		numeral = Abs{param: "f", body: Abs{param: "x", body: numeral, position: p}, position: p}
+		head := App{fun: Var{name: "c", position: p}, arg: numeral, position: p}
+		list = App{fun: head, arg: list, position: p}
+	}
+	return Abs{param: "c", body: Abs{param: "n", body: list, position: p}, position: p}
+}
+
+// unquote decodes the escape sequences of a string literal, as lexed: the
+// lexer already turned \" into a quote, and left the other sequences, such as
+// \n or \u00e9, as they were written. The sequences are those of Go.
+func unquote(lit string) (string, error) {
+	return strconv.Unquote(quote(lit))
+}
+
+// quote renders a string literal, as lexed, back in λ.c syntax.
+func quote(lit string) string {
+	return `"` + strings.ReplaceAll(lit, `"`, `\"`) + `"`
+}
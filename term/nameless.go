@@ -0,0 +1,284 @@
+package term
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Nameless is a lambda term in de Bruijn notation: bound variables are
+// replaced by the number of abstractions separating them from their binder,
+// which makes alpha-equivalent terms syntactically equal. Free variables keep
+// their names. A Nameless is an Index, a Free, a Lam or an Ap.
+type Nameless interface {
+	// String renders the term with numeric indices, e.g. \.\.1 for \x.\y.x.
+	String() string
+
+	isNameless()
+}
+
+// Index is a bound variable, designated by the number of abstractions between
+// the occurrence and its binder: 0 is the innermost enclosing abstraction.
+type Index struct {
+	index int
+}
+
+// NewIndex builds a bound variable occurrence.
+func NewIndex(i int) Index {
+	return Index{index: i}
+}
+
+// Index returns the de Bruijn index of the variable.
+func (i Index) Index() int {
+	return i.index
+}
+
+// String renders the index.
+func (i Index) String() string {
+	return strconv.Itoa(i.index)
+}
+
+func (Index) isNameless() {}
+
+// Free is a free variable, designated by its name.
+type Free struct {
+	name string
+}
+
+// NewFree builds a free variable occurrence.
+func NewFree(name string) Free {
+	return Free{name: name}
+}
+
+// Name returns the name of the free variable.
+func (f Free) Name() string {
+	return f.name
+}
+
+// String renders the name of the variable.
+func (f Free) String() string {
+	return f.name
+}
+
+func (Free) isNameless() {}
+
+// Lam is an abstraction. Its binder has no name.
+type Lam struct {
+	body Nameless
+}
+
+// NewLam builds an abstraction.
+func NewLam(body Nameless) Lam {
+	return Lam{body: body}
+}
+
+// Body returns the body of the abstraction.
+func (l Lam) Body() Nameless {
+	return l.body
+}
+
+// String renders the abstraction as \.body.
+func (l Lam) String() string {
+	var b strings.Builder
+	writeNameless(&b, l)
+	return b.String()
+}
+
+func (Lam) isNameless() {}
+
+// Ap is an application.
+type Ap struct {
+	fun Nameless
+	arg Nameless
+}
+
+// NewAp builds an application.
+func NewAp(fun, arg Nameless) Ap {
+	return Ap{fun: fun, arg: arg}
+}
+
+// Fun returns the applied term.
+func (a Ap) Fun() Nameless {
+	return a.fun
+}
+
+// Arg returns the argument.
+func (a Ap) Arg() Nameless {
+	return a.arg
+}
+
+// String renders the application.
+func (a Ap) String() string {
+	var b strings.Builder
+	writeNameless(&b, a)
+	return b.String()
+}
+
+func (Ap) isNameless() {}
+
+// writeNameless renders a nameless term with the same parenthesization rules
+// as write.
+func writeNameless(b *strings.Builder, n Nameless) {
+	switch n := n.(type) {
+	case Index, Free:
+		b.WriteString(n.String())
+	case Lam:
+		b.WriteString(`\.`)
+		writeNameless(b, n.body)
+	case Ap:
+		if _, ok := n.fun.(Lam); ok {
+			b.WriteString("(")
+			writeNameless(b, n.fun)
+			b.WriteString(")")
+		} else {
+			writeNameless(b, n.fun)
+		}
+		b.WriteString(" ")
+		switch n.arg.(type) {
+		case Index, Free:
+			writeNameless(b, n.arg)
+		default:
+			b.WriteString("(")
+			writeNameless(b, n.arg)
+			b.WriteString(")")
+		}
+	}
+}
+
+// ToNameless converts a term to de Bruijn notation. Variables that are not
+// bound within the term become Free variables.
+func ToNameless(t Term) Nameless {
+	return toNameless(t, nil)
+}
+
+// toNameless converts t given the names of the enclosing binders, innermost
+// last.
+func toNameless(t Term, binders []string) Nameless {
+	switch t := t.(type) {
+	case Var:
+		for i := len(binders) - 1; i >= 0; i-- {
+			if binders[i] == t.name {
+				return Index{index: len(binders) - 1 - i}
+			}
+		}
+		return Free{name: t.name}
+	case Abs:
+		return Lam{body: toNameless(t.body, append(binders[:len(binders):len(binders)], t.param))}
+	case App:
+		return Ap{fun: toNameless(t.fun, binders), arg: toNameless(t.arg, binders)}
+	}
+	return nil
+}
+
+// FromNameless converts a term in de Bruijn notation back to a named term.
+// Binders are named x, x1, x2, ... avoiding the free variables of the term, so
+// that the result never captures them. Indices that do not refer to an
+// enclosing abstraction are rendered as free variables named after the index.
+func FromNameless(n Nameless) Term {
+	avoid := map[string]bool{}
+	collectFreeNames(n, avoid)
+	return fromNameless(n, nil, avoid)
+}
+
+// fromNameless converts n given the names chosen for the enclosing binders,
+// innermost last.
+func fromNameless(n Nameless, binders []string, avoid map[string]bool) Term {
+	switch n := n.(type) {
+	case Index:
+		if n.index < len(binders) {
+			return NewVar(binders[len(binders)-1-n.index])
+		}
+		return NewVar("#" + strconv.Itoa(n.index-len(binders)))
+	case Free:
+		return NewVar(n.name)
+	case Lam:
+		taken := map[string]bool{}
+		for name := range avoid {
+			taken[name] = true
+		}
+		for _, name := range binders {
+			taken[name] = true
+		}
+		param := Fresh("x", taken)
+		return NewAbs(param, fromNameless(n.body, append(binders[:len(binders):len(binders)], param), avoid))
+	case Ap:
+		return NewApp(fromNameless(n.fun, binders, avoid), fromNameless(n.arg, binders, avoid))
+	}
+	return nil
+}
+
+// collectFreeNames gathers the names of the Free variables of n.
+func collectFreeNames(n Nameless, names map[string]bool) {
+	switch n := n.(type) {
+	case Free:
+		names[n.name] = true
+	case Lam:
+		collectFreeNames(n.body, names)
+	case Ap:
+		collectFreeNames(n.fun, names)
+		collectFreeNames(n.arg, names)
+	}
+}
+
+// Shift adds d to the indices of n that are greater or equal to cutoff, i.e.
+// that refer to binders outside of n.
+func Shift(n Nameless, d, cutoff int) Nameless {
+	switch n := n.(type) {
+	case Index:
+		if n.index >= cutoff {
+			return Index{index: n.index + d}
+		}
+		return n
+	case Lam:
+		return Lam{body: Shift(n.body, d, cutoff+1)}
+	case Ap:
+		return Ap{fun: Shift(n.fun, d, cutoff), arg: Shift(n.arg, d, cutoff)}
+	}
+	return n
+}
+
+// SubstIndex replaces the occurrences of index j in n by s, adjusting the
+// indices of s as it goes under abstractions.
+func SubstIndex(n Nameless, j int, s Nameless) Nameless {
+	switch n := n.(type) {
+	case Index:
+		if n.index == j {
+			return s
+		}
+		return n
+	case Lam:
+		return Lam{body: SubstIndex(n.body, j+1, Shift(s, 1, 0))}
+	case Ap:
+		return Ap{fun: SubstIndex(n.fun, j, s), arg: SubstIndex(n.arg, j, s)}
+	}
+	return n
+}
+
+// Beta contracts the redex (\.body) arg in de Bruijn notation.
+func Beta(body, arg Nameless) Nameless {
+	return Shift(SubstIndex(body, 0, Shift(arg, 1, 0)), -1, 0)
+}
+
+// NamelessEqual tells whether two nameless terms are syntactically equal.
+func NamelessEqual(a, b Nameless) bool {
+	switch a := a.(type) {
+	case Index:
+		b, ok := b.(Index)
+		return ok && a.index == b.index
+	case Free:
+		b, ok := b.(Free)
+		return ok && a.name == b.name
+	case Lam:
+		b, ok := b.(Lam)
+		return ok && NamelessEqual(a.body, b.body)
+	case Ap:
+		b, ok := b.(Ap)
+		return ok && NamelessEqual(a.fun, b.fun) && NamelessEqual(a.arg, b.arg)
+	}
+	return false
+}
+
+// AlphaEquivalent tells whether two terms are equal up to the renaming of
+// their bound variables.
+func AlphaEquivalent(a, b Term) bool {
+	return NamelessEqual(ToNameless(a), ToNameless(b))
+}
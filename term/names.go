@@ -0,0 +1,84 @@
+package term
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FreeVars returns the set of the names occurring free in a term, i.e. not
+// bound by an enclosing abstraction.
+func FreeVars(t Term) map[string]bool {
+	free := map[string]bool{}
+	collectFree(t, map[string]int{}, free)
+	return free
+}
+
+// collectFree accumulates the free names of t, given the multiset of the
+// names bound by the enclosing abstractions.
+func collectFree(t Term, bound map[string]int, free map[string]bool) {
+	switch t := t.(type) {
+	case Var:
+		if bound[t.name] == 0 {
+			free[t.name] = true
+		}
+	case Abs:
+		bound[t.param]++
+		collectFree(t.body, bound, free)
+		bound[t.param]--
+	case App:
+		collectFree(t.fun, bound, free)
+		collectFree(t.arg, bound, free)
+	}
+}
+
+// BoundVars returns the set of the names bound by the abstractions of a term,
+// whether they are used or not.
+func BoundVars(t Term) map[string]bool {
+	bound := map[string]bool{}
+	var walk func(Term)
+	walk = func(t Term) {
+		switch t := t.(type) {
+		case Abs:
+			bound[t.param] = true
+			walk(t.body)
+		case App:
+			walk(t.fun)
+			walk(t.arg)
+		}
+	}
+	walk(t)
+	return bound
+}
+
+// IsFree tells whether name occurs free in t. It is cheaper than FreeVars
+// when a single name is of interest.
+func IsFree(name string, t Term) bool {
+	switch t := t.(type) {
+	case Var:
+		return t.name == name
+	case Abs:
+		return t.param != name && IsFree(name, t.body)
+	case App:
+		return IsFree(name, t.fun) || IsFree(name, t.arg)
+	}
+	return false
+}
+
+// Fresh returns a name that does not belong to avoid, derived from base by
+// appending a numeric suffix. Any numeric suffix already present on base is
+// replaced, so that renaming x1 yields x2 rather than x11.
+func Fresh(base string, avoid map[string]bool) string {
+	stem := strings.TrimRightFunc(base, unicode.IsDigit)
+	if stem == "" {
+		stem = base
+	}
+	if !avoid[base] {
+		return base
+	}
+	for i := 1; ; i++ {
+		if name := stem + strconv.Itoa(i); !avoid[name] {
+			return name
+		}
+	}
+}
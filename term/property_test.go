@@ -0,0 +1,130 @@
+package term_test
+
+import (
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/term"
+	"github.com/denisdubochevalier/lambdac/term/termtest"
+)
+
+// replaceFree is the reference implementation of substitution: in de Bruijn
+// notation, free variables are names and bound variables are indices, so a
+// free variable can be replaced by a nameless term without any risk of
+// capture. The replacement being converted from a top-level term, it has no
+// dangling index and needs no shifting.
+func replaceFree(n term.Nameless, name string, s term.Nameless) term.Nameless {
+	switch n := n.(type) {
+	case term.Free:
+		if n.Name() == name {
+			return s
+		}
+		return n
+	case term.Lam:
+		return term.NewLam(replaceFree(n.Body(), name, s))
+	case term.Ap:
+		return term.NewAp(replaceFree(n.Fun(), name, s), replaceFree(n.Arg(), name, s))
+	}
+	return n
+}
+
+func TestSubstProperties(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 1000
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("Subst agrees with the de Bruijn reference", prop.ForAll(
+		func(t, s term.Term, name string) bool {
+			expected := replaceFree(term.ToNameless(t), name, term.ToNameless(s))
+			return term.NamelessEqual(expected, term.ToNameless(term.Subst(t, name, s)))
+		},
+		termtest.Term(5), termtest.Term(3), gen.OneConstOf("x", "y", "z", "w"),
+	))
+
+	properties.Property("Subst implements beta-reduction like de Bruijn indices", prop.ForAll(
+		func(abs term.Term, arg term.Term) bool {
+			a := abs.(term.Abs)
+			lam := term.ToNameless(a).(term.Lam)
+			expected := term.Beta(lam.Body(), term.ToNameless(arg))
+			return term.NamelessEqual(expected, term.ToNameless(term.Subst(a.Body(), a.Param(), arg)))
+		},
+		termtest.Abs(5), termtest.Term(3),
+	))
+
+	properties.Property("Subst computes the expected free variables", prop.ForAll(
+		func(t, s term.Term, name string) bool {
+			expected := term.FreeVars(t)
+			if expected[name] {
+				delete(expected, name)
+				for n := range term.FreeVars(s) {
+					expected[n] = true
+				}
+			}
+			actual := term.FreeVars(term.Subst(t, name, s))
+			if len(actual) != len(expected) {
+				return false
+			}
+			for n := range expected {
+				if !actual[n] {
+					return false
+				}
+			}
+			return true
+		},
+		termtest.Term(5), termtest.Term(3), gen.OneConstOf("x", "y", "z", "w"),
+	))
+
+	properties.Property("substituting a variable for itself is the identity", prop.ForAll(
+		func(t term.Term, name string) bool {
+			return term.AlphaEquivalent(t, term.Subst(t, name, term.NewVar(name)))
+		},
+		termtest.Term(5), gen.OneConstOf("x", "y", "z", "w"),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestRenameProperties(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("Rename to a fresh name preserves alpha-equivalence", prop.ForAll(
+		func(abs term.Term, name string) bool {
+			a := abs.(term.Abs)
+			fresh := term.Fresh(name, term.FreeVars(a.Body()))
+			return term.AlphaEquivalent(a, term.Rename(a, fresh))
+		},
+		termtest.Abs(5), gen.OneConstOf("x", "y", "z", "w"),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestNamelessProperties(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("FromNameless is the inverse of ToNameless", prop.ForAll(
+		func(t term.Term) bool {
+			return term.AlphaEquivalent(t, term.FromNameless(term.ToNameless(t)))
+		},
+		termtest.Term(6),
+	))
+
+	properties.Property("String output parses back to the same term", prop.ForAll(
+		func(t term.Term) bool {
+			program := parser.ParseString(t.String())
+			if program.Failure() {
+				return false
+			}
+			parsed := term.FromAST(program.Value().Children()[0])
+			return parsed.Success() && parsed.Value().String() == t.String() &&
+				term.NamelessEqual(term.ToNameless(t), term.ToNameless(parsed.Value()))
+		},
+		termtest.Term(6),
+	))
+
+	properties.TestingRun(t)
+}
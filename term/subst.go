@@ -0,0 +1,54 @@
+package term
+
+// Subst performs the capture-avoiding substitution t[name := replacement]:
+// every free occurrence of name in t is replaced by replacement. When an
+// abstraction of t binds a name occurring free in replacement, its binder is
+// renamed to a Fresh name first, so that the meaning of replacement is
+// preserved.
+func Subst(t Term, name string, replacement Term) Term {
+	return subst(t, name, replacement, FreeVars(replacement))
+}
+
+// subst implements Subst, given the free names of the replacement, computed
+// once and for all.
+func subst(t Term, name string, replacement Term, free map[string]bool) Term {
+	switch t := t.(type) {
+	case Var:
+		if t.name == name {
+			return replacement
+		}
+		return t
+	case App:
+		t.fun = subst(t.fun, name, replacement, free)
+		t.arg = subst(t.arg, name, replacement, free)
+		return t
+	case Abs:
+		if t.param == name {
+			return t
+		}
+		if free[t.param] && IsFree(name, t.body) {
+			avoid := FreeVars(t.body)
+			for n := range free {
+				avoid[n] = true
+			}
+			avoid[name] = true
+			t = Rename(t, Fresh(t.param, avoid))
+		}
+		t.body = subst(t.body, name, replacement, free)
+		return t
+	}
+	return t
+}
+
+// Rename alpha-renames the binder of an abstraction: \x.M becomes
+// \name.M[x := name]. The caller is responsible for picking a name that does
+// not occur free in the body, otherwise the meaning of the term changes; Fresh
+// is meant for that.
+func Rename(a Abs, name string) Abs {
+	if a.param == name {
+		return a
+	}
+	a.body = Subst(a.body, a.param, Var{name: name, position: a.position})
+	a.param = name
+	return a
+}
@@ -0,0 +1,218 @@
+// Package term provides the core representation of λ.c programs and the
+// classic operations of the lambda calculus over it.
+//
+// Overview:
+//
+// The parser produces a concrete syntax tree that mirrors the source text,
+// statements and namespaces included. The later stages of the compiler
+// (evaluation, compilation) reason about plain lambda terms instead, which
+// this package represents with three immutable types implementing Term:
+//
+//   - Var:  a variable occurrence, x.
+//   - Abs:  an abstraction, \x.M.
+//   - App:  an application, M N.
+//
+// Each term remembers the lexer.Position of the source construct it stems
+// from, which lets later stages relate what they do back to the source text.
+//
+// Operations:
+//
+//   - FreeVars and BoundVars compute the sets of free and bound names.
+//   - Subst performs capture-avoiding substitution, renaming binders with
+//     Fresh names whenever a free variable of the substituted term would
+//     otherwise be captured.
+//   - Rename alpha-renames the binder of an abstraction.
+//   - ToNameless converts a term to its de Bruijn representation, in which
+//     alpha-equivalent terms are equal. AlphaEquivalent builds upon it.
+//
+// Qualified names such as `io->read` are represented by variables bearing the
+// qualified name, and behave as free variables until the module they belong to
+// is linked in. String literals have no term of their own: they stand for the
+// Church lists of their code points, built by Literal.
+package term
+
+import (
+	"strings"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+)
+
+// Term is a lambda term: a Var, an Abs or an App. The interface is sealed;
+// consumers are expected to inspect terms with type switches.
+type Term interface {
+	// Position returns the location of the source construct the term stems
+	// from.
+	Position() lexer.Position
+	// String renders the term in λ.c syntax, with as few parentheses as
+	// possible.
+	String() string
+
+	isTerm()
+}
+
+// Var is a variable occurrence.
+type Var struct {
+	name     string
+	position lexer.Position
+}
+
+// NewVar builds a variable occurrence positioned at the start of the source.
+func NewVar(name string) Var {
+	return Var{name: name, position: lexer.StartPosition()}
+}
+
+// WithPosition returns a copy of the variable positioned at p.
+func (v Var) WithPosition(p lexer.Position) Var {
+	v.position = p
+	return v
+}
+
+// Name returns the name of the variable.
+func (v Var) Name() string {
+	return v.name
+}
+
+// Position returns the location of the variable in the source text.
+func (v Var) Position() lexer.Position {
+	return v.position
+}
+
+// String returns the name of the variable.
+func (v Var) String() string {
+	return v.name
+}
+
+func (Var) isTerm() {}
+
+// Abs is a lambda abstraction \param.body.
+type Abs struct {
+	param    string
+	body     Term
+	position lexer.Position
+}
+
+// NewAbs builds an abstraction positioned at the start of the source.
+func NewAbs(param string, body Term) Abs {
+	return Abs{param: param, body: body, position: lexer.StartPosition()}
+}
+
+// WithPosition returns a copy of the abstraction positioned at p.
+func (a Abs) WithPosition(p lexer.Position) Abs {
+	a.position = p
+	return a
+}
+
+// Param returns the name bound by the abstraction.
+func (a Abs) Param() string {
+	return a.param
+}
+
+// Body returns the body of the abstraction.
+func (a Abs) Body() Term {
+	return a.body
+}
+
+// Position returns the location of the abstraction in the source text.
+func (a Abs) Position() lexer.Position {
+	return a.position
+}
+
+// String renders the abstraction as \param.body.
+func (a Abs) String() string {
+	var b strings.Builder
+	write(&b, a)
+	return b.String()
+}
+
+func (Abs) isTerm() {}
+
+// App is the application of a function to an argument.
+type App struct {
+	fun      Term
+	arg      Term
+	position lexer.Position
+}
+
+// NewApp builds an application positioned like its function.
+func NewApp(fun, arg Term) App {
+	return App{fun: fun, arg: arg, position: fun.Position()}
+}
+
+// WithPosition returns a copy of the application positioned at p.
+func (a App) WithPosition(p lexer.Position) App {
+	a.position = p
+	return a
+}
+
+// Fun returns the applied term.
+func (a App) Fun() Term {
+	return a.fun
+}
+
+// Arg returns the argument.
+func (a App) Arg() Term {
+	return a.arg
+}
+
+// Position returns the location of the application in the source text.
+func (a App) Position() lexer.Position {
+	return a.position
+}
+
+// String renders the application, parenthesizing the operands as needed.
+func (a App) String() string {
+	var b strings.Builder
+	write(&b, a)
+	return b.String()
+}
+
+func (App) isTerm() {}
+
+// Apply builds the left-nested application of fun to the given arguments.
+func Apply(fun Term, args ...Term) Term {
+	for _, arg := range args {
+		fun = NewApp(fun, arg)
+	}
+	return fun
+}
+
+// Lambda builds nested abstractions binding params, from left to right, in
+// body.
+func Lambda(params []string, body Term) Term {
+	for i := len(params) - 1; i >= 0; i-- {
+		body = NewAbs(params[i], body)
+	}
+	return body
+}
+
+// write renders a term into a builder. Abstractions extend as far right as
+// possible and applications associate to the left, so that only functions
+// that are abstractions and arguments that are not variables need
+// parentheses.
+func write(b *strings.Builder, t Term) {
+	switch t := t.(type) {
+	case Var:
+		b.WriteString(t.name)
+	case Abs:
+		b.WriteString(`\`)
+		b.WriteString(t.param)
+		b.WriteString(".")
+		write(b, t.body)
+	case App:
+		if _, ok := t.fun.(Abs); ok {
+			b.WriteString("(")
+			write(b, t.fun)
+			b.WriteString(")")
+		} else {
+			write(b, t.fun)
+		}
+		b.WriteString(" ")
+		if _, ok := t.arg.(Var); ok {
+			write(b, t.arg)
+		} else {
+			b.WriteString("(")
+			write(b, t.arg)
+			b.WriteString(")")
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package term
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/lexer"
+	"github.com/denisdubochevalier/lambdac/parser"
+)
+
+// parse converts a single λ.c expression into a Term.
+func parse(t *testing.T, source string) Term {
+	t.Helper()
+	program := parser.ParseString(source)
+	require.True(t, program.Success(), "%v", program.Error())
+	result := FromAST(program.Value().Children()[0])
+	require.True(t, result.Success(), "%v", result.Error())
+	return result.Value()
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{`x`, `x`},
+		{`\x.x`, `\x.x`},
+		{`f x y`, `f x y`},
+		{`f (x y)`, `f (x y)`},
+		{`(\x.x) y`, `(\x.x) y`},
+		{`f \x.x`, `f (\x.x)`},
+		{`\f.(\x.f(x x))(\x.f(x x))`, `\f.(\x.f (x x)) (\x.f (x x))`},
+		{`io->read x`, `io->read x`},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.input, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			is.Equal(testCase.expected, parse(t, testCase.input).String())
+		})
+	}
+}
+
+func TestFromAST(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	term := parse(t, `a (\x.x)`)
+	app, ok := term.(App)
+	is.True(ok)
+	is.Equal(lexer.NewPosition(1, 0), app.Position())
+	abs, ok := app.Arg().(Abs)
+	is.True(ok)
+	is.Equal(lexer.NewPosition(1, 3), abs.Position())
+	is.Equal("x", abs.Param())
+	is.Equal(lexer.NewPosition(1, 6), abs.Body().Position())
+
+	program := parser.ParseString(`i := \x.x`).Value()
+	is.True(FromAST(program.Children()[0]).Failure())
+}
+
+func TestLiteral(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.Equal(`\c.\n.n`, Literal("").String())
+	is.Equal(`\c.\n.c (\f.\x.f (f x)) (c (\f.\x.f x) n)`, Literal("\x02\x01").String())
+	is.True(AlphaEquivalent(Literal("a\"\n\u00e9"), parse(t, `"a\"\n\u00e9"`)))
+	is.True(AlphaEquivalent(Literal("\\q"), parse(t, `"\\q"`)))
+	is.Equal(lexer.NewPosition(1, 2), parse(t, `f "ab"`).(App).Arg().(Abs).Body().Position())
+
+	invalid := parser.ParseString(`"\q"`).Value()
+	is.ErrorContains(FromAST(invalid.Children()[0]).Error(), "invalid escape sequence")
+
+	verbatim := FromSource(parser.ParseString(`f "a\"\n" x`).Value().Children()[0])
+	is.True(verbatim.Success(), "%v", verbatim.Error())
+	is.Equal(`f "a\"\n" x`, verbatim.Value().String())
+}
+
+func TestFreeAndBoundVars(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	term := parse(t, `\x.x y (\y.y z) (\w.x)`)
+	is.Equal(map[string]bool{"y": true, "z": true}, FreeVars(term))
+	is.Equal(map[string]bool{"x": true, "y": true, "w": true}, BoundVars(term))
+	is.True(IsFree("y", term))
+	is.False(IsFree("x", term))
+}
+
+func TestFresh(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.Equal("x", Fresh("x", map[string]bool{"y": true}))
+	is.Equal("x1", Fresh("x", map[string]bool{"x": true}))
+	is.Equal("x2", Fresh("x1", map[string]bool{"x": true, "x1": true}))
+	is.Equal("42", Fresh("42", map[string]bool{}))
+	is.Equal("421", Fresh("42", map[string]bool{"42": true}))
+}
+
+func TestSubst(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		term        string
+		variable    string
+		replacement string
+		expected    string
+	}{
+		{"variable", `x`, "x", `\y.y`, `\y.y`},
+		{"other variable", `y`, "x", `z`, `y`},
+		{"application", `x (y x)`, "x", `z`, `z (y z)`},
+		{"shadowed", `\x.x`, "x", `z`, `\x.x`},
+		{"under binder", `\y.x y`, "x", `z`, `\y.z y`},
+		{"capture avoided", `\y.x y`, "x", `y`, `\y1.y y1`},
+		{"capture avoided twice", `\y.\y1.x y y1`, "x", `y y1`, `\y2.\y3.y y1 y2 y3`},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			result := Subst(parse(t, testCase.term), testCase.variable, parse(t, testCase.replacement))
+			is.Equal(testCase.expected, result.String())
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	abs := parse(t, `\x.\y.x y`).(Abs)
+	is.Equal(`\z.\y.z y`, Rename(abs, "z").String())
+	is.Equal(`\y1.\y.y1 y`, Rename(abs, "y1").String())
+	is.Equal(abs, Rename(abs, "x"))
+}
+
+func TestNameless(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.Equal(`\.\.1 0 z`, ToNameless(parse(t, `\x.\y.x y z`)).String())
+	is.Equal(`\.(\.0) 0`, ToNameless(parse(t, `\x.(\y.y) x`)).String())
+	is.Equal(`\x.\x1.x x1 z`, FromNameless(ToNameless(parse(t, `\a.\b.a b z`))).String())
+	is.Equal(`\x1.x1 x`, FromNameless(ToNameless(parse(t, `\a.a x`))).String())
+	is.True(AlphaEquivalent(parse(t, `\x.\y.x`), parse(t, `\a.\b.a`)))
+	is.False(AlphaEquivalent(parse(t, `\x.\y.x`), parse(t, `\a.\b.b`)))
+	is.Equal(`\.0 z`, Beta(NewLam(NewAp(NewIndex(0), NewIndex(1))), NewFree("z")).String())
+}
@@ -0,0 +1,80 @@
+// Package termtest provides gopter generators of lambda terms, shared by the
+// property-based tests of the packages manipulating terms.
+package termtest
+
+import (
+	"github.com/leanovate/gopter"
+
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Names is the default pool of variable names used by the generators. It is
+// deliberately small, so that generated terms are likely to exhibit
+// shadowing, captures and shared free variables.
+var Names = []string{"x", "y", "z", "w"}
+
+// Term returns a generator of arbitrary terms of at most the given depth,
+// whose variables are drawn from names.
+func Term(depth int, names ...string) gopter.Gen {
+	if len(names) == 0 {
+		names = Names
+	}
+	return func(params *gopter.GenParameters) *gopter.GenResult {
+		return gopter.NewGenResult(randomTerm(params, depth, names, nil, false), gopter.NoShrinker)
+	}
+}
+
+// Closed returns a generator of closed abstractions of at most the given
+// depth: every variable refers to an enclosing abstraction.
+func Closed(depth int, names ...string) gopter.Gen {
+	if len(names) == 0 {
+		names = Names
+	}
+	return func(params *gopter.GenParameters) *gopter.GenResult {
+		return gopter.NewGenResult(term.Term(randomAbs(params, depth, names, nil, true)), gopter.NoShrinker)
+	}
+}
+
+// Abs returns a generator of abstractions of at most the given depth.
+func Abs(depth int, names ...string) gopter.Gen {
+	if len(names) == 0 {
+		names = Names
+	}
+	return func(params *gopter.GenParameters) *gopter.GenResult {
+		return gopter.NewGenResult(randomAbs(params, depth, names, nil, false), gopter.NoShrinker)
+	}
+}
+
+// randomAbs builds a random abstraction whose body may refer to the given
+// binders.
+func randomAbs(params *gopter.GenParameters, depth int, names, binders []string, closed bool) term.Abs {
+	param := names[params.Rng.Intn(len(names))]
+	body := randomTerm(params, depth-1, names, append(binders[:len(binders):len(binders)], param), closed)
+	return term.NewAbs(param, body)
+}
+
+// randomTerm builds a random term. When closed is set, variables are only
+// drawn among the enclosing binders.
+func randomTerm(params *gopter.GenParameters, depth int, names, binders []string, closed bool) term.Term {
+	variable := func() term.Term {
+		if closed {
+			return term.NewVar(binders[params.Rng.Intn(len(binders))])
+		}
+		return term.NewVar(names[params.Rng.Intn(len(names))])
+	}
+	if depth <= 0 {
+		return variable()
+	}
+
+	switch params.Rng.Intn(3) {
+	case 0:
+		return variable()
+	case 1:
+		return randomAbs(params, depth, names, binders, closed)
+	default:
+		return term.NewApp(
+			randomTerm(params, depth-1, names, binders, closed),
+			randomTerm(params, depth-1, names, binders, closed),
+		)
+	}
+}
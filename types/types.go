@@ -0,0 +1,250 @@
+// Package types infers simple types for λ.c terms.
+//
+// λ.c is untyped, and most interesting programs, starting with the fixpoint
+// combinators, have no simple type. Many terms do, though, and their principal
+// type, the most general one, is a concise summary of how they may be used:
+// the Church numerals all have type (a -> a) -> a -> a, and K has type
+// a -> b -> a. Infer computes it with Hindley's algorithm, a syntax-directed
+// generation of equations between types solved by unification as they go.
+package types
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/denisdubochevalier/monad"
+
+	"github.com/denisdubochevalier/lambdac/diagnostic"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+// Type is a simple type: a Var or an Arrow.
+type Type interface {
+	// String renders the type, arrows associating to the right.
+	String() string
+
+	isType()
+}
+
+// Var is a type variable.
+type Var struct {
+	name string
+}
+
+// NewVar builds a type variable.
+func NewVar(name string) Var {
+	return Var{name: name}
+}
+
+// Name returns the name of the type variable.
+func (v Var) Name() string {
+	return v.name
+}
+
+// String renders the type variable.
+func (v Var) String() string {
+	return v.name
+}
+
+func (Var) isType() {}
+
+// Arrow is the type of functions from a type to another.
+type Arrow struct {
+	from, to Type
+}
+
+// NewArrow builds the type of functions from from to to.
+func NewArrow(from, to Type) Arrow {
+	return Arrow{from: from, to: to}
+}
+
+// From returns the type of the argument.
+func (a Arrow) From() Type {
+	return a.from
+}
+
+// To returns the type of the result.
+func (a Arrow) To() Type {
+	return a.to
+}
+
+// String renders the arrow, parenthesizing its argument type if it is an
+// arrow itself.
+func (a Arrow) String() string {
+	var b strings.Builder
+	if _, ok := a.from.(Arrow); ok {
+		b.WriteString("(" + a.from.String() + ")")
+	} else {
+		b.WriteString(a.from.String())
+	}
+	b.WriteString(" -> ")
+	b.WriteString(a.to.String())
+	return b.String()
+}
+
+func (Arrow) isType() {}
+
+// Infer returns the principal type of t, its type variables being named a, b,
+// c... in order of appearance. The free variables of t are assumed to have
+// whichever type their uses require. Terms that are not simply typable, such
+// as self-applications, are reported by a diagnostic positioned on the
+// application whose function and argument types cannot agree.
+func Infer(t term.Term) monad.Result[Type, error] {
+	in := &inferrer{substitution: map[string]Type{}, free: map[string]Type{}}
+	typ, err := in.infer(nil, t)
+	if err != nil {
+		return monad.Fail[Type, error](err)
+	}
+	return monad.Succeed[Type, error](rename(in.apply(typ), map[string]string{}))
+}
+
+// inferrer holds the state of an inference: the substitution solving the
+// equations met so far, the types assumed for the free variables, and the
+// counter used to generate fresh type variables.
+type inferrer struct {
+	substitution map[string]Type
+	free         map[string]Type
+	next         int
+}
+
+// scope binds the parameters of the enclosing abstractions to their types,
+// innermost first.
+type scope struct {
+	name string
+	typ  Type
+	next *scope
+}
+
+func (in *inferrer) fresh() Type {
+	in.next++
+	return NewVar("t" + strconv.Itoa(in.next))
+}
+
+func (in *inferrer) infer(s *scope, t term.Term) (Type, error) {
+	switch t := t.(type) {
+	case term.Var:
+		for ; s != nil; s = s.next {
+			if s.name == t.Name() {
+				return s.typ, nil
+			}
+		}
+		if typ, ok := in.free[t.Name()]; ok {
+			return typ, nil
+		}
+		typ := in.fresh()
+		in.free[t.Name()] = typ
+		return typ, nil
+	case term.Abs:
+		param := in.fresh()
+		body, err := in.infer(&scope{name: t.Param(), typ: param, next: s}, t.Body())
+		if err != nil {
+			return nil, err
+		}
+		return NewArrow(param, body), nil
+	case term.App:
+		fun, err := in.infer(s, t.Fun())
+		if err != nil {
+			return nil, err
+		}
+		arg, err := in.infer(s, t.Arg())
+		if err != nil {
+			return nil, err
+		}
+		result := in.fresh()
+		if !in.unify(fun, NewArrow(arg, result)) {
+			names := map[string]string{}
+			return nil, diagnostic.Errorf(
+				t.Position(), "%s has no simple type: %s cannot be applied to %s",
+				t, rename(in.apply(fun), names), rename(in.apply(arg), names),
+			).WithHint("this would require an infinite type, as self-applications such as x x do")
+		}
+		return result, nil
+	}
+	return nil, nil
+}
+
+// unify extends the substitution so that a and b become equal, and reports
+// whether it is possible.
+func (in *inferrer) unify(a, b Type) bool {
+	a, b = in.walk(a), in.walk(b)
+	if v, ok := a.(Var); ok {
+		return in.bind(v, b)
+	}
+	if v, ok := b.(Var); ok {
+		return in.bind(v, a)
+	}
+	fa, fb := a.(Arrow), b.(Arrow)
+	return in.unify(fa.from, fb.from) && in.unify(fa.to, fb.to)
+}
+
+// bind extends the substitution with v = t, unless t contains v, in which
+// case no finite type solves the equation.
+func (in *inferrer) bind(v Var, t Type) bool {
+	if w, ok := t.(Var); ok && w.name == v.name {
+		return true
+	}
+	if in.occurs(v, t) {
+		return false
+	}
+	in.substitution[v.name] = t
+	return true
+}
+
+// walk resolves t while it is a variable bound by the substitution.
+func (in *inferrer) walk(t Type) Type {
+	for {
+		v, ok := t.(Var)
+		if !ok {
+			return t
+		}
+		bound, ok := in.substitution[v.name]
+		if !ok {
+			return t
+		}
+		t = bound
+	}
+}
+
+func (in *inferrer) occurs(v Var, t Type) bool {
+	switch t := in.walk(t).(type) {
+	case Var:
+		return t.name == v.name
+	case Arrow:
+		return in.occurs(v, t.from) || in.occurs(v, t.to)
+	}
+	return false
+}
+
+// apply resolves every variable of t bound by the substitution.
+func (in *inferrer) apply(t Type) Type {
+	switch t := in.walk(t).(type) {
+	case Arrow:
+		return NewArrow(in.apply(t.from), in.apply(t.to))
+	default:
+		return t
+	}
+}
+
+// rename names the variables of t a, b, c... in order of appearance.
+func rename(t Type, names map[string]string) Type {
+	switch t := t.(type) {
+	case Var:
+		if _, ok := names[t.name]; !ok {
+			names[t.name] = letters(len(names))
+		}
+		return NewVar(names[t.name])
+	case Arrow:
+		from := rename(t.from, names)
+		return NewArrow(from, rename(t.to, names))
+	}
+	return t
+}
+
+// letters returns the i-th name in the sequence a, b, ..., z, a1, b1...
+func letters(i int) string {
+	name := string(rune('a' + i%26))
+	if i >= 26 {
+		name += strconv.Itoa(i / 26)
+	}
+	return name
+}
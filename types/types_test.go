@@ -0,0 +1,74 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/denisdubochevalier/lambdac/parser"
+	"github.com/denisdubochevalier/lambdac/term"
+)
+
+func parse(t *testing.T, source string) term.Term {
+	t.Helper()
+	program := parser.ParseString(source)
+	require.True(t, program.Success(), "%v", program.Error())
+	result := term.FromAST(program.Value().Children()[0])
+	require.True(t, result.Success(), "%v", result.Error())
+	return result.Value()
+}
+
+func TestInfer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{`\x.x`, `a -> a`},
+		{`\x.\y.x`, `a -> b -> a`},
+		{`\x.\y.\z.x z (y z)`, `(a -> b -> c) -> (a -> b) -> a -> c`},
+		{`\f.\x.f (f x)`, `(a -> a) -> a -> a`},
+		{`\m.\n.\f.\x.m f (n f x)`, `(a -> b -> c) -> (a -> d -> b) -> a -> d -> c`},
+		{`\f.\g.\x.f (g x)`, `(a -> b) -> (c -> a) -> c -> b`},
+		{`f x`, `a`},
+		{`\x.f x x`, `a -> b`},
+		{`(\x.x) (\y.y)`, `a -> a`},
+		{`\x.\x.x`, `a -> b -> b`},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.input, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			result := Infer(parse(t, testCase.input))
+			is.True(result.Success(), "%v", result.Error())
+			is.Equal(testCase.expected, result.Value().String())
+		})
+	}
+}
+
+func TestInferFailure(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	result := Infer(parse(t, `\x.x x`))
+	is.True(result.Failure())
+	is.Equal(`1:3: error: x x has no simple type: a cannot be applied to a `+
+		`(this would require an infinite type, as self-applications such as x x do)`, result.Error().Error())
+
+	is.True(Infer(parse(t, `\f.(\x.f (x x)) (\x.f (x x))`)).Failure())
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	a, b := NewVar("a"), NewVar("b")
+	is.Equal("(a -> b) -> a", NewArrow(NewArrow(a, b), a).String())
+	is.Equal("a -> b -> a", NewArrow(a, NewArrow(b, a)).String())
+	is.Equal("a", NewArrow(a, b).From().String())
+	is.Equal("b", NewArrow(a, b).To().(Var).Name())
+}